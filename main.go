@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/openshift/library-go/pkg/controller/controllercmd"
@@ -93,6 +94,7 @@ var (
 	metricsAddr string
 	probeAddr   string
 	leaderElect bool
+	gcOptions   meshcontroller.GarbageCollectorOptions
 )
 
 func newControllerCommand() *cobra.Command {
@@ -112,6 +114,10 @@ func newControllerCommand() *cobra.Command {
 	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	cmd.Flags().StringVar(&probeAddr, "health-probe-addr", ":8081", "The address the probe endpoint binds to.")
 	cmd.Flags().BoolVar(&leaderElect, "leader-elect", true, "Enable leader election for controller manager.")
+	cmd.Flags().DurationVar(&gcOptions.Interval, "orphan-gc-interval", time.Hour,
+		"The interval between two sweeps deleting the resources of meshes that no longer exist. Zero disables them.")
+	cmd.Flags().BoolVar(&gcOptions.DryRun, "orphan-gc-dry-run", false,
+		"Only log and count the resources of meshes that no longer exist instead of deleting them.")
 
 	return cmd
 }
@@ -156,6 +162,12 @@ func runController(ctx context.Context, controllerContext *controllercmd.Control
 		return err
 	}
 
+	// Register the garbage collector of resources left behind by deleted meshes
+	if err := meshcontroller.RegisterGarbageCollector(mgr, gcOptions); err != nil {
+		klog.Errorf("Unable to register orphaned resource garbage collector: %v", err)
+		return err
+	}
+
 	// Add health and readiness checks - these run on all pods, not just the leader
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		klog.Errorf("Unable to set up health check: %v", err)
@@ -4,8 +4,10 @@ import (
 	"fmt"
 
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +genclient
@@ -43,8 +45,13 @@ func (m *MultiClusterMesh) GetControlPlaneNamespace() string {
 
 // SetReadyCondition sets the mesh-level Ready condition.
 func (m *MultiClusterMesh) SetReadyCondition(status metav1.ConditionStatus, reason string, messageFmt string, args ...any) {
+	m.SetCondition(ConditionReady, status, reason, messageFmt, args...)
+}
+
+// SetCondition sets a mesh-level condition.
+func (m *MultiClusterMesh) SetCondition(conditionType string, status metav1.ConditionStatus, reason string, messageFmt string, args ...any) {
 	meta.SetStatusCondition(&m.Status.Conditions, metav1.Condition{
-		Type:               ConditionReady,
+		Type:               conditionType,
 		Status:             status,
 		Reason:             reason,
 		ObservedGeneration: m.Generation,
@@ -64,6 +71,51 @@ func (m *MultiClusterMesh) SetClusterCondition(clusterName string, conditionType
 	})
 }
 
+// SetClusterDiscovery sets the endpoint discovery status of a cluster, creating the cluster status entry if needed.
+func (m *MultiClusterMesh) SetClusterDiscovery(clusterName string, discovery *ClusterDiscoveryStatus) {
+	m.getOrCreateClusterStatus(clusterName).Discovery = discovery
+}
+
+// SetClusterOperatorProfile sets the operator profile chosen for a cluster, creating the cluster status entry if needed.
+func (m *MultiClusterMesh) SetClusterOperatorProfile(clusterName, profile string) {
+	m.getOrCreateClusterStatus(clusterName).OperatorProfile = profile
+}
+
+// SetClusterOperatorVersion sets the installed operator version of a cluster, creating the cluster status entry if needed.
+func (m *MultiClusterMesh) SetClusterOperatorVersion(clusterName, version string) {
+	m.getOrCreateClusterStatus(clusterName).OperatorVersion = version
+}
+
+// SetClusterOperatorRollout sets the operator rollout status of a cluster, creating the cluster status entry if needed.
+func (m *MultiClusterMesh) SetClusterOperatorRollout(clusterName string, rollout *ClusterOperatorRolloutStatus) {
+	m.getOrCreateClusterStatus(clusterName).OperatorRollout = rollout
+}
+
+// GetClusterOperatorRollout returns the operator rollout status of a cluster, or nil if it has none.
+func (m *MultiClusterMesh) GetClusterOperatorRollout(clusterName string) *ClusterOperatorRolloutStatus {
+	for i := range m.Status.ClusterStatus {
+		if m.Status.ClusterStatus[i].ClusterName == clusterName {
+			return m.Status.ClusterStatus[i].OperatorRollout
+		}
+	}
+	return nil
+}
+
+// SetClusterQuarantine sets the quarantine status of a cluster, creating the cluster status entry if needed.
+func (m *MultiClusterMesh) SetClusterQuarantine(clusterName string, quarantine *ClusterQuarantineStatus) {
+	m.getOrCreateClusterStatus(clusterName).Quarantine = quarantine
+}
+
+// GetClusterQuarantine returns the quarantine status of a cluster, or nil if it is not quarantined.
+func (m *MultiClusterMesh) GetClusterQuarantine(clusterName string) *ClusterQuarantineStatus {
+	for i := range m.Status.ClusterStatus {
+		if m.Status.ClusterStatus[i].ClusterName == clusterName {
+			return m.Status.ClusterStatus[i].Quarantine
+		}
+	}
+	return nil
+}
+
 func (m *MultiClusterMesh) getOrCreateClusterStatus(clusterName string) *ClusterMeshStatus {
 	// Index-based iteration to return a pointer into the slice, not a copy.
 	for i := range m.Status.ClusterStatus {
@@ -91,9 +143,39 @@ type MultiClusterMeshSpec struct {
 	// +optional
 	Operator OperatorConfig `json:"operator,omitempty"`
 
+	// OperatorProfiles define operator configurations for clusters of specific platforms, detected from
+	// their ClusterClaims, e.g. OSSM on OpenShift and Sail elsewhere. Each cluster uses the operator
+	// configuration of the first matching profile, or Operator if no profile matches.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=8
+	OperatorProfiles []OperatorProfile `json:"operatorProfiles,omitempty"`
+
+	// Rollout defines how changes to the operator configuration are rolled out across clusters.
+	// If unset, all clusters are updated at once.
+	// +optional
+	Rollout *RolloutStrategy `json:"rollout,omitempty"`
+
+	// VersionSkew defines how far apart the operator versions installed on the clusters may be
+	// before the mesh reports them as inconsistent. If unset, all clusters must run the same version.
+	// +optional
+	VersionSkew *VersionSkewPolicy `json:"versionSkew,omitempty"`
+
 	// Security defines the trust and discovery configuration
 	// +optional
 	Security SecurityConfig `json:"security,omitempty"`
+
+	// DeletionPolicy defines whether the resources created on the clusters are deleted or left in place
+	// when the mesh is deleted or a cluster leaves it.
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Suspend holds all changes to the resources of the mesh on the clusters: nothing is applied or deleted,
+	// while the status is still reported. The quarantine of a cluster is the exception: its credentials and remote
+	// secrets are still revoked, since that only removes trust. Deleting the mesh still cleans up its resources.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
 }
 
 // ControlPlaneConfig defines where the mesh control plane will be installed
@@ -109,7 +191,22 @@ type ControlPlaneConfig struct {
 
 // OperatorConfig defines the service mesh operator installation settings.
 // Defaults target OSSM on OpenShift. Override fields to use a different operator variant (e.g. Sail).
+// +kubebuilder:validation:XValidation:rule="!has(self.approvedCSV) || self.installPlanApproval == 'Manual'",message="approvedCSV requires installPlanApproval Manual"
+// +kubebuilder:validation:XValidation:rule="self.installMode != 'Manifests' || (has(self.manifests) && has(self.manifests.configMapName))",message="manifests.configMapName is required when installMode is Manifests"
+// +kubebuilder:validation:XValidation:rule="self.installMode != 'Manifests' || !has(self.config)",message="config requires installMode OLM"
+// +kubebuilder:validation:XValidation:rule="self.installMode != 'Manifests' || !has(self.catalog)",message="catalog requires installMode OLM"
 type OperatorConfig struct {
+	// InstallMode defines how the operator is installed on the clusters.
+	// OLM installs it with an OLM Subscription, Manifests applies the operator manifests
+	// from a ConfigMap on clusters without OLM.
+	// +optional
+	// +kubebuilder:default="OLM"
+	InstallMode OperatorInstallMode `json:"installMode,omitempty"`
+
+	// Manifests defines the operator manifests applied by the Manifests install mode
+	// +optional
+	Manifests OperatorManifests `json:"manifests,omitempty"`
+
 	// Name is the OLM package name of the operator
 	// +optional
 	// +kubebuilder:default="servicemeshoperator3"
@@ -150,6 +247,244 @@ type OperatorConfig struct {
 	// +kubebuilder:default="Automatic"
 	// +kubebuilder:validation:Enum=Automatic;Manual
 	InstallPlanApproval operatorsv1alpha1.Approval `json:"installPlanApproval,omitempty"`
+
+	// ApprovedCSV is the operator version the hub approves InstallPlans for when InstallPlanApproval is Manual.
+	// InstallPlans for any other version are left pending. Defaults to StartingCSV.
+	// +optional
+	ApprovedCSV string `json:"approvedCSV,omitempty"`
+
+	// Catalog makes the hub create the CatalogSource named by Source in SourceNamespace on the clusters,
+	// e.g. a mirrored catalog in disconnected environments. If unset, the CatalogSource must exist on the clusters.
+	// +optional
+	Catalog *OperatorCatalog `json:"catalog,omitempty"`
+
+	// Config customizes the operator Deployment created by OLM, e.g. to pin it to infra nodes or set proxy variables.
+	// It is passed to the config of the OLM Subscription.
+	// +optional
+	Config *SubscriptionConfig `json:"config,omitempty"`
+}
+
+// OperatorCatalog defines a CatalogSource serving the operator from a catalog image
+type OperatorCatalog struct {
+	// Image is the catalog image, e.g. a mirror of registry.redhat.io/redhat/redhat-operator-index
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// DisplayName is the name of the catalog shown to users
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
+	// PullSecrets are the names of Secrets in the source namespace used to pull the catalog image
+	// +optional
+	// +listType=set
+	PullSecrets []string `json:"pullSecrets,omitempty"`
+
+	// PollInterval is how often OLM polls the catalog image for updates. If unset, the catalog is not updated.
+	// +optional
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+}
+
+// SubscriptionConfig defines the operator Deployment settings OLM applies from the Subscription config
+type SubscriptionConfig struct {
+	// NodeSelector selects the nodes the operator pod runs on
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the operator pod
+	// +optional
+	// +listType=atomic
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Resources of the operator container
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Env is a list of environment variables set in the operator container, e.g. HTTP_PROXY
+	// +optional
+	// +listType=atomic
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Volumes added to the operator pod. The volume schema is validated by the Kubernetes API server
+	// of the managed clusters, keeping it out of this CRD.
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// VolumeMounts added to the operator container
+	// +optional
+	// +listType=atomic
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+}
+
+// OperatorInstallMode defines how the operator is installed on the clusters
+// +kubebuilder:validation:Enum=OLM;Manifests
+type OperatorInstallMode string
+
+const (
+	// OperatorInstallModeOLM installs the operator with an OLM Subscription
+	OperatorInstallModeOLM OperatorInstallMode = "OLM"
+
+	// OperatorInstallModeManifests applies the operator manifests directly, for clusters without OLM
+	OperatorInstallModeManifests OperatorInstallMode = "Manifests"
+)
+
+// OperatorManifests references the manifests of the operator, e.g. rendered with helm template from the Sail operator chart
+type OperatorManifests struct {
+	// ConfigMapName is the name of a ConfigMap in the mesh namespace. Each key holds one or more YAML documents,
+	// applied to the clusters in the order of the keys.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// DeploymentName is the name of the operator Deployment in the operator namespace, whose readiness is reported
+	// +optional
+	// +kubebuilder:default="sail-operator"
+	DeploymentName string `json:"deploymentName,omitempty"`
+}
+
+// GetApprovedCSV returns the operator CSV the hub approves InstallPlans for, defaulting to the starting CSV.
+// It returns an empty string unless the InstallPlan approval is Manual.
+func (c *OperatorConfig) GetApprovedCSV() string {
+	if c.InstallPlanApproval != operatorsv1alpha1.ApprovalManual {
+		return ""
+	}
+	if c.ApprovedCSV == "" {
+		return c.StartingCSV
+	}
+	return c.ApprovedCSV
+}
+
+// OperatorProfile defines the operator configuration of the clusters matching a platform selector
+type OperatorProfile struct {
+	// Name identifies the profile in the cluster status
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Selector selects the clusters using this profile. An empty selector matches every cluster.
+	// +optional
+	Selector PlatformSelector `json:"selector,omitempty"`
+
+	// Operator defines the service mesh operator installation configuration of the selected clusters
+	// +optional
+	Operator OperatorConfig `json:"operator,omitempty"`
+}
+
+// PlatformSelector selects clusters by the platform ClusterClaims reported by their klusterlet.
+// A cluster matches if it matches every criterion that is set.
+type PlatformSelector struct {
+	// Products match the product.open-cluster-management.io ClusterClaim, e.g. OpenShift, EKS or Other
+	// +optional
+	// +listType=set
+	Products []string `json:"products,omitempty"`
+
+	// Platforms match the platform.open-cluster-management.io ClusterClaim, e.g. AWS, GCP or Other
+	// +optional
+	// +listType=set
+	Platforms []string `json:"platforms,omitempty"`
+
+	// MinKubeVersion is the minimum Kubernetes version of the kubeversion.open-cluster-management.io ClusterClaim
+	// +optional
+	// +kubebuilder:validation:Pattern=`^v?(0|[1-9][0-9]*)\.[0-9]+(\.[0-9]+)?$`
+	MinKubeVersion string `json:"minKubeVersion,omitempty"`
+}
+
+// RolloutStrategy defines a progressive rollout of operator configuration changes.
+// Canary clusters are updated first, and the remaining clusters are held back until every canary
+// runs the new configuration and stays healthy for the soak time.
+type RolloutStrategy struct {
+	// CanaryClusters are the clusters updated before any other cluster
+	// +optional
+	// +listType=set
+	CanaryClusters []string `json:"canaryClusters,omitempty"`
+
+	// MaxConcurrency is the maximum number of clusters updating at the same time after the canaries,
+	// as an absolute number or a percentage of the clusters. If unset, all remaining clusters are updated at once.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:XValidation:rule="type(self) == int ? self >= 1 : self.matches('^(100|[1-9][0-9]?)%$')",message="maxConcurrency must be a positive number or a percentage between 1% and 100%"
+	MaxConcurrency *intstr.IntOrString `json:"maxConcurrency,omitempty"`
+
+	// MaxFailures is the number of failed clusters tolerated before the rollout is halted,
+	// as an absolute number or a percentage of the clusters. A failed canary always halts the rollout.
+	// If unset, the rollout is halted on the first failure.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:XValidation:rule="type(self) == int ? self >= 0 : self.matches('^(100|[1-9]?[0-9])%$')",message="maxFailures must be a non-negative number or a percentage between 0% and 100%"
+	MaxFailures *intstr.IntOrString `json:"maxFailures,omitempty"`
+
+	// SoakTime is the minimum time an updated cluster must stay healthy before the rollout proceeds past it
+	// +optional
+	SoakTime metav1.Duration `json:"soakTime,omitempty"`
+}
+
+// VersionSkewPolicy defines the operator version skew tolerated between the clusters of a mesh.
+// Clusters on different major versions are always reported as inconsistent.
+type VersionSkewPolicy struct {
+	// MaxMinorVersions is the maximum difference in minor versions between the clusters
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxMinorVersions int32 `json:"maxMinorVersions,omitempty"`
+
+	// IgnorePatchVersions tolerates clusters running different patch versions of the same minor version
+	// +optional
+	IgnorePatchVersions bool `json:"ignorePatchVersions,omitempty"`
+}
+
+// DeletionPolicyType defines what happens to resources on the clusters when the add-on stops managing them
+// +kubebuilder:validation:Enum=Delete;Orphan
+type DeletionPolicyType string
+
+const (
+	// DeletionPolicyDelete deletes the resources from the clusters
+	DeletionPolicyDelete DeletionPolicyType = "Delete"
+
+	// DeletionPolicyOrphan leaves the resources on the clusters, no longer managed by the add-on
+	DeletionPolicyOrphan DeletionPolicyType = "Orphan"
+)
+
+// DeletionPolicy defines the deletion policy of each type of resource the add-on creates on the clusters.
+// A resource type without its own policy uses the default policy.
+type DeletionPolicy struct {
+	// Default is the deletion policy of the resource types without their own policy
+	// +optional
+	// +kubebuilder:default=Delete
+	Default DeletionPolicyType `json:"default,omitempty"`
+
+	// Operator is the deletion policy of the operator installation. It applies to the operator shared by
+	// all meshes of the ClusterSet, so meshes targeting the same ClusterSet must agree on it.
+	// +optional
+	Operator DeletionPolicyType `json:"operator,omitempty"`
+
+	// ControlPlaneNamespace is the deletion policy of the control plane namespace, along with everything
+	// deployed in it, including the cacerts and remote secrets
+	// +optional
+	ControlPlaneNamespace DeletionPolicyType `json:"controlPlaneNamespace,omitempty"`
+
+	// Cacerts is the deletion policy of the cacerts secret
+	// +optional
+	Cacerts DeletionPolicyType `json:"cacerts,omitempty"`
+
+	// RemoteSecrets is the deletion policy of the remote secrets of the peer clusters. The remote secrets are removed
+	// from and of a quarantined cluster regardless of this policy.
+	// +optional
+	RemoteSecrets DeletionPolicyType `json:"remoteSecrets,omitempty"`
+
+	// CleanupTimeout is how long the deletion of the mesh waits for the clusters to clean up its resources.
+	// The clusters that have not cleaned up by then, usually because they are unreachable, are skipped and
+	// clean up once their work agent is back. If unset, defaults to 10m
+	// +optional
+	// +kubebuilder:default="10m"
+	CleanupTimeout *metav1.Duration `json:"cleanupTimeout,omitempty"`
+
+	// RemovalGracePeriod is how long the resources of a cluster that left the ClusterSet are kept before they are removed,
+	// so that a cluster losing its ClusterSet label by mistake only for a moment is not torn down.
+	// If unset, the resources are removed as soon as the cluster leaves
+	// +optional
+	RemovalGracePeriod *metav1.Duration `json:"removalGracePeriod,omitempty"`
 }
 
 // SecurityConfig defines trust and discovery configuration
@@ -191,6 +526,7 @@ type IssuerReference struct {
 }
 
 // DiscoveryConfig defines endpoint discovery token configuration
+// +kubebuilder:validation:XValidation:rule="!has(self.transport) || self.transport != 'ClusterProxy' || has(self.clusterProxy)",message="clusterProxy is required when transport is ClusterProxy"
 type DiscoveryConfig struct {
 	// TokenValidity defines how long discovery tokens are valid
 	// Supports hours (h), minutes (m), seconds (s). If unset, defaults to 360h
@@ -198,8 +534,145 @@ type DiscoveryConfig struct {
 	// +kubebuilder:default="360h"
 	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('10m')", message="TokenValidity must be at least 10 minutes"
 	TokenValidity *metav1.Duration `json:"tokenValidity,omitempty"`
+
+	// APIServer selects the API server endpoint and CA written into the remote secrets of each cluster.
+	// Individual clusters can override it with the mesh.open-cluster-management.io/api-server-* ManagedCluster annotations.
+	// +optional
+	APIServer APIServerConfig `json:"apiServer,omitempty"`
+
+	// Transport defines how peers reach a cluster's API server.
+	// Direct uses the cluster's API server endpoint, ClusterProxy routes through the OCM cluster-proxy addon,
+	// which lets peers discover clusters whose API servers are not reachable from other clusters.
+	// Individual clusters can override it with the mesh.open-cluster-management.io/discovery-transport ManagedCluster annotation.
+	// +optional
+	// +kubebuilder:default="Direct"
+	Transport DiscoveryTransport `json:"transport,omitempty"`
+
+	// ClusterProxy defines the cluster-proxy endpoint used by the ClusterProxy transport
+	// +optional
+	ClusterProxy *ClusterProxyConfig `json:"clusterProxy,omitempty"`
+
+	// ReadinessGate defines what a cluster waits for before it becomes a discoverable peer and receives the remote secrets
+	// of its peers. None distributes the remote secrets as soon as the token exists, Operator waits for the operator to be
+	// installed and the cacerts secret to be applied, and Istiod also waits for the istiod Deployment to be ready.
+	// A cluster stays a peer once it passed the gate. Defaults to None, so that the clusters of existing meshes are not
+	// held back on upgrade.
+	// +optional
+	// +kubebuilder:default="None"
+	ReadinessGate ReadinessGate `json:"readinessGate,omitempty"`
+}
+
+// ReadinessGate defines what a cluster waits for before it becomes a discoverable peer
+// +kubebuilder:validation:Enum=None;Operator;Istiod
+type ReadinessGate string
+
+const (
+	// ReadinessGateNone makes a cluster a peer as soon as its discovery token exists
+	ReadinessGateNone ReadinessGate = "None"
+
+	// ReadinessGateOperator makes a cluster a peer once its operator is installed and its cacerts secret is applied
+	ReadinessGateOperator ReadinessGate = "Operator"
+
+	// ReadinessGateIstiod makes a cluster a peer once its operator is installed, its cacerts secret is applied and its
+	// istiod Deployment is ready
+	ReadinessGateIstiod ReadinessGate = "Istiod"
+)
+
+// DiscoveryTransport defines how peers reach a cluster's API server
+// +kubebuilder:validation:Enum=Direct;ClusterProxy
+type DiscoveryTransport string
+
+const (
+	// DiscoveryTransportDirect connects peers directly to the cluster's API server
+	DiscoveryTransportDirect DiscoveryTransport = "Direct"
+
+	// DiscoveryTransportClusterProxy connects peers to the cluster's API server through the OCM cluster-proxy
+	DiscoveryTransportClusterProxy DiscoveryTransport = "ClusterProxy"
+)
+
+// ClusterProxyConfig defines the cluster-proxy endpoint used to reach cluster API servers
+type ClusterProxyConfig struct {
+	// URL is the base URL of the cluster-proxy user server. The cluster name is appended as path segment.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self.startsWith('https://')",message="url must use https"
+	URL string `json:"url"`
+
+	// CABundle is the PEM encoded CA certificate of the cluster-proxy user server
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	CABundle []byte `json:"caBundle"`
+
+	// TLSServerName is the server name used to verify the cluster-proxy certificate, if it differs from the URL host
+	// +optional
+	TLSServerName string `json:"tlsServerName,omitempty"`
+
+	// Impersonate is the user the cluster-proxy impersonates on the cluster.
+	// If unset, the ManagedServiceAccount token is passed through to the cluster.
+	// +optional
+	Impersonate string `json:"impersonate,omitempty"`
+}
+
+// APIServerCASource is the source of the CA certificate used to verify a cluster's API server
+// +kubebuilder:validation:Enum=ServiceAccount;ClientConfig
+type APIServerCASource string
+
+const (
+	// APIServerCASourceServiceAccount uses the service account root CA from the ManagedServiceAccount token secret
+	APIServerCASourceServiceAccount APIServerCASource = "ServiceAccount"
+
+	// APIServerCASourceClientConfig uses the CABundle of the selected ManagedCluster client config
+	APIServerCASourceClientConfig APIServerCASource = "ClientConfig"
+)
+
+// APIServerConfig defines how a cluster's API server endpoint is selected for remote secrets.
+// If neither ClientConfigIndex nor URLPattern is set, the first ManagedCluster client config is used.
+// +kubebuilder:validation:XValidation:rule="!(has(self.clientConfigIndex) && has(self.urlPattern))",message="clientConfigIndex and urlPattern are mutually exclusive"
+type APIServerConfig struct {
+	// ClientConfigIndex selects the ManagedCluster client config at the given index
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ClientConfigIndex *int32 `json:"clientConfigIndex,omitempty"`
+
+	// URLPattern selects the first ManagedCluster client config whose URL matches the regular expression
+	// +optional
+	URLPattern string `json:"urlPattern,omitempty"`
+
+	// CASource is the source of the CA certificate used to verify the API server
+	// +optional
+	// +kubebuilder:default="ServiceAccount"
+	CASource APIServerCASource `json:"caSource,omitempty"`
+
+	// TLSServerName is the server name used to verify the API server certificate, if it differs from the URL host
+	// +optional
+	TLSServerName string `json:"tlsServerName,omitempty"`
+
+	// ProxyURL is the URL of the proxy used to reach the API server
+	// +optional
+	ProxyURL string `json:"proxyURL,omitempty"`
 }
 
+// ManagedCluster annotations overriding the mesh's API server selection policy and discovery transport for a single cluster.
+const (
+	// AnnotationAPIServerURL overrides the API server URL of the cluster
+	AnnotationAPIServerURL = "mesh.open-cluster-management.io/api-server-url"
+
+	// AnnotationAPIServerURLPattern overrides the URL pattern used to select the cluster's client config
+	AnnotationAPIServerURLPattern = "mesh.open-cluster-management.io/api-server-url-pattern"
+
+	// AnnotationAPIServerCASource overrides the CA source of the cluster (ServiceAccount or ClientConfig)
+	AnnotationAPIServerCASource = "mesh.open-cluster-management.io/api-server-ca-source"
+
+	// AnnotationAPIServerTLSServerName overrides the TLS server name of the cluster
+	AnnotationAPIServerTLSServerName = "mesh.open-cluster-management.io/api-server-tls-server-name"
+
+	// AnnotationAPIServerProxyURL overrides the proxy URL of the cluster
+	AnnotationAPIServerProxyURL = "mesh.open-cluster-management.io/api-server-proxy-url"
+
+	// AnnotationDiscoveryTransport overrides the discovery transport of the cluster (Direct or ClusterProxy)
+	AnnotationDiscoveryTransport = "mesh.open-cluster-management.io/discovery-transport"
+)
+
 const (
 	// ConditionReady indicates whether the mesh is fully operational
 	ConditionReady = "Ready"
@@ -207,18 +680,142 @@ const (
 	// ConditionOperatorInstalled indicates whether the operator is installed on a cluster
 	ConditionOperatorInstalled = "OperatorInstalled"
 
+	// ConditionOperatorApplied indicates whether the work agent applied the operator ManifestWork on a cluster
+	ConditionOperatorApplied = "OperatorApplied"
+
+	// ConditionControlPlaneNamespaceApplied indicates whether the work agent applied the control plane namespace ManifestWork on a cluster
+	ConditionControlPlaneNamespaceApplied = "ControlPlaneNamespaceApplied"
+
+	// ConditionCacertsApplied indicates whether the work agent applied the cacerts ManifestWork on a cluster
+	ConditionCacertsApplied = "CacertsApplied"
+
+	// ConditionDiscoveryReady indicates whether a cluster's remote secret is distributed to its peers
+	ConditionDiscoveryReady = "DiscoveryReady"
+
+	// ConditionPeerReady indicates whether a cluster passed the readiness gate to become a discoverable peer
+	ConditionPeerReady = "PeerReady"
+
+	// ConditionRemoteSecretsApplied indicates whether the remote secrets of a cluster's peers are applied on the cluster
+	ConditionRemoteSecretsApplied = "RemoteSecretsApplied"
+
+	// ConditionOperatorRolledOut indicates whether the operator configuration is rolled out to all clusters
+	ConditionOperatorRolledOut = "OperatorRolledOut"
+
+	// ConditionCatalogSourceReady indicates whether the CatalogSource created by the hub serves the operator catalog
+	ConditionCatalogSourceReady = "CatalogSourceReady"
+
+	// ConditionVersionConsistent indicates whether the clusters run operator versions within the allowed skew
+	ConditionVersionConsistent = "VersionConsistent"
+
+	// ConditionDeletionBlocked indicates whether the deletion of the mesh waits for the user to confirm the removal of
+	// Istio control planes still running on its clusters
+	ConditionDeletionBlocked = "DeletionBlocked"
+
+	// ConditionTerminating indicates whether the deletion of the mesh waits for its clusters to clean up its resources
+	ConditionTerminating = "Terminating"
+
+	// ConditionSuspended indicates whether changes to the resources of the mesh on the clusters are held
+	ConditionSuspended = "Suspended"
+
+	// ConditionPendingRemoval indicates a cluster left the ClusterSet and its resources are removed once the removal grace period expires
+	ConditionPendingRemoval = "PendingRemoval"
+
+	// ConditionRemoving indicates the resources of a cluster that left the mesh are being removed, one step after the other
+	ConditionRemoving = "Removing"
+
+	// ConditionQuarantined indicates a cluster is quarantined and cut out of the trust of the mesh
+	ConditionQuarantined = "Quarantined"
+
 	// ReasonAllClustersReady indicates all clusters have confirmed operator installation
 	ReasonAllClustersReady = "AllClustersReady"
 
 	// ReasonClustersNotReady indicates that not all clusters have confirmed operator installation
 	ReasonClustersNotReady = "ClustersNotReady"
 
+	// ReasonClustersDegraded indicates that the work agent failed to apply or reports degraded ManifestWorks on some clusters
+	ReasonClustersDegraded = "ClustersDegraded"
+
 	// ReasonInstallationPending indicates the operator installation has been requested
 	ReasonInstallationPending = "InstallationPending"
 
 	// ReasonOperatorInstalled indicates the operator CSV has been successfully installed
 	ReasonOperatorInstalled = "Installed"
 
+	// ReasonCatalogUnhealthy indicates OLM reports an unhealthy CatalogSource for the operator Subscription
+	ReasonCatalogUnhealthy = "CatalogUnhealthy"
+
+	// ReasonCatalogReady indicates the CatalogSource created by the hub is connected to its catalog image
+	ReasonCatalogReady = "CatalogReady"
+
+	// ReasonCatalogConnecting indicates the CatalogSource created by the hub is not connected to its catalog image yet
+	ReasonCatalogConnecting = "CatalogConnecting"
+
+	// ReasonResolutionFailed indicates OLM failed to resolve the operator Subscription's dependencies
+	ReasonResolutionFailed = "ResolutionFailed"
+
+	// ReasonAwaitingApproval indicates the operator InstallPlan requires manual approval
+	ReasonAwaitingApproval = "AwaitingApproval"
+
+	// ReasonInstallPlanFailed indicates the operator InstallPlan failed
+	ReasonInstallPlanFailed = "InstallPlanFailed"
+
+	// ReasonUpgradeFailed indicates OLM failed to upgrade the installed operator
+	ReasonUpgradeFailed = "UpgradeFailed"
+
+	// ReasonNoAPIEndpoint indicates no usable API server endpoint was found for a cluster
+	ReasonNoAPIEndpoint = "NoAPIEndpoint"
+
+	// ReasonTokenPending indicates the ManagedServiceAccount token of a cluster has not been issued yet
+	ReasonTokenPending = "TokenPending"
+
+	// ReasonDistributionPending indicates the remote secret of a cluster has not been distributed yet
+	ReasonDistributionPending = "DistributionPending"
+
+	// ReasonDistributed indicates the remote secret of a cluster is distributed to its peers
+	ReasonDistributed = "Distributed"
+
+	// ReasonOperatorNotReady indicates a cluster waits for its operator to be installed before becoming a peer
+	ReasonOperatorNotReady = "OperatorNotReady"
+
+	// ReasonCacertsNotApplied indicates a cluster waits for its cacerts secret to be applied before becoming a peer
+	ReasonCacertsNotApplied = "CacertsNotApplied"
+
+	// ReasonIstiodNotReady indicates a cluster waits for its istiod Deployment to be ready before becoming a peer
+	ReasonIstiodNotReady = "IstiodNotReady"
+
+	// ReasonReadinessGatePassed indicates a cluster passed the readiness gate and is a discoverable peer
+	ReasonReadinessGatePassed = "ReadinessGatePassed"
+
+	// ReasonApplied indicates the work agent applied the ManifestWork and its resources are available
+	ReasonApplied = "Applied"
+
+	// ReasonApplyPending indicates the work agent has not applied the ManifestWork yet or its resources are not available yet
+	ReasonApplyPending = "ApplyPending"
+
+	// ReasonApplyFailed indicates the work agent failed to apply the ManifestWork
+	ReasonApplyFailed = "ApplyFailed"
+
+	// ReasonDegraded indicates the work agent reports the ManifestWork as degraded
+	ReasonDegraded = "Degraded"
+
+	// ReasonRolloutProgressing indicates the operator configuration is being rolled out
+	ReasonRolloutProgressing = "RolloutProgressing"
+
+	// ReasonRolloutHalted indicates the operator rollout is halted after too many failed clusters
+	ReasonRolloutHalted = "RolloutHalted"
+
+	// ReasonRolloutComplete indicates the operator configuration is rolled out to all clusters
+	ReasonRolloutComplete = "RolloutComplete"
+
+	// ReasonVersionsConsistent indicates the clusters run operator versions within the allowed skew
+	ReasonVersionsConsistent = "VersionsConsistent"
+
+	// ReasonVersionSkew indicates the clusters run operator versions beyond the allowed skew
+	ReasonVersionSkew = "VersionSkew"
+
+	// ReasonVersionUnknown indicates the operator version of some clusters could not be determined
+	ReasonVersionUnknown = "VersionUnknown"
+
 	// ReasonReconcileError indicates an error occurred during reconciliation
 	ReasonReconcileError = "ReconcileError"
 
@@ -227,6 +824,70 @@ const (
 
 	// ReasonNamespaceConflict indicates a conflict with an older mesh's control plane namespace
 	ReasonNamespaceConflict = "NamespaceConflict"
+
+	// ReasonInvalidOperatorProfile indicates an operator profile whose platform selector cannot be evaluated
+	ReasonInvalidOperatorProfile = "InvalidOperatorProfile"
+
+	// ReasonConfigurationConflict indicates a pre-existing operator Subscription on a cluster conflicts with the operator config
+	ReasonConfigurationConflict = "ConfigurationConflict"
+
+	// ReasonOperatorAdopted indicates a compatible pre-existing operator Subscription on a cluster is used instead of installing the operator
+	ReasonOperatorAdopted = "OperatorAdopted"
+
+	// ReasonDetectionPending indicates the cluster has not reported yet whether an operator Subscription already exists
+	ReasonDetectionPending = "DetectionPending"
+
+	// ReasonControlPlanesRunning indicates clusters still run an Istio control plane in the control plane namespace
+	ReasonControlPlanesRunning = "ControlPlanesRunning"
+
+	// ReasonSafetyCheckPending indicates clusters have not reported yet whether they run an Istio control plane
+	ReasonSafetyCheckPending = "SafetyCheckPending"
+
+	// ReasonNoControlPlanes indicates a cluster runs no Istio control plane in the control plane namespace
+	ReasonNoControlPlanes = "NoControlPlanes"
+
+	// ReasonCleanupInProgress indicates clusters are still deleting the resources of the mesh
+	ReasonCleanupInProgress = "CleanupInProgress"
+
+	// ReasonCleanupComplete indicates a cluster deleted the resources of the mesh
+	ReasonCleanupComplete = "CleanupComplete"
+
+	// ReasonCleanupTimedOut indicates clusters did not delete the resources of the mesh within the cleanup timeout and were skipped
+	ReasonCleanupTimedOut = "CleanupTimedOut"
+
+	// ReasonMeshSuspended indicates changes are held on all clusters because the mesh is suspended
+	ReasonMeshSuspended = "MeshSuspended"
+
+	// ReasonClusterMaintenance indicates changes are held on clusters in maintenance mode
+	ReasonClusterMaintenance = "ClusterMaintenance"
+
+	// ReasonNotSuspended indicates changes are applied to all clusters
+	ReasonNotSuspended = "NotSuspended"
+
+	// ReasonRemovalGracePeriod indicates the resources of a cluster that left the ClusterSet are kept during the removal grace period
+	ReasonRemovalGracePeriod = "RemovalGracePeriod"
+
+	// ReasonRevokingRemoteSecret indicates the remote secret of a leaving cluster is being removed from its peers
+	ReasonRevokingRemoteSecret = "RevokingRemoteSecret"
+
+	// ReasonRemovingPeerSecrets indicates the remote secrets of the peers are being removed from a leaving cluster
+	ReasonRemovingPeerSecrets = "RemovingPeerSecrets"
+
+	// ReasonRemovingCacerts indicates the cacerts secret is being removed from a leaving cluster
+	ReasonRemovingCacerts = "RemovingCacerts"
+
+	// ReasonRemovingControlPlaneNamespace indicates the control plane namespace is being removed from a leaving cluster
+	ReasonRemovingControlPlaneNamespace = "RemovingControlPlaneNamespace"
+
+	// ReasonRemovingOperator indicates the operator is being removed from a leaving cluster, once no mesh needs it anymore
+	ReasonRemovingOperator = "RemovingOperator"
+
+	// ReasonQuarantineInProgress indicates the credentials of a quarantined cluster are being revoked
+	ReasonQuarantineInProgress = "QuarantineInProgress"
+
+	// ReasonQuarantineComplete indicates all credentials of a quarantined cluster are revoked and its intermediate CA
+	// is no longer trusted
+	ReasonQuarantineComplete = "QuarantineComplete"
 )
 
 // MultiClusterMeshStatus defines the observed state of MultiClusterMesh
@@ -242,6 +903,35 @@ type MultiClusterMeshStatus struct {
 	// +listMapKey=clusterName
 	// +optional
 	ClusterStatus []ClusterMeshStatus `json:"clusterStatus,omitempty"`
+
+	// OperatorRollout reports the progress of the operator rollout, only when a rollout strategy is set
+	// +optional
+	OperatorRollout *OperatorRolloutStatus `json:"operatorRollout,omitempty"`
+
+	// RevokedCertificates lists the intermediate CAs revoked by the quarantine of clusters that have not expired yet
+	// +listType=map
+	// +listMapKey=serialNumber
+	// +optional
+	RevokedCertificates []RevokedCertificate `json:"revokedCertificates,omitempty"`
+}
+
+// OperatorRolloutStatus reports the progress of an operator configuration rollout
+type OperatorRolloutStatus struct {
+	// Revision identifies the operator configuration being rolled out
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Total is the number of clusters in the mesh
+	Total int32 `json:"total"`
+
+	// Updated is the number of clusters running the rolled out revision
+	Updated int32 `json:"updated"`
+
+	// Succeeded is the number of updated clusters that stayed healthy for the soak time
+	Succeeded int32 `json:"succeeded"`
+
+	// Failed is the number of updated clusters that failed
+	Failed int32 `json:"failed"`
 }
 
 // ClusterMeshStatus tracks the mesh status for a specific cluster
@@ -255,6 +945,121 @@ type ClusterMeshStatus struct {
 	// +listMapKey=type
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Discovery reports the endpoint discovery token of this cluster
+	// +optional
+	Discovery *ClusterDiscoveryStatus `json:"discovery,omitempty"`
+
+	// OperatorProfile is the name of the operator profile chosen for this cluster, empty if it uses spec.operator
+	// +optional
+	OperatorProfile string `json:"operatorProfile,omitempty"`
+
+	// OperatorVersion is the version of the operator installed on this cluster, parsed from its installed CSV
+	// or the image tag of the operator Deployment
+	// +optional
+	OperatorVersion string `json:"operatorVersion,omitempty"`
+
+	// OperatorRollout reports the operator rollout state of this cluster, only when a rollout strategy is set
+	// +optional
+	OperatorRollout *ClusterOperatorRolloutStatus `json:"operatorRollout,omitempty"`
+
+	// Quarantine records the revocations made while the cluster is quarantined
+	// +optional
+	Quarantine *ClusterQuarantineStatus `json:"quarantine,omitempty"`
+}
+
+// OperatorRolloutState is the rollout state of a single cluster
+// +kubebuilder:validation:Enum=Pending;Progressing;Succeeded;Failed
+type OperatorRolloutState string
+
+const (
+	// OperatorRolloutPending means the cluster is held back at its previous revision
+	OperatorRolloutPending OperatorRolloutState = "Pending"
+
+	// OperatorRolloutProgressing means the cluster is updated but not healthy for the soak time yet
+	OperatorRolloutProgressing OperatorRolloutState = "Progressing"
+
+	// OperatorRolloutSucceeded means the cluster is updated and stayed healthy for the soak time
+	OperatorRolloutSucceeded OperatorRolloutState = "Succeeded"
+
+	// OperatorRolloutFailed means the cluster is updated but failed to apply or install the operator
+	OperatorRolloutFailed OperatorRolloutState = "Failed"
+)
+
+// ClusterOperatorRolloutStatus reports the operator rollout state of a cluster
+type ClusterOperatorRolloutStatus struct {
+	// Revision identifies the operator configuration applied to the cluster
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// State is the rollout state of the cluster
+	// +optional
+	State OperatorRolloutState `json:"state,omitempty"`
+
+	// HealthyTime is the time the cluster was first observed healthy at this revision
+	// +optional
+	HealthyTime *metav1.Time `json:"healthyTime,omitempty"`
+}
+
+// ClusterQuarantineStatus records when each credential of a quarantined cluster was revoked. A time is only set once the
+// revocation is confirmed.
+type ClusterQuarantineStatus struct {
+	// Since is the time the cluster was first observed quarantined
+	// +required
+	Since metav1.Time `json:"since"`
+
+	// RemoteSecretRevokedTime is the time the remote secret of the cluster was gone from all its peers
+	// +optional
+	RemoteSecretRevokedTime *metav1.Time `json:"remoteSecretRevokedTime,omitempty"`
+
+	// PeerSecretsRemovedTime is the time the remote secrets of the peers were gone from the cluster
+	// +optional
+	PeerSecretsRemovedTime *metav1.Time `json:"peerSecretsRemovedTime,omitempty"`
+
+	// ServiceAccountRevokedTime is the time the ManagedServiceAccount of the cluster was deleted, revoking its tokens
+	// +optional
+	ServiceAccountRevokedTime *metav1.Time `json:"serviceAccountRevokedTime,omitempty"`
+
+	// CertificateRevokedTime is the time the intermediate CA Certificate of the cluster and its secret were deleted
+	// from the hub, so it is no longer renewed nor distributed
+	// +optional
+	CertificateRevokedTime *metav1.Time `json:"certificateRevokedTime,omitempty"`
+
+	// TrustRevokedTime is the time every peer applied a certificate revocation list revoking the intermediate CA of
+	// the cluster, so the workload certificates it issued are no longer trusted
+	// +optional
+	TrustRevokedTime *metav1.Time `json:"trustRevokedTime,omitempty"`
+}
+
+// RevokedCertificate is an intermediate CA revoked by the quarantine of a cluster. It is listed in the certificate
+// revocation list distributed to the clusters until it expires.
+type RevokedCertificate struct {
+	// ClusterName is the name of the cluster the intermediate CA was issued to
+	// +required
+	ClusterName string `json:"clusterName"`
+
+	// SerialNumber is the serial number of the intermediate CA, in hexadecimal
+	// +required
+	SerialNumber string `json:"serialNumber"`
+
+	// RevocationTime is the time the intermediate CA was revoked
+	// +required
+	RevocationTime metav1.Time `json:"revocationTime"`
+
+	// ExpirationTime is the time the intermediate CA expires
+	// +required
+	ExpirationTime metav1.Time `json:"expirationTime"`
+}
+
+// ClusterDiscoveryStatus reports the ManagedServiceAccount token used by peers to discover a cluster
+type ClusterDiscoveryStatus struct {
+	// TokenExpirationTime is the time when the current token expires
+	// +optional
+	TokenExpirationTime *metav1.Time `json:"tokenExpirationTime,omitempty"`
+
+	// LastTokenRotationTime is the time when the token was last rotated
+	// +optional
+	LastTokenRotationTime *metav1.Time `json:"lastTokenRotationTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
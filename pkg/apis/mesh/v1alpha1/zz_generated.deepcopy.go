@@ -5,10 +5,32 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIServerConfig) DeepCopyInto(out *APIServerConfig) {
+	*out = *in
+	if in.ClientConfigIndex != nil {
+		in, out := &in.ClientConfigIndex, &out.ClientConfigIndex
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIServerConfig.
+func (in *APIServerConfig) DeepCopy() *APIServerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(APIServerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertManagerConfig) DeepCopyInto(out *CertManagerConfig) {
 	*out = *in
@@ -25,6 +47,29 @@ func (in *CertManagerConfig) DeepCopy() *CertManagerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDiscoveryStatus) DeepCopyInto(out *ClusterDiscoveryStatus) {
+	*out = *in
+	if in.TokenExpirationTime != nil {
+		in, out := &in.TokenExpirationTime, &out.TokenExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastTokenRotationTime != nil {
+		in, out := &in.LastTokenRotationTime, &out.LastTokenRotationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDiscoveryStatus.
+func (in *ClusterDiscoveryStatus) DeepCopy() *ClusterDiscoveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDiscoveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterMeshStatus) DeepCopyInto(out *ClusterMeshStatus) {
 	*out = *in
@@ -35,6 +80,21 @@ func (in *ClusterMeshStatus) DeepCopyInto(out *ClusterMeshStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Discovery != nil {
+		in, out := &in.Discovery, &out.Discovery
+		*out = new(ClusterDiscoveryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OperatorRollout != nil {
+		in, out := &in.OperatorRollout, &out.OperatorRollout
+		*out = new(ClusterOperatorRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quarantine != nil {
+		in, out := &in.Quarantine, &out.Quarantine
+		*out = new(ClusterQuarantineStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMeshStatus.
@@ -47,6 +107,81 @@ func (in *ClusterMeshStatus) DeepCopy() *ClusterMeshStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterOperatorRolloutStatus) DeepCopyInto(out *ClusterOperatorRolloutStatus) {
+	*out = *in
+	if in.HealthyTime != nil {
+		in, out := &in.HealthyTime, &out.HealthyTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterOperatorRolloutStatus.
+func (in *ClusterOperatorRolloutStatus) DeepCopy() *ClusterOperatorRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterOperatorRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProxyConfig) DeepCopyInto(out *ClusterProxyConfig) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProxyConfig.
+func (in *ClusterProxyConfig) DeepCopy() *ClusterProxyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProxyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterQuarantineStatus) DeepCopyInto(out *ClusterQuarantineStatus) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+	if in.RemoteSecretRevokedTime != nil {
+		in, out := &in.RemoteSecretRevokedTime, &out.RemoteSecretRevokedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PeerSecretsRemovedTime != nil {
+		in, out := &in.PeerSecretsRemovedTime, &out.PeerSecretsRemovedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ServiceAccountRevokedTime != nil {
+		in, out := &in.ServiceAccountRevokedTime, &out.ServiceAccountRevokedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CertificateRevokedTime != nil {
+		in, out := &in.CertificateRevokedTime, &out.CertificateRevokedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.TrustRevokedTime != nil {
+		in, out := &in.TrustRevokedTime, &out.TrustRevokedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterQuarantineStatus.
+func (in *ClusterQuarantineStatus) DeepCopy() *ClusterQuarantineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterQuarantineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlPlaneConfig) DeepCopyInto(out *ControlPlaneConfig) {
 	*out = *in
@@ -62,6 +197,31 @@ func (in *ControlPlaneConfig) DeepCopy() *ControlPlaneConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeletionPolicy) DeepCopyInto(out *DeletionPolicy) {
+	*out = *in
+	if in.CleanupTimeout != nil {
+		in, out := &in.CleanupTimeout, &out.CleanupTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RemovalGracePeriod != nil {
+		in, out := &in.RemovalGracePeriod, &out.RemovalGracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletionPolicy.
+func (in *DeletionPolicy) DeepCopy() *DeletionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeletionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DiscoveryConfig) DeepCopyInto(out *DiscoveryConfig) {
 	*out = *in
@@ -70,6 +230,12 @@ func (in *DiscoveryConfig) DeepCopyInto(out *DiscoveryConfig) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	in.APIServer.DeepCopyInto(&out.APIServer)
+	if in.ClusterProxy != nil {
+		in, out := &in.ClusterProxy, &out.ClusterProxy
+		*out = new(ClusterProxyConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveryConfig.
@@ -160,8 +326,26 @@ func (in *MultiClusterMeshList) DeepCopyObject() runtime.Object {
 func (in *MultiClusterMeshSpec) DeepCopyInto(out *MultiClusterMeshSpec) {
 	*out = *in
 	out.ControlPlane = in.ControlPlane
-	out.Operator = in.Operator
+	in.Operator.DeepCopyInto(&out.Operator)
+	if in.OperatorProfiles != nil {
+		in, out := &in.OperatorProfiles, &out.OperatorProfiles
+		*out = make([]OperatorProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VersionSkew != nil {
+		in, out := &in.VersionSkew, &out.VersionSkew
+		*out = new(VersionSkewPolicy)
+		**out = **in
+	}
 	in.Security.DeepCopyInto(&out.Security)
+	in.DeletionPolicy.DeepCopyInto(&out.DeletionPolicy)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterMeshSpec.
@@ -191,6 +375,18 @@ func (in *MultiClusterMeshStatus) DeepCopyInto(out *MultiClusterMeshStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.OperatorRollout != nil {
+		in, out := &in.OperatorRollout, &out.OperatorRollout
+		*out = new(OperatorRolloutStatus)
+		**out = **in
+	}
+	if in.RevokedCertificates != nil {
+		in, out := &in.RevokedCertificates, &out.RevokedCertificates
+		*out = make([]RevokedCertificate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterMeshStatus.
@@ -203,9 +399,45 @@ func (in *MultiClusterMeshStatus) DeepCopy() *MultiClusterMeshStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorCatalog) DeepCopyInto(out *OperatorCatalog) {
+	*out = *in
+	if in.PullSecrets != nil {
+		in, out := &in.PullSecrets, &out.PullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorCatalog.
+func (in *OperatorCatalog) DeepCopy() *OperatorCatalog {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorCatalog)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperatorConfig) DeepCopyInto(out *OperatorConfig) {
 	*out = *in
+	out.Manifests = in.Manifests
+	if in.Catalog != nil {
+		in, out := &in.Catalog, &out.Catalog
+		*out = new(OperatorCatalog)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(SubscriptionConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorConfig.
@@ -218,6 +450,126 @@ func (in *OperatorConfig) DeepCopy() *OperatorConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorManifests) DeepCopyInto(out *OperatorManifests) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorManifests.
+func (in *OperatorManifests) DeepCopy() *OperatorManifests {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorManifests)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorProfile) DeepCopyInto(out *OperatorProfile) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	in.Operator.DeepCopyInto(&out.Operator)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorProfile.
+func (in *OperatorProfile) DeepCopy() *OperatorProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorRolloutStatus) DeepCopyInto(out *OperatorRolloutStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorRolloutStatus.
+func (in *OperatorRolloutStatus) DeepCopy() *OperatorRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlatformSelector) DeepCopyInto(out *PlatformSelector) {
+	*out = *in
+	if in.Products != nil {
+		in, out := &in.Products, &out.Products
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Platforms != nil {
+		in, out := &in.Platforms, &out.Platforms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlatformSelector.
+func (in *PlatformSelector) DeepCopy() *PlatformSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(PlatformSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevokedCertificate) DeepCopyInto(out *RevokedCertificate) {
+	*out = *in
+	in.RevocationTime.DeepCopyInto(&out.RevocationTime)
+	in.ExpirationTime.DeepCopyInto(&out.ExpirationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevokedCertificate.
+func (in *RevokedCertificate) DeepCopy() *RevokedCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(RevokedCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
+	*out = *in
+	if in.CanaryClusters != nil {
+		in, out := &in.CanaryClusters, &out.CanaryClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxConcurrency != nil {
+		in, out := &in.MaxConcurrency, &out.MaxConcurrency
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxFailures != nil {
+		in, out := &in.MaxFailures, &out.MaxFailures
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	out.SoakTime = in.SoakTime
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityConfig) DeepCopyInto(out *SecurityConfig) {
 	*out = *in
@@ -235,6 +587,61 @@ func (in *SecurityConfig) DeepCopy() *SecurityConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionConfig) DeepCopyInto(out *SubscriptionConfig) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]corev1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionConfig.
+func (in *SubscriptionConfig) DeepCopy() *SubscriptionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriptionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrustConfig) DeepCopyInto(out *TrustConfig) {
 	*out = *in
@@ -250,3 +657,18 @@ func (in *TrustConfig) DeepCopy() *TrustConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionSkewPolicy) DeepCopyInto(out *VersionSkewPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionSkewPolicy.
+func (in *VersionSkewPolicy) DeepCopy() *VersionSkewPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionSkewPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,258 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+)
+
+// ManifestWorkNameOperatorProbe is the name of the read-only ManifestWork detecting a pre-existing operator Subscription on a cluster.
+const ManifestWorkNameOperatorProbe = "multicluster-mesh-operator-probe"
+
+// Names of the Subscription spec fields reported back to the hub by the operator probe ManifestWork.
+const (
+	FeedbackSubscriptionPackage         = "package"
+	FeedbackSubscriptionChannel         = "channel"
+	FeedbackSubscriptionSource          = "source"
+	FeedbackSubscriptionSourceNamespace = "sourceNamespace"
+)
+
+// globalOperatorNamespaces are the namespaces of the global OperatorGroups of OpenShift and upstream OLM,
+// where an operator installed from the console or by hand usually has its Subscription.
+var globalOperatorNamespaces = []string{"openshift-operators", "operators"}
+
+// operatorOwnership tells who installs the operator on a cluster.
+type operatorOwnership string
+
+const (
+	// operatorOwned means the add-on installs the operator with the operator ManifestWork.
+	operatorOwned operatorOwnership = "Owned"
+	// operatorDetecting means the cluster has not reported yet whether an operator Subscription already exists.
+	operatorDetecting operatorOwnership = "Detecting"
+	// operatorAdopted means a compatible operator Subscription already exists and is used as is.
+	operatorAdopted operatorOwnership = "Adopted"
+	// operatorConflicting means an operator Subscription already exists but does not match the operator configuration.
+	operatorConflicting operatorOwnership = "Conflicting"
+)
+
+// operatorDetection is the outcome of looking for a pre-existing operator Subscription on a cluster.
+type operatorDetection struct {
+	ownership operatorOwnership
+	// subscription is the namespace/name of the pre-existing Subscription
+	subscription string
+	// feedback holds the spec and status fields reported for the pre-existing Subscription
+	feedback map[string]string
+	// conflicts describes the fields of the pre-existing Subscription that differ from the operator configuration
+	conflicts []string
+}
+
+// managedByAddon reports whether the add-on installs the operator, or will install it, with the operator ManifestWork.
+func (d operatorDetection) managedByAddon() bool {
+	return d.ownership == operatorOwned || d.ownership == operatorDetecting
+}
+
+// detectExistingOperator determines who installs the operator of a cluster. The add-on owns the installation once the
+// operator ManifestWork exists. Before that, the read-only probe ManifestWork reports whether a Subscription for the
+// operator package already exists, since the work agent takes over any resource it applies and would delete it along
+// with the ManifestWork. Only the OLM install mode is probed.
+func (r *Reconciler) detectExistingOperator(ctx context.Context, clusterName string, config meshv1alpha1.OperatorConfig) (operatorDetection, error) {
+	if config.InstallMode == meshv1alpha1.OperatorInstallModeManifests {
+		return operatorDetection{ownership: operatorOwned}, nil
+	}
+
+	if err := r.Get(ctx, key.Of(OperatorManifestWorkName, clusterName), &workv1.ManifestWork{}); err == nil {
+		return operatorDetection{ownership: operatorOwned}, nil
+	} else if !apierrors.IsNotFound(err) {
+		return operatorDetection{}, fmt.Errorf("failed to get operator ManifestWork for cluster %s: %w", clusterName, err)
+	}
+
+	probe := &workv1.ManifestWork{}
+	if err := r.Get(ctx, key.Of(ManifestWorkNameOperatorProbe, clusterName), probe); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return operatorDetection{}, fmt.Errorf("failed to get operator probe ManifestWork for cluster %s: %w", clusterName, err)
+		}
+		return detectSubscription(nil, config), nil
+	}
+	return detectSubscription(probe, config), nil
+}
+
+// detectSubscription determines from the probe ManifestWork whether an operator Subscription exists on a cluster.
+// Detection is pending until the work agent reports the existence of every probed Subscription, and the spec of the
+// Subscription found. The Subscription in the operator namespace is preferred over the global operator namespaces.
+func detectSubscription(probe *workv1.ManifestWork, config meshv1alpha1.OperatorConfig) operatorDetection {
+	if probe == nil {
+		return operatorDetection{ownership: operatorDetecting}
+	}
+
+	for _, namespace := range probedSubscriptionNamespaces(config) {
+		index := slices.IndexFunc(probe.Status.ResourceStatus.Manifests, func(m workv1.ManifestCondition) bool {
+			return m.ResourceMeta.Resource == "subscriptions" && m.ResourceMeta.Namespace == namespace && m.ResourceMeta.Name == config.Name
+		})
+		if index < 0 {
+			return operatorDetection{ownership: operatorDetecting}
+		}
+		status := probe.Status.ResourceStatus.Manifests[index]
+		available := meta.FindStatusCondition(status.Conditions, string(workv1.ManifestAvailable))
+		if available == nil || available.Status == metav1.ConditionUnknown {
+			return operatorDetection{ownership: operatorDetecting}
+		}
+		if available.Status == metav1.ConditionFalse {
+			continue
+		}
+
+		feedback := feedbackValues(status.StatusFeedbacks.Values)
+		if feedback[FeedbackSubscriptionPackage] == "" {
+			return operatorDetection{ownership: operatorDetecting}
+		}
+		detection := operatorDetection{
+			ownership:    operatorAdopted,
+			subscription: namespace + "/" + config.Name,
+			feedback:     feedback,
+			conflicts:    subscriptionConflicts(feedback, config),
+		}
+		if len(detection.conflicts) > 0 {
+			detection.ownership = operatorConflicting
+		}
+		return detection
+	}
+	return operatorDetection{ownership: operatorOwned}
+}
+
+// subscriptionConflicts describes the fields of a pre-existing Subscription that differ from the operator configuration.
+func subscriptionConflicts(feedback map[string]string, config meshv1alpha1.OperatorConfig) []string {
+	var conflicts []string
+	for _, field := range []struct{ name, existing, desired string }{
+		{"package", feedback[FeedbackSubscriptionPackage], config.Name},
+		{"channel", feedback[FeedbackSubscriptionChannel], config.Channel},
+		{"source", feedback[FeedbackSubscriptionSource], config.Source},
+		{"sourceNamespace", feedback[FeedbackSubscriptionSourceNamespace], config.SourceNamespace},
+	} {
+		if field.existing != field.desired {
+			conflicts = append(conflicts, fmt.Sprintf("%s is %q instead of %q", field.name, field.existing, field.desired))
+		}
+	}
+	return conflicts
+}
+
+// probedSubscriptionNamespaces returns the namespaces where a pre-existing operator Subscription is looked for,
+// the operator namespace first.
+func probedSubscriptionNamespaces(config meshv1alpha1.OperatorConfig) []string {
+	namespaces := []string{config.Namespace}
+	for _, namespace := range globalOperatorNamespaces {
+		if !slices.Contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// existingOperatorState derives the OperatorApplied condition of a cluster whose operator is not installed by the add-on,
+// or not yet. A conflicting Subscription must be changed or removed by the user, the add-on never overwrites it.
+func existingOperatorState(detection operatorDetection) (status metav1.ConditionStatus, reason, message string) {
+	switch detection.ownership {
+	case operatorAdopted:
+		return metav1.ConditionTrue, meshv1alpha1.ReasonOperatorAdopted,
+			fmt.Sprintf("Adopted the pre-existing Subscription %s, it is left in place when the cluster leaves the mesh", detection.subscription)
+	case operatorConflicting:
+		return metav1.ConditionFalse, meshv1alpha1.ReasonConfigurationConflict,
+			fmt.Sprintf("Pre-existing Subscription %s conflicts with the operator configuration: %s", detection.subscription, strings.Join(detection.conflicts, ", "))
+	default:
+		return metav1.ConditionFalse, meshv1alpha1.ReasonDetectionPending,
+			"Waiting for the cluster to report whether an operator Subscription already exists"
+	}
+}
+
+// ensureOperatorProbe applies the probe ManifestWork looking for a pre-existing operator Subscription on a cluster.
+func (r *Reconciler) ensureOperatorProbe(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, cluster *clusterv1.ManagedCluster, config meshv1alpha1.OperatorConfig) error {
+	work, err := r.workApplier.Apply(ctx, buildOperatorProbeManifestWork(mesh, cluster.Name, config))
+	if err != nil {
+		return fmt.Errorf("failed to apply operator probe ManifestWork: %w", err)
+	}
+	klog.V(4).Infof("Applied operator probe ManifestWork %s/%s", work.Namespace, work.Name)
+	return nil
+}
+
+// deleteOperatorProbe deletes the probe ManifestWork of a cluster once the add-on owns the operator installation.
+func (r *Reconciler) deleteOperatorProbe(ctx context.Context, clusterName string) error {
+	existing := &workv1.ManifestWork{}
+	if err := r.Get(ctx, key.Of(ManifestWorkNameOperatorProbe, clusterName), existing); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get operator probe ManifestWork: %w", err)
+	}
+	klog.Infof("Deleting operator probe ManifestWork %s/%s", existing.Namespace, existing.Name)
+	return r.workApplier.Delete(ctx, existing.Namespace, existing.Name)
+}
+
+// buildOperatorProbeManifestWork builds a ManifestWork that reports whether a Subscription for the operator package exists
+// in the operator namespace or a global operator namespace. The Subscriptions are read-only, so the work agent neither
+// changes nor deletes them, and they report their spec and status back to the hub. The work agent is granted read
+// access to Subscriptions by the same ClusterRole as for the operator ManifestWork.
+func buildOperatorProbeManifestWork(mesh *meshv1alpha1.MultiClusterMesh, clusterName string, config meshv1alpha1.OperatorConfig) *workv1.ManifestWork {
+	manifests := []workv1.Manifest{buildWorkAgentOLMClusterRole()}
+	var manifestConfigs []workv1.ManifestConfigOption
+	feedbackPaths := append([]workv1.JsonPath{
+		{Name: FeedbackSubscriptionPackage, Path: ".spec.name"},
+		{Name: FeedbackSubscriptionChannel, Path: ".spec.channel"},
+		{Name: FeedbackSubscriptionSource, Path: ".spec.source"},
+		{Name: FeedbackSubscriptionSourceNamespace, Path: ".spec.sourceNamespace"},
+	}, subscriptionFeedbackPaths()...)
+	for _, namespace := range probedSubscriptionNamespaces(config) {
+		manifests = append(manifests, workv1.Manifest{RawExtension: runtime.RawExtension{Object: &operatorsv1alpha1.Subscription{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "operators.coreos.com/v1alpha1",
+				Kind:       "Subscription",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      config.Name,
+				Namespace: namespace,
+			},
+		}}})
+		manifestConfigs = append(manifestConfigs, workv1.ManifestConfigOption{
+			ResourceIdentifier: workv1.ResourceIdentifier{
+				Group:     "operators.coreos.com",
+				Resource:  "subscriptions",
+				Name:      config.Name,
+				Namespace: namespace,
+			},
+			UpdateStrategy: &workv1.UpdateStrategy{
+				Type: workv1.UpdateStrategyTypeReadOnly,
+			},
+			FeedbackRules: []workv1.FeedbackRule{{
+				Type:      workv1.JSONPathsType,
+				JsonPaths: feedbackPaths,
+			}},
+		})
+	}
+
+	return &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ManifestWorkNameOperatorProbe,
+			Namespace: clusterName,
+			Labels: map[string]string{
+				ManagedByLabel:  ManagedByValue,
+				ClusterSetLabel: mesh.Spec.ClusterSet,
+			},
+		},
+		Spec: workv1.ManifestWorkSpec{
+			Workload: workv1.ManifestsTemplate{
+				Manifests: manifests,
+			},
+			ManifestConfigs: manifestConfigs,
+		},
+	}
+}
@@ -0,0 +1,146 @@
+package mesh
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestDetectSubscription(t *testing.T) {
+	config := meshv1alpha1.OperatorConfig{
+		Name:            "servicemeshoperator3",
+		Namespace:       "multicluster-mesh-operator",
+		Channel:         "stable",
+		Source:          "redhat-operators",
+		SourceNamespace: "openshift-marketplace",
+	}
+	subscription := func(namespace string, available metav1.ConditionStatus, feedback map[string]string) workv1.ManifestCondition {
+		condition := workv1.ManifestCondition{
+			ResourceMeta: workv1.ManifestResourceMeta{
+				Group: "operators.coreos.com", Resource: "subscriptions", Name: config.Name, Namespace: namespace,
+			},
+			Conditions: []metav1.Condition{{Type: workv1.ManifestAvailable, Status: available}},
+		}
+		for name, value := range feedback {
+			condition.StatusFeedbacks.Values = append(condition.StatusFeedbacks.Values, workv1.FeedbackValue{
+				Name: name, Value: workv1.FieldValue{Type: workv1.String, String: &value},
+			})
+		}
+		return condition
+	}
+	probe := func(manifests ...workv1.ManifestCondition) *workv1.ManifestWork {
+		return &workv1.ManifestWork{Status: workv1.ManifestWorkStatus{ResourceStatus: workv1.ManifestResourceStatus{Manifests: manifests}}}
+	}
+	compatible := map[string]string{
+		FeedbackSubscriptionPackage:         "servicemeshoperator3",
+		FeedbackSubscriptionChannel:         "stable",
+		FeedbackSubscriptionSource:          "redhat-operators",
+		FeedbackSubscriptionSourceNamespace: "openshift-marketplace",
+		FeedbackInstalledCSV:                "servicemeshoperator3.v3.0.0",
+	}
+	otherChannel := map[string]string{
+		FeedbackSubscriptionPackage:         "servicemeshoperator3",
+		FeedbackSubscriptionChannel:         "candidates",
+		FeedbackSubscriptionSource:          "redhat-operators",
+		FeedbackSubscriptionSourceNamespace: "openshift-marketplace",
+	}
+
+	tests := []struct {
+		name                 string
+		probe                *workv1.ManifestWork
+		expectedOwnership    operatorOwnership
+		expectedSubscription string
+		expectedConflicts    int
+	}{
+		{
+			name:              "no probe",
+			expectedOwnership: operatorDetecting,
+		},
+		{
+			name:              "not reported yet",
+			probe:             probe(),
+			expectedOwnership: operatorDetecting,
+		},
+		{
+			name: "some namespaces not reported yet",
+			probe: probe(
+				subscription("multicluster-mesh-operator", metav1.ConditionFalse, nil),
+				subscription("openshift-operators", metav1.ConditionFalse, nil),
+			),
+			expectedOwnership: operatorDetecting,
+		},
+		{
+			name: "no Subscription",
+			probe: probe(
+				subscription("multicluster-mesh-operator", metav1.ConditionFalse, nil),
+				subscription("openshift-operators", metav1.ConditionFalse, nil),
+				subscription("operators", metav1.ConditionFalse, nil),
+			),
+			expectedOwnership: operatorOwned,
+		},
+		{
+			name: "feedback not synced yet",
+			probe: probe(
+				subscription("multicluster-mesh-operator", metav1.ConditionFalse, nil),
+				subscription("openshift-operators", metav1.ConditionTrue, nil),
+				subscription("operators", metav1.ConditionFalse, nil),
+			),
+			expectedOwnership: operatorDetecting,
+		},
+		{
+			name: "compatible Subscription",
+			probe: probe(
+				subscription("multicluster-mesh-operator", metav1.ConditionFalse, nil),
+				subscription("openshift-operators", metav1.ConditionTrue, compatible),
+				subscription("operators", metav1.ConditionFalse, nil),
+			),
+			expectedOwnership:    operatorAdopted,
+			expectedSubscription: "openshift-operators/servicemeshoperator3",
+		},
+		{
+			name: "prefers the operator namespace",
+			probe: probe(
+				subscription("multicluster-mesh-operator", metav1.ConditionTrue, otherChannel),
+				subscription("openshift-operators", metav1.ConditionTrue, compatible),
+				subscription("operators", metav1.ConditionFalse, nil),
+			),
+			expectedOwnership:    operatorConflicting,
+			expectedSubscription: "multicluster-mesh-operator/servicemeshoperator3",
+			expectedConflicts:    1,
+		},
+		{
+			name: "Subscription from another catalog",
+			probe: probe(
+				subscription("multicluster-mesh-operator", metav1.ConditionFalse, nil),
+				subscription("openshift-operators", metav1.ConditionFalse, nil),
+				subscription("operators", metav1.ConditionTrue, map[string]string{
+					FeedbackSubscriptionPackage:         "servicemeshoperator3",
+					FeedbackSubscriptionChannel:         "stable",
+					FeedbackSubscriptionSource:          "mirror",
+					FeedbackSubscriptionSourceNamespace: "olm",
+				}),
+			),
+			expectedOwnership:    operatorConflicting,
+			expectedSubscription: "operators/servicemeshoperator3",
+			expectedConflicts:    2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			detection := detectSubscription(tc.probe, config)
+			if detection.ownership != tc.expectedOwnership {
+				t.Errorf("detectSubscription() ownership = %s, want %s", detection.ownership, tc.expectedOwnership)
+			}
+			if detection.subscription != tc.expectedSubscription {
+				t.Errorf("detectSubscription() subscription = %s, want %s", detection.subscription, tc.expectedSubscription)
+			}
+			if len(detection.conflicts) != tc.expectedConflicts {
+				t.Errorf("detectSubscription() conflicts = %v, want %d conflicts", detection.conflicts, tc.expectedConflicts)
+			}
+		})
+	}
+}
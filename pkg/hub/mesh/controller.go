@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
 	"slices"
 	"strings"
@@ -16,19 +17,21 @@ import (
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
 	applyconfigv1 "k8s.io/client-go/applyconfigurations/meta/v1"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	workclient "open-cluster-management.io/api/client/work/clientset/versioned"
 	workinformers "open-cluster-management.io/api/client/work/informers/externalversions"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
-	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
 	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
 	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
 	"open-cluster-management.io/sdk-go/pkg/apis/work/v1/applier"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -49,8 +52,6 @@ const (
 	ManifestWorkNameCacerts    = "multicluster-mesh-cacerts"
 	ManifestWorkNameCPNSPrefix = "multicluster-mesh-cp-ns-"
 
-	FeedbackInstalledCSV = "installedCSV"
-
 	CacertsSecretName = "cacerts"
 
 	FinalizerName = "mesh.open-cluster-management.io/finalizer"
@@ -81,6 +82,9 @@ func RegisterController(mgr manager.Manager) error {
 	}); err != nil {
 		return fmt.Errorf("failed to create field index: %w", err)
 	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &meshv1alpha1.MultiClusterMesh{}, operatorConfigMapIndex, operatorConfigMapNames); err != nil {
+		return fmt.Errorf("failed to create field index: %w", err)
+	}
 
 	workClient, err := workclient.NewForConfig(mgr.GetConfig())
 	if err != nil {
@@ -112,6 +116,7 @@ func RegisterController(mgr manager.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&meshv1alpha1.MultiClusterMesh{}).
 		Owns(&certmanagerv1.Certificate{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&workv1alpha1.ManifestWorkReplicaSet{}).
 		Watches(
 			&clusterv1.ManagedCluster{},
 			handler.EnqueueRequestsFromMapFunc(reconciler.findMeshesForCluster),
@@ -133,11 +138,17 @@ func RegisterController(mgr manager.Manager) error {
 				return obj.GetLabels()[MeshNameLabel] != "" && obj.GetLabels()[MeshNamespaceLabel] != ""
 			})),
 		).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(reconciler.findMeshesForConfigMap),
+			builder.WithPredicates(predicate.NewPredicateFuncs(reconciler.isOperatorConfigMap)),
+		).
 		Watches(
 			&workv1.ManifestWork{},
 			handler.EnqueueRequestsFromMapFunc(reconciler.findMeshesForManifestWork),
 			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
-				return obj.GetLabels()[ManagedByLabel] == ManagedByValue
+				// ManifestWorks generated from remote secret ManifestWorkReplicaSets don't inherit the managed-by label.
+				return obj.GetLabels()[ManagedByLabel] == ManagedByValue ||
+					obj.GetLabels()[workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey] != ""
 			})),
 		).
 		Complete(reconciler)
@@ -150,12 +161,14 @@ func RegisterController(mgr manager.Manager) error {
 //+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclustersets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclustersetbindings,verbs=get;list;watch;create;delete
 //+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclustersets/bind,verbs=create
-//+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=placements,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=placements,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworkreplicasets,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworkreplicasets,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cert-manager.io,resources=issuers;clusterissuers,verbs=get;list;watch
 //+kubebuilder:rbac:groups=authentication.open-cluster-management.io,resources=managedserviceaccounts,verbs=get;list;watch;create;update;delete
-//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 
 // Reconcile implements the reconcile loop for MultiClusterMesh resources
 func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
@@ -170,7 +183,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 
 	if !mesh.DeletionTimestamp.IsZero() {
 		klog.Infof("MultiClusterMesh being deleted: %s/%s", req.Namespace, req.Name)
-		return reconcile.Result{}, r.handleDeletion(ctx, mesh)
+		return r.handleDeletion(ctx, mesh)
 	}
 
 	if !controllerutil.ContainsFinalizer(mesh, FinalizerName) {
@@ -186,19 +199,52 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 
 	var reconcileErr error
 	var conflict bool
+	var requeueAfter time.Duration
+	var removal *clusterRemoval
+	var readiness peerReadiness
+	var revocation *revocationList
 	if conflict, reconcileErr = r.validate(ctx, mesh); reconcileErr != nil {
 		mesh.SetReadyCondition(metav1.ConditionFalse, meshv1alpha1.ReasonReconcileError, "%v", reconcileErr)
 	} else if !conflict {
 		clusters, err := r.getClustersFromSet(ctx, mesh.Spec.ClusterSet)
 		if err != nil {
 			reconcileErr = fmt.Errorf("failed to get clusters from set %s: %w", mesh.Spec.ClusterSet, err)
+		} else if rollout, err := r.planOperatorRollout(ctx, mesh, clusters); err != nil {
+			reconcileErr = fmt.Errorf("failed to plan operator rollout: %w", err)
+		} else if readiness, err = r.planPeerReadiness(ctx, mesh, clusters, rollout); err != nil {
+			reconcileErr = fmt.Errorf("failed to plan peer readiness: %w", err)
+		} else if removal, err = r.planClusterRemoval(ctx, mesh, clusters); err != nil {
+			reconcileErr = fmt.Errorf("failed to plan cluster removal: %w", err)
+		} else if revocation, err = r.ensureRevocationList(ctx, mesh); err != nil {
+			reconcileErr = fmt.Errorf("failed to ensure the certificate revocation list: %w", err)
 		} else {
-			reconcileErr = r.doReconcile(ctx, mesh, clusters)
+			requeueAfter = rollout.requeueAfter
+			if removal.requeueAfter > 0 && (requeueAfter == 0 || removal.requeueAfter < requeueAfter) {
+				requeueAfter = removal.requeueAfter
+			}
+			if mesh.Spec.Suspend {
+				klog.Infof("MultiClusterMesh %s/%s is suspended, holding changes to its clusters", mesh.Namespace, mesh.Name)
+			} else {
+				reconcileErr = r.doReconcile(ctx, mesh, clusters, rollout, readiness, removal, revocation)
+			}
+			if reconcileErr == nil {
+				reconcileErr = r.quarantineClusters(ctx, mesh, clusters, revocation)
+			}
+			if after := revocationRequeueAfter(mesh); after > 0 && (requeueAfter == 0 || after < requeueAfter) {
+				requeueAfter = after
+			}
+			if len(removal.teardowns) > 0 && (requeueAfter == 0 || teardownRequeueInterval < requeueAfter) {
+				requeueAfter = teardownRequeueInterval
+			}
 		}
 
 		if reconcileErr == nil {
 			klog.Infof("Successfully reconciled MultiClusterMesh %s/%s", mesh.Namespace, mesh.Name)
 			reconcileErr = r.determineStatus(ctx, mesh, clusters)
+			setSuspendedConditions(mesh, clusters)
+			setPeerReadyConditions(mesh, readiness)
+			setQuarantinedConditions(mesh, clusters, revocation)
+			setRemovalStatus(mesh, removal)
 		}
 
 		if reconcileErr != nil {
@@ -207,20 +253,24 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		}
 	}
 
-	var statusErr error
-	if !reflect.DeepEqual(oldStatus, &mesh.Status) {
-		newStatus := mesh.Status
-		statusErr = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			latest := &meshv1alpha1.MultiClusterMesh{}
-			if err := r.Get(ctx, req.NamespacedName, latest); err != nil {
-				return err
-			}
-			latest.Status = newStatus
-			return r.Status().Update(ctx, latest)
-		})
-	}
+	statusErr := r.updateStatus(ctx, mesh, oldStatus)
+	return reconcile.Result{RequeueAfter: requeueAfter}, errors.Join(reconcileErr, statusErr)
+}
 
-	return reconcile.Result{}, errors.Join(reconcileErr, statusErr)
+// updateStatus writes the status of the mesh if it changed from the old status, retrying on conflicts.
+func (r *Reconciler) updateStatus(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, oldStatus *meshv1alpha1.MultiClusterMeshStatus) error {
+	if reflect.DeepEqual(oldStatus, &mesh.Status) {
+		return nil
+	}
+	newStatus := mesh.Status
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &meshv1alpha1.MultiClusterMesh{}
+		if err := r.Get(ctx, key.For(mesh), latest); err != nil {
+			return err
+		}
+		latest.Status = newStatus
+		return r.Status().Update(ctx, latest)
+	})
 }
 
 // validate checks for conflicts that prevent reconciliation.
@@ -228,14 +278,27 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 func (r *Reconciler) validate(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) (conflict bool, err error) {
 	// CEL cross-field rule on the spec struct exceeds the estimated cost budget,
 	// so this is validated here instead of via kubebuilder markers.
-	if mesh.GetControlPlaneNamespace() == mesh.Spec.Operator.Namespace {
-		mesh.SetReadyCondition(metav1.ConditionFalse, meshv1alpha1.ReasonNamespaceConflict,
-			"controlPlane.namespace %q must not equal operator.namespace", mesh.GetControlPlaneNamespace())
-		return true, nil
+	for _, config := range operatorConfigs(mesh) {
+		if mesh.GetControlPlaneNamespace() == config.Namespace {
+			mesh.SetReadyCondition(metav1.ConditionFalse, meshv1alpha1.ReasonNamespaceConflict,
+				"controlPlane.namespace %q must not equal operator.namespace", mesh.GetControlPlaneNamespace())
+			return true, nil
+		}
+	}
+
+	for _, profile := range mesh.Spec.OperatorProfiles {
+		if v := profile.Selector.MinKubeVersion; v != "" {
+			if _, err := utilversion.ParseGeneric(v); err != nil {
+				mesh.SetReadyCondition(metav1.ConditionFalse, meshv1alpha1.ReasonInvalidOperatorProfile,
+					"operator profile %q has an invalid minKubeVersion: %v", profile.Name, err)
+				return true, nil
+			}
+		}
 	}
 
+	var clusters []clusterv1.ManagedCluster
 	if err = r.forEachMeshInClusterSet(ctx, mesh.Spec.ClusterSet, func(other *meshv1alpha1.MultiClusterMesh) {
-		if other.UID == mesh.UID || conflict {
+		if other.UID == mesh.UID || conflict || err != nil {
 			return
 		}
 		if isOlderMesh(mesh, other) {
@@ -248,11 +311,57 @@ func (r *Reconciler) validate(ctx context.Context, mesh *meshv1alpha1.MultiClust
 			conflict = true
 			return
 		}
-		if mesh.Spec.Operator != other.Spec.Operator {
+		// The operator ManifestWork is shared by the meshes of the ClusterSet, and carries the operator deletion policy.
+		if policy, otherPolicy := deletionPolicy(mesh, mesh.Spec.DeletionPolicy.Operator), deletionPolicy(other, other.Spec.DeletionPolicy.Operator); policy != otherPolicy {
 			mesh.SetReadyCondition(metav1.ConditionFalse, meshv1alpha1.ReasonOperatorConfigConflict,
-				"operator config conflicts with older mesh %s/%s targeting the same ClusterSet %s",
+				"operator deletion policy %s conflicts with policy %s of older mesh %s/%s targeting the same ClusterSet %s",
+				policy, otherPolicy, other.Namespace, other.Name, mesh.Spec.ClusterSet)
+			conflict = true
+			return
+		}
+		// Whichever mesh reconciles last applies the shared operator ManifestWork, so meshes rolling out differently
+		// would undo each other's staging.
+		if !equality.Semantic.DeepEqual(mesh.Spec.Rollout, other.Spec.Rollout) {
+			mesh.SetReadyCondition(metav1.ConditionFalse, meshv1alpha1.ReasonOperatorConfigConflict,
+				"operator rollout strategy conflicts with older mesh %s/%s targeting the same ClusterSet %s",
 				other.Namespace, other.Name, mesh.Spec.ClusterSet)
 			conflict = true
+			return
+		}
+
+		// Without operator profiles, spec.operator applies to every cluster and is compared once.
+		if len(mesh.Spec.OperatorProfiles) == 0 && len(other.Spec.OperatorProfiles) == 0 {
+			if what := operatorConfigConflict(mesh, other, mesh.Spec.Operator, other.Spec.Operator); what != "" {
+				mesh.SetReadyCondition(metav1.ConditionFalse, meshv1alpha1.ReasonOperatorConfigConflict,
+					"%s conflicts with older mesh %s/%s targeting the same ClusterSet %s",
+					what, other.Namespace, other.Name, mesh.Spec.ClusterSet)
+				conflict = true
+			}
+			return
+		}
+
+		// Operator profiles resolve per cluster, so the configs are compared for every cluster of the set, once per
+		// pair of profiles the clusters resolve to.
+		if clusters == nil {
+			if clusters, err = r.getClustersFromSet(ctx, mesh.Spec.ClusterSet); err != nil {
+				return
+			}
+		}
+		compared := map[[2]string]bool{}
+		for _, cluster := range clusters {
+			config, profile := clusterOperatorConfig(mesh, &cluster)
+			otherConfig, otherProfile := clusterOperatorConfig(other, &cluster)
+			if compared[[2]string{profile, otherProfile}] {
+				continue
+			}
+			compared[[2]string{profile, otherProfile}] = true
+			if what := operatorConfigConflict(mesh, other, config, otherConfig); what != "" {
+				mesh.SetReadyCondition(metav1.ConditionFalse, meshv1alpha1.ReasonOperatorConfigConflict,
+					"%s for cluster %s conflicts with older mesh %s/%s targeting the same ClusterSet %s",
+					what, cluster.Name, other.Namespace, other.Name, mesh.Spec.ClusterSet)
+				conflict = true
+				return
+			}
 		}
 	}); err != nil {
 		return false, fmt.Errorf("failed to validate: %w", err)
@@ -261,6 +370,20 @@ func (r *Reconciler) validate(ctx context.Context, mesh *meshv1alpha1.MultiClust
 	return conflict, nil
 }
 
+// operatorConfigConflict describes why two meshes would install a different operator with the given configs, or
+// returns an empty string if they install the same one.
+func operatorConfigConflict(mesh, other *meshv1alpha1.MultiClusterMesh, config, otherConfig meshv1alpha1.OperatorConfig) string {
+	if !equality.Semantic.DeepEqual(config, otherConfig) {
+		return "operator config"
+	}
+	// The operator manifests are read from a ConfigMap in the mesh namespace, so equal configs only install the same
+	// operator if the meshes share a namespace.
+	if config.InstallMode == meshv1alpha1.OperatorInstallModeManifests && mesh.Namespace != other.Namespace {
+		return "operator manifests ConfigMap"
+	}
+	return ""
+}
+
 // isOlderMesh returns true if a is older than b, using namespace/name as tiebreaker for equal timestamps.
 func isOlderMesh(a, b *meshv1alpha1.MultiClusterMesh) bool {
 	return a.CreationTimestamp.Before(&b.CreationTimestamp) ||
@@ -268,21 +391,26 @@ func isOlderMesh(a, b *meshv1alpha1.MultiClusterMesh) bool {
 			key.For(a).String() < key.For(b).String())
 }
 
-func (r *Reconciler) doReconcile(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster) error {
+func (r *Reconciler) doReconcile(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster, rollout *operatorRollout, readiness peerReadiness, removal *clusterRemoval, revocation *revocationList) error {
+	maintenance, err := r.clustersInMaintenance(ctx)
+	if err != nil {
+		return err
+	}
+
 	for _, cluster := range clusters {
 		klog.V(4).Infof("Reconciling cluster %s", cluster.Name)
 
-		cpNsWork, err := r.workApplier.Apply(ctx, r.buildControlPlaneNamespaceManifestWork(mesh, &cluster))
-		if err != nil {
-			return fmt.Errorf("failed to apply control plane namespace ManifestWork on cluster %s: %w", cluster.Name, err)
+		if maintenance[cluster.Name] {
+			klog.Infof("Cluster %s is in maintenance, holding changes to its ManifestWorks", cluster.Name)
+		} else if err := r.ensureClusterManifestWorks(ctx, mesh, &cluster, rollout); err != nil {
+			return err
 		}
-		klog.V(4).Infof("Applied control plane namespace ManifestWork %s/%s", cpNsWork.Namespace, cpNsWork.Name)
 
-		work, err := r.workApplier.Apply(ctx, r.buildOperatorManifestWork(mesh, &cluster))
-		if err != nil {
-			return fmt.Errorf("failed to apply operator ManifestWork on cluster %s: %w", cluster.Name, err)
+		// The credentials of a quarantined cluster are revoked by quarantineClusters instead, and the ones of a cluster in
+		// maintenance are left as they are.
+		if quarantined(&cluster) || maintenance[cluster.Name] {
+			continue
 		}
-		klog.V(4).Infof("Applied operator ManifestWork %s/%s", work.Namespace, work.Name)
 
 		if err := r.ensureManagedServiceAccount(ctx, mesh, &cluster); err != nil {
 			return fmt.Errorf("failed to ensure ManagedServiceAccount for cluster %s: %w", cluster.Name, err)
@@ -292,32 +420,37 @@ func (r *Reconciler) doReconcile(ctx context.Context, mesh *meshv1alpha1.MultiCl
 			if err := r.ensureCertificateForCluster(ctx, mesh, &cluster); err != nil {
 				return fmt.Errorf("failed to ensure certificate for cluster %s: %w", cluster.Name, err)
 			}
-			if err := r.ensureCacertsManifestWork(ctx, mesh, &cluster); err != nil {
+			if err := r.ensureCacertsManifestWork(ctx, mesh, &cluster, revocation); err != nil {
 				return fmt.Errorf("failed to ensure cacerts ManifestWork for cluster %s: %w", cluster.Name, err)
 			}
 		}
 	}
 
+	// The clusters pending removal keep their resources until the removal grace period expires.
+	retained := removal.retained(clusters)
+	held := maps.Clone(maintenance)
+	for _, cluster := range removal.pending {
+		held[cluster.Name] = true
+	}
+
 	if mesh.Spec.Security.Trust.CertManager.IssuerRef.Name == "" {
 		if err := r.deleteAllCertificates(ctx, mesh); err != nil {
 			return fmt.Errorf("failed to cleanup Certificates: %w", err)
 		}
-	} else {
-		if err := r.deleteCertificatesForRemovedClusters(ctx, mesh, clusters); err != nil {
-			return fmt.Errorf("failed to cleanup Certificates: %w", err)
-		}
 	}
 
-	if err := r.cleanupMeshOwnedManifestWorks(ctx, mesh, clusters); err != nil {
-		return fmt.Errorf("failed to cleanup mesh-owned ManifestWorks: %w", err)
+	// The clusters that left the mesh are removed step by step, and keep the shared ManifestWorks until the last one.
+	if removal.teardowns, err = r.teardownRemovedClusters(ctx, mesh, retained, maintenance); err != nil {
+		return err
 	}
-
-	if err := r.cleanupManifestWorks(ctx, mesh.Spec.ClusterSet); err != nil {
-		return fmt.Errorf("failed to cleanup ManifestWorks: %w", err)
+	for clusterName, teardown := range removal.teardowns {
+		if teardown.step < teardownOperator {
+			held[clusterName] = true
+		}
 	}
 
-	if err := r.cleanupManagedServiceAccounts(ctx, mesh, clusters); err != nil {
-		return fmt.Errorf("failed to cleanup ManagedServiceAccounts: %w", err)
+	if err := r.cleanupManifestWorks(ctx, mesh.Spec.ClusterSet, held); err != nil {
+		return fmt.Errorf("failed to cleanup ManifestWorks: %w", err)
 	}
 
 	clusterSetExists, err := r.clusterSetExists(ctx, mesh.Spec.ClusterSet)
@@ -329,15 +462,60 @@ func (r *Reconciler) doReconcile(ctx context.Context, mesh *meshv1alpha1.MultiCl
 		if err := r.ensureManagedClusterSetBinding(ctx, mesh); err != nil {
 			return fmt.Errorf("failed to ensure ManagedClusterSetBinding for mesh %s binding %s: %w", mesh.Name, mesh.Spec.ClusterSet, err)
 		}
-		if err := r.ensurePlacement(ctx, mesh); err != nil {
-			return fmt.Errorf("failed to ensure Placement for mesh %s/%s: %w", mesh.Namespace, mesh.Name, err)
-		}
 	}
 
-	if err := r.ensureRemoteSecretDistribution(ctx, mesh, clusters); err != nil {
-		return fmt.Errorf("failed to ensure ManifestWorkReplicaSet for mesh %s/%s: %w", mesh.Namespace, mesh.Name, err)
+	// The clusters in maintenance hold the remote secrets of their peers through direct ManifestWorks, and are only left
+	// out of the Placements of their peers once those are applied.
+	covered, err := r.ensureDirectRemoteSecrets(ctx, mesh, clusters, removal.peerSecretReceivers(), maintenance)
+	if err != nil {
+		return fmt.Errorf("failed to ensure direct remote secrets for mesh %s/%s: %w", mesh.Namespace, mesh.Name, err)
+	}
+	// The clusters that did not pass the readiness gate are kept out of the peers until they do.
+	if err := r.ensureRemoteSecretDistribution(ctx, mesh, retained, excludedPeers(readiness, clusters), covered); err != nil {
+		return fmt.Errorf("failed to ensure remote secret distribution for mesh %s/%s: %w", mesh.Namespace, mesh.Name, err)
+	}
+
+	return nil
+}
+
+// ensureClusterManifestWorks applies the ManifestWorks of the mesh to a cluster: the control plane namespace, and the
+// operator or the operator probe, and the istiod probe of the Istiod readiness gate.
+func (r *Reconciler) ensureClusterManifestWorks(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, cluster *clusterv1.ManagedCluster, rollout *operatorRollout) error {
+	cpNsWork, err := r.workApplier.Apply(ctx, r.buildControlPlaneNamespaceManifestWork(mesh, cluster))
+	if err != nil {
+		return fmt.Errorf("failed to apply control plane namespace ManifestWork on cluster %s: %w", cluster.Name, err)
+	}
+	klog.V(4).Infof("Applied control plane namespace ManifestWork %s/%s", cpNsWork.Namespace, cpNsWork.Name)
+
+	operator := rollout.clusters[cluster.Name]
+	switch {
+	case operator.detection.ownership != operatorOwned:
+		// The operator ManifestWork is only created once the cluster reported that no operator Subscription
+		// exists, and never next to a pre-existing one, so that it is not deleted with the ManifestWork.
+		klog.V(4).Infof("Operator on cluster %s is %s, not applying the operator ManifestWork", cluster.Name, operator.detection.ownership)
+		if err := r.ensureOperatorProbe(ctx, mesh, cluster, operator.config); err != nil {
+			return fmt.Errorf("failed to ensure operator probe on cluster %s: %w", cluster.Name, err)
+		}
+	case rollout.held[cluster.Name]:
+		klog.V(4).Infof("Holding back operator revision %s on cluster %s", operator.revision, cluster.Name)
+	default:
+		work, err := r.workApplier.Apply(ctx, r.buildOperatorManifestWork(mesh, cluster, operator))
+		if err != nil {
+			return fmt.Errorf("failed to apply operator ManifestWork on cluster %s: %w", cluster.Name, err)
+		}
+		klog.V(4).Infof("Applied operator ManifestWork %s/%s", work.Namespace, work.Name)
+
+		if err := r.deleteOperatorProbe(ctx, cluster.Name); err != nil {
+			return fmt.Errorf("failed to cleanup operator probe on cluster %s: %w", cluster.Name, err)
+		}
+		if err := r.ensureInstallPlanApproval(ctx, mesh, cluster, operator.config, work); err != nil {
+			return fmt.Errorf("failed to ensure InstallPlan approval on cluster %s: %w", cluster.Name, err)
+		}
 	}
 
+	if err := r.ensureIstiodProbe(ctx, mesh, cluster); err != nil {
+		return fmt.Errorf("failed to ensure istiod probe on cluster %s: %w", cluster.Name, err)
+	}
 	return nil
 }
 
@@ -389,40 +567,64 @@ func (r *Reconciler) findMeshesForClusterSet(ctx context.Context, obj client.Obj
 
 // findMeshesForManifestWork returns a list of all meshes to reconcile following a ManifestWork change
 func (r *Reconciler) findMeshesForManifestWork(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	// Meshes being deleted still watch their own ManifestWorks, such as the deletion guard, even on deleted clusters.
+	if name, namespace := obj.GetLabels()[MeshNameLabel], obj.GetLabels()[MeshNamespaceLabel]; name != "" && namespace != "" {
+		requests = append(requests, reconcile.Request{NamespacedName: key.Of(name, namespace)})
+	}
+
 	cluster := &clusterv1.ManagedCluster{}
 	if err := r.Get(ctx, key.Of(obj.GetNamespace()), cluster); err != nil {
 		if !apierrors.IsNotFound(err) {
 			klog.Errorf("Failed to get ManagedCluster %s for ManifestWork %s: %v", obj.GetNamespace(), obj.GetName(), err)
 		}
-		return nil
+		return requests
 	}
 
-	return r.findMeshesForCluster(ctx, cluster)
+	for _, request := range r.findMeshesForCluster(ctx, cluster) {
+		if !slices.Contains(requests, request) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
 }
 
-// handleDeletion handles cleanup when the MultiClusterMesh is being deleted
-func (r *Reconciler) handleDeletion(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) error {
+// handleDeletion handles cleanup when the MultiClusterMesh is being deleted.
+// The finalizer is only removed once the clusters deleted the mesh-owned resources, or the cleanup timed out.
+func (r *Reconciler) handleDeletion(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) (reconcile.Result, error) {
 	if !controllerutil.ContainsFinalizer(mesh, FinalizerName) {
 		klog.V(4).Infof("MultiClusterMesh %s/%s has no finalizer, nothing to clean up", mesh.Namespace, mesh.Name)
-		return nil
+		return reconcile.Result{}, nil
 	}
 
 	klog.Infof("Handling deletion for MultiClusterMesh %s/%s", mesh.Namespace, mesh.Name)
 
-	if err := r.cleanupMeshOwnedManifestWorks(ctx, mesh, nil); err != nil {
-		return fmt.Errorf("failed to cleanup mesh-owned ManifestWorks: %w", err)
+	if blocked, err := r.guardDeletion(ctx, mesh); err != nil || blocked {
+		return reconcile.Result{}, err
 	}
 
-	if err := r.cleanupManifestWorks(ctx, mesh.Spec.ClusterSet); err != nil {
-		return fmt.Errorf("failed to cleanup ManifestWorks: %w", err)
+	if err := r.cleanupMeshOwnedManifestWorks(ctx, mesh); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to cleanup mesh-owned ManifestWorks: %w", err)
+	}
+
+	if err := r.cleanupRemoteSecretDistributions(ctx, mesh); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to cleanup remote secret distribution: %w", err)
+	}
+
+	if err := r.cleanupManifestWorks(ctx, mesh.Spec.ClusterSet, nil); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to cleanup ManifestWorks: %w", err)
 	}
 
 	if err := r.deleteAllManagedServiceAccounts(ctx, mesh); err != nil {
-		return fmt.Errorf("failed to cleanup ManagedServiceAccount resources: %w", err)
+		return reconcile.Result{}, fmt.Errorf("failed to cleanup ManagedServiceAccount resources: %w", err)
 	}
 
 	if err := r.cleanupManagedClusterSetBinding(ctx, mesh); err != nil {
-		return fmt.Errorf("failed to cleanup ManagedClusterSetBinding: %w", err)
+		return reconcile.Result{}, fmt.Errorf("failed to cleanup ManagedClusterSetBinding: %w", err)
+	}
+
+	if requeueAfter, err := r.awaitCleanup(ctx, mesh); err != nil || requeueAfter > 0 {
+		return reconcile.Result{RequeueAfter: requeueAfter}, err
 	}
 
 	// Trigger reconciliation for other meshes targeting the same cluster set.
@@ -432,14 +634,15 @@ func (r *Reconciler) handleDeletion(ctx context.Context, mesh *meshv1alpha1.Mult
 	klog.Infof("Removing finalizer from MultiClusterMesh %s/%s", mesh.Namespace, mesh.Name)
 	controllerutil.RemoveFinalizer(mesh, FinalizerName)
 	if err := r.Update(ctx, mesh); err != nil {
-		return fmt.Errorf("failed to remove finalizer: %w", err)
+		return reconcile.Result{}, fmt.Errorf("failed to remove finalizer: %w", err)
 	}
 
-	return nil
+	return reconcile.Result{}, nil
 }
 
-// cleanupManifestWorks deletes ManifestWorks on clusters that no mesh in the given ClusterSet needs anymore.
-func (r *Reconciler) cleanupManifestWorks(ctx context.Context, clusterSet string) error {
+// cleanupManifestWorks deletes ManifestWorks on clusters that no mesh in the given ClusterSet needs anymore,
+// except on the held clusters.
+func (r *Reconciler) cleanupManifestWorks(ctx context.Context, clusterSet string, held map[string]bool) error {
 	neededClusters, err := r.getMeshEnabledClusters(ctx, clusterSet)
 	if err != nil {
 		return fmt.Errorf("failed to determine needed clusters: %w", err)
@@ -451,7 +654,7 @@ func (r *Reconciler) cleanupManifestWorks(ctx context.Context, clusterSet string
 	}
 
 	for _, work := range workList.Items {
-		if neededClusters[work.Namespace] {
+		if neededClusters[work.Namespace] || held[work.Namespace] {
 			continue
 		}
 
@@ -484,36 +687,8 @@ func (r *Reconciler) deleteAllCertificates(ctx context.Context, mesh *meshv1alph
 	return nil
 }
 
-// deleteCertificatesForRemovedClusters deletes Certificates for clusters no longer in the ClusterSet.
-func (r *Reconciler) deleteCertificatesForRemovedClusters(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster) error {
-	clusterNames := clusterNameSet(clusters)
-
-	certList := &certmanagerv1.CertificateList{}
-	if err := r.List(ctx, certList,
-		client.InNamespace(mesh.Namespace),
-		client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace},
-	); err != nil {
-		return fmt.Errorf("failed to list Certificates: %w", err)
-	}
-
-	for _, cert := range certList.Items {
-		clusterName := cert.Labels[ClusterNameLabel]
-		if clusterNames[clusterName] {
-			continue
-		}
-
-		klog.Infof("Deleting Certificate %s/%s (cluster %s no longer in ClusterSet %s)", cert.Namespace, cert.Name, clusterName, mesh.Spec.ClusterSet)
-		if err := client.IgnoreNotFound(r.Delete(ctx, &cert)); err != nil {
-			return fmt.Errorf("failed to delete Certificate %s/%s: %w", cert.Namespace, cert.Name, err)
-		}
-	}
-
-	return nil
-}
-
-func (r *Reconciler) cleanupMeshOwnedManifestWorks(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster) error {
-	clusterNames := clusterNameSet(clusters)
-
+// cleanupMeshOwnedManifestWorks deletes all the mesh-owned ManifestWorks.
+func (r *Reconciler) cleanupMeshOwnedManifestWorks(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) error {
 	workList := &workv1.ManifestWorkList{}
 	if err := r.List(ctx, workList,
 		client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace},
@@ -522,7 +697,7 @@ func (r *Reconciler) cleanupMeshOwnedManifestWorks(ctx context.Context, mesh *me
 	}
 
 	for _, work := range workList.Items {
-		if clusterNames[work.Namespace] {
+		if !work.DeletionTimestamp.IsZero() {
 			continue
 		}
 
@@ -556,27 +731,100 @@ func (r *Reconciler) triggerReconcileForNotReadyMeshes(ctx context.Context, mesh
 }
 
 func (r *Reconciler) determineStatus(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster) error {
+	// The operator rollout and quarantine states are recorded while reconciling, carry them over.
+	rollouts := map[string]*meshv1alpha1.ClusterOperatorRolloutStatus{}
+	quarantines := map[string]*meshv1alpha1.ClusterQuarantineStatus{}
+	for _, cs := range mesh.Status.ClusterStatus {
+		rollouts[cs.ClusterName] = cs.OperatorRollout
+		quarantines[cs.ClusterName] = cs.Quarantine
+	}
 	mesh.Status.ClusterStatus = make([]meshv1alpha1.ClusterMeshStatus, 0, len(clusters))
 	allReady := len(clusters) > 0
+	installedVersions := map[string]string{}
 
 	for _, cluster := range clusters {
+		if rollout := rollouts[cluster.Name]; rollout != nil {
+			mesh.SetClusterOperatorRollout(cluster.Name, rollout)
+		}
+		if quarantine := quarantines[cluster.Name]; quarantine != nil {
+			mesh.SetClusterQuarantine(cluster.Name, quarantine)
+		}
+		config, profile := clusterOperatorConfig(mesh, &cluster)
+		mesh.SetClusterOperatorProfile(cluster.Name, profile)
+
+		detection, err := r.detectExistingOperator(ctx, cluster.Name, config)
+		if err != nil {
+			return err
+		}
+
+		type workCondition struct{ workName, conditionType string }
+		workConditions := []workCondition{
+			{ManifestWorkNameCPNSPrefix + mesh.GetControlPlaneNamespace(), meshv1alpha1.ConditionControlPlaneNamespaceApplied},
+		}
+		if detection.ownership == operatorOwned {
+			workConditions = append(workConditions, workCondition{OperatorManifestWorkName, meshv1alpha1.ConditionOperatorApplied})
+		}
+		if mesh.Spec.Security.Trust.CertManager.IssuerRef.Name != "" && !quarantined(&cluster) {
+			workConditions = append(workConditions, workCondition{ManifestWorkNameCacerts, meshv1alpha1.ConditionCacertsApplied})
+		}
+		for _, wc := range workConditions {
+			applied, err := r.determineManifestWorkCondition(ctx, mesh, cluster.Name, wc.workName, wc.conditionType)
+			if err != nil {
+				return err
+			}
+			allReady = allReady && applied
+		}
+
+		if detection.ownership != operatorOwned {
+			status, reason, message := existingOperatorState(detection)
+			mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionOperatorApplied, status, reason, "%s", message)
+			if detection.ownership == operatorAdopted {
+				// The hub does not approve InstallPlans of an adopted Subscription, every pending one awaits the user.
+				status, reason, message = operatorInstallState(detection.feedback, "")
+				if status == metav1.ConditionTrue {
+					installedVersions[cluster.Name] = csvVersion(detection.feedback[FeedbackInstalledCSV])
+				}
+			}
+			allReady = allReady && status == metav1.ConditionTrue
+			mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionOperatorInstalled, status, reason, "%s", message)
+			continue
+		}
 
+		// The operator ManifestWork may not exist yet if the cluster just reported that no Subscription exists.
 		operatorWork := &workv1.ManifestWork{}
-		if err := r.Get(ctx, key.Of(OperatorManifestWorkName, cluster.Name), operatorWork); err != nil {
+		if err := r.Get(ctx, key.Of(OperatorManifestWorkName, cluster.Name), operatorWork); err != nil && !apierrors.IsNotFound(err) {
 			return fmt.Errorf("failed to get operator ManifestWork for cluster %s: %w", cluster.Name, err)
 		}
 
-		if installedCSV := getManifestWorkFeedback(operatorWork); installedCSV != nil {
-			mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionOperatorInstalled, metav1.ConditionTrue,
-				meshv1alpha1.ReasonOperatorInstalled, "Operator installed: %s", *installedCSV)
-		} else {
-			allReady = false
-			mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionOperatorInstalled, metav1.ConditionFalse,
-				meshv1alpha1.ReasonInstallationPending, "Operator installation is pending")
+		feedback := getManifestWorkFeedback(operatorWork)
+		status, reason, message := operatorInstallStatus(config, feedback)
+		allReady = allReady && status == metav1.ConditionTrue
+		mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionOperatorInstalled, status, reason, "%s", message)
+		if status == metav1.ConditionTrue {
+			installedVersions[cluster.Name] = installedOperatorVersion(config, feedback)
+		}
+
+		if config.Catalog != nil {
+			catalogStatus, catalogReason, catalogMessage := catalogSourceState(feedback, config)
+			mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionCatalogSourceReady, catalogStatus, catalogReason, "%s", catalogMessage)
 		}
 	}
 
-	if allReady {
+	determineVersionConsistency(mesh, installedVersions)
+
+	// Quarantined clusters take no part in endpoint discovery.
+	discoveryReady, err := r.determineDiscoveryStatus(ctx, mesh, slices.DeleteFunc(slices.Clone(clusters), func(cluster clusterv1.ManagedCluster) bool {
+		return quarantined(&cluster)
+	}))
+	if err != nil {
+		return err
+	}
+	allReady = allReady && discoveryReady
+
+	if degraded := degradedClusters(mesh); len(degraded) > 0 {
+		mesh.SetReadyCondition(metav1.ConditionFalse,
+			meshv1alpha1.ReasonClustersDegraded, "ManifestWorks are failing on clusters %s, check individual cluster statuses for details", strings.Join(degraded, ", "))
+	} else if allReady {
 		mesh.SetReadyCondition(metav1.ConditionTrue,
 			meshv1alpha1.ReasonAllClustersReady, "All clusters are ready")
 	} else {
@@ -587,24 +835,83 @@ func (r *Reconciler) determineStatus(ctx context.Context, mesh *meshv1alpha1.Mul
 	return nil
 }
 
-func getManifestWorkFeedback(work *workv1.ManifestWork) *string {
-	for _, manifest := range work.Status.ResourceStatus.Manifests {
-		for _, value := range manifest.StatusFeedbacks.Values {
-			if value.Name == FeedbackInstalledCSV {
-				return value.Value.String
-			}
+// determineManifestWorkCondition sets a per-cluster condition from the state the work agent reports for a ManifestWork.
+// It returns true if the ManifestWork is applied and its resources are available.
+func (r *Reconciler) determineManifestWorkCondition(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName, workName, conditionType string) (bool, error) {
+	work := &workv1.ManifestWork{}
+	if err := r.Get(ctx, key.Of(workName, clusterName), work); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get ManifestWork %s/%s: %w", clusterName, workName, err)
 		}
+		mesh.SetClusterCondition(clusterName, conditionType, metav1.ConditionFalse,
+			meshv1alpha1.ReasonApplyPending, "ManifestWork %s has not been created yet", workName)
+		return false, nil
 	}
-	return nil
+
+	reason, message := manifestWorkState(work)
+	status := metav1.ConditionFalse
+	if reason == meshv1alpha1.ReasonApplied {
+		status = metav1.ConditionTrue
+	}
+	mesh.SetClusterCondition(clusterName, conditionType, status, reason, "ManifestWork %s: %s", workName, message)
+	return status == metav1.ConditionTrue, nil
 }
 
-// getMeshEnabledClusters returns all clusters in the given ClusterSet if any non-deleting mesh targets it, or an empty set otherwise.
+// degradedClusters returns the clusters with a condition reporting a failed or degraded ManifestWork.
+func degradedClusters(mesh *meshv1alpha1.MultiClusterMesh) []string {
+	var degraded []string
+	for _, cs := range mesh.Status.ClusterStatus {
+		if slices.ContainsFunc(cs.Conditions, func(c metav1.Condition) bool {
+			return c.Reason == meshv1alpha1.ReasonApplyFailed || c.Reason == meshv1alpha1.ReasonDegraded
+		}) {
+			degraded = append(degraded, cs.ClusterName)
+		}
+	}
+	return degraded
+}
+
+// manifestWorkState summarizes the Applied, Available and Degraded conditions reported by the work agent.
+// It returns one of the Applied, ApplyPending, ApplyFailed or Degraded reasons with the agent's message.
+// Conditions observed for an older generation describe the previous spec of the work, and are treated as not reported yet.
+func manifestWorkState(work *workv1.ManifestWork) (reason, message string) {
+	observed := func(conditionType string) *metav1.Condition {
+		condition := meta.FindStatusCondition(work.Status.Conditions, conditionType)
+		if condition == nil || condition.ObservedGeneration < work.Generation {
+			return nil
+		}
+		return condition
+	}
+	applied := observed(workv1.WorkApplied)
+	available := observed(workv1.WorkAvailable)
+	degraded := observed(workv1.WorkDegraded)
+
+	switch {
+	case applied != nil && applied.Status == metav1.ConditionFalse:
+		return meshv1alpha1.ReasonApplyFailed, applied.Message
+	case degraded != nil && degraded.Status == metav1.ConditionTrue:
+		return meshv1alpha1.ReasonDegraded, degraded.Message
+	case applied == nil || applied.Status != metav1.ConditionTrue:
+		return meshv1alpha1.ReasonApplyPending, "Waiting for the work agent to apply the manifests"
+	case available == nil || available.Status != metav1.ConditionTrue:
+		if available != nil {
+			return meshv1alpha1.ReasonApplyPending, available.Message
+		}
+		return meshv1alpha1.ReasonApplyPending, "Waiting for the applied resources to become available"
+	}
+	return meshv1alpha1.ReasonApplied, applied.Message
+}
+
+// getMeshEnabledClusters returns all clusters in the given ClusterSet if any non-deleting mesh targets it, or an empty set otherwise,
+// together with the clusters the meshes keep during their removal grace period.
 func (r *Reconciler) getMeshEnabledClusters(ctx context.Context, clusterSet string) (map[string]bool, error) {
 	needed := make(map[string]bool)
 
 	hasActiveMesh := false
-	if err := r.forEachMeshInClusterSet(ctx, clusterSet, func(_ *meshv1alpha1.MultiClusterMesh) {
+	if err := r.forEachMeshInClusterSet(ctx, clusterSet, func(mesh *meshv1alpha1.MultiClusterMesh) {
 		hasActiveMesh = true
+		for _, name := range departingClusters(mesh) {
+			needed[name] = true
+		}
 	}); err != nil {
 		return nil, err
 	}
@@ -618,7 +925,10 @@ func (r *Reconciler) getMeshEnabledClusters(ctx context.Context, clusterSet stri
 		return nil, fmt.Errorf("failed to get clusters from set %s: %w", clusterSet, err)
 	}
 
-	return clusterNameSet(clusters), nil
+	for _, c := range clusters {
+		needed[c.Name] = true
+	}
+	return needed, nil
 }
 
 func clusterNameSet(clusters []clusterv1.ManagedCluster) map[string]bool {
@@ -663,28 +973,34 @@ func (r *Reconciler) getClustersFromSet(ctx context.Context, clusterSetName stri
 	return clusterList.Items, nil
 }
 
-func (r *Reconciler) buildOperatorManifestWork(mesh *meshv1alpha1.MultiClusterMesh, cluster *clusterv1.ManagedCluster) *workv1.ManifestWork {
-	config := mesh.Spec.Operator
-	manifests := []workv1.Manifest{
-		{
-			RawExtension: runtime.RawExtension{Object: &rbacv1.ClusterRole{
-				TypeMeta: metav1.TypeMeta{
-					APIVersion: "rbac.authorization.k8s.io/v1",
-					Kind:       "ClusterRole",
-				},
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "klusterlet-work-olm-ossm",
-					Labels: map[string]string{
-						"open-cluster-management.io/aggregate-to-work": "true",
-					},
-				},
-				Rules: []rbacv1.PolicyRule{{
-					APIGroups: []string{"operators.coreos.com"},
-					Resources: []string{"operatorgroups", "subscriptions", "catalogsources", "clusterserviceversions"},
-					Verbs:     []string{"create", "get", "list", "update", "patch", "delete"},
-				}},
-			}},
+func (r *Reconciler) buildOperatorManifestWork(mesh *meshv1alpha1.MultiClusterMesh, cluster *clusterv1.ManagedCluster, operator *clusterOperator) *workv1.ManifestWork {
+	return &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      OperatorManifestWorkName,
+			Namespace: cluster.Name,
+			Labels: map[string]string{
+				ManagedByLabel:  ManagedByValue,
+				ClusterSetLabel: mesh.Spec.ClusterSet,
+			},
+			Annotations: map[string]string{
+				AnnotationOperatorRevision: operator.revision,
+			},
+		},
+		Spec: workv1.ManifestWorkSpec{
+			Workload: workv1.ManifestsTemplate{
+				Manifests: operator.manifests,
+			},
+			ManifestConfigs: operatorManifestConfigs(operator.config),
+			DeleteOption:    deleteOption(deletionPolicy(mesh, mesh.Spec.DeletionPolicy.Operator)),
 		},
+	}
+}
+
+// buildOLMOperatorManifests builds the OLM objects installing the operator through a Subscription,
+// and the CatalogSource of the Subscription if the mesh defines its catalog.
+func buildOLMOperatorManifests(config meshv1alpha1.OperatorConfig) []workv1.Manifest {
+	manifests := []workv1.Manifest{
+		buildWorkAgentOLMClusterRole(),
 		{
 			RawExtension: runtime.RawExtension{Object: &corev1.Namespace{
 				TypeMeta: metav1.TypeMeta{
@@ -728,43 +1044,52 @@ func (r *Reconciler) buildOperatorManifestWork(mesh *meshv1alpha1.MultiClusterMe
 					CatalogSource:          config.Source,
 					CatalogSourceNamespace: config.SourceNamespace,
 					StartingCSV:            config.StartingCSV,
+					Config:                 subscriptionConfig(config.Config),
 				},
 			}},
 		},
 	}
+	if config.Catalog != nil {
+		manifests = append(manifests, workv1.Manifest{RawExtension: runtime.RawExtension{Object: buildCatalogSource(config)}})
+	}
+	return manifests
+}
 
-	return &workv1.ManifestWork{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      OperatorManifestWorkName,
-			Namespace: cluster.Name,
-			Labels: map[string]string{
-				ManagedByLabel:  ManagedByValue,
-				ClusterSetLabel: mesh.Spec.ClusterSet,
+// buildWorkAgentOLMClusterRole builds the ClusterRole aggregated to the work agent, allowing it to manage OLM objects.
+func buildWorkAgentOLMClusterRole() workv1.Manifest {
+	return workv1.Manifest{
+		RawExtension: runtime.RawExtension{Object: &rbacv1.ClusterRole{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "rbac.authorization.k8s.io/v1",
+				Kind:       "ClusterRole",
 			},
-		},
-		Spec: workv1.ManifestWorkSpec{
-			Workload: workv1.ManifestsTemplate{
-				Manifests: manifests,
-			},
-			// Report the Subscription's installedCSV field back to the hub via ManifestWork feedback.
-			// OLM sets this field only after the operator's CSV reaches the Succeeded phase,
-			// so a non-empty value confirms the operator is installed.
-			ManifestConfigs: []workv1.ManifestConfigOption{{
-				ResourceIdentifier: workv1.ResourceIdentifier{
-					Group:     "operators.coreos.com",
-					Resource:  "subscriptions",
-					Name:      config.Name,
-					Namespace: config.Namespace,
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "klusterlet-work-olm-ossm",
+				Labels: map[string]string{
+					"open-cluster-management.io/aggregate-to-work": "true",
 				},
-				FeedbackRules: []workv1.FeedbackRule{{
-					Type: workv1.JSONPathsType,
-					JsonPaths: []workv1.JsonPath{{
-						Name: FeedbackInstalledCSV,
-						Path: ".status.installedCSV",
-					}},
-				}},
+			},
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups: []string{"operators.coreos.com"},
+				Resources: []string{"operatorgroups", "subscriptions", "catalogsources", "clusterserviceversions", "installplans"},
+				Verbs:     []string{"create", "get", "list", "update", "patch", "delete"},
 			}},
-		},
+		}},
+	}
+}
+
+// subscriptionConfig maps the operator Deployment settings of the mesh onto the OLM Subscription config.
+func subscriptionConfig(config *meshv1alpha1.SubscriptionConfig) *operatorsv1alpha1.SubscriptionConfig {
+	if config == nil {
+		return nil
+	}
+	return &operatorsv1alpha1.SubscriptionConfig{
+		NodeSelector: config.NodeSelector,
+		Tolerations:  config.Tolerations,
+		Resources:    config.Resources,
+		Env:          config.Env,
+		Volumes:      config.Volumes,
+		VolumeMounts: config.VolumeMounts,
 	}
 }
 
@@ -832,6 +1157,7 @@ func (r *Reconciler) ensureCertificateForCluster(ctx context.Context, mesh *mesh
 				certmanagerv1.UsageDigitalSignature,
 				certmanagerv1.UsageKeyEncipherment,
 				certmanagerv1.UsageCertSign,
+				certmanagerv1.UsageCRLSign,
 			).
 			WithIssuerRef(cmmetaapply.IssuerReference().
 				WithName(mesh.Spec.Security.Trust.CertManager.IssuerRef.Name).
@@ -846,8 +1172,9 @@ func (r *Reconciler) ensureCertificateForCluster(ctx context.Context, mesh *mesh
 	return nil
 }
 
-// ensureCacertsManifestWork creates a ManifestWork to distribute the cacerts secret to a cluster
-func (r *Reconciler) ensureCacertsManifestWork(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, cluster *clusterv1.ManagedCluster) error {
+// ensureCacertsManifestWork creates a ManifestWork to distribute the cacerts secret to a cluster, along with the
+// certificate revocation list. A list that cannot be signed leaves the one the cluster has unchanged.
+func (r *Reconciler) ensureCacertsManifestWork(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, cluster *clusterv1.ManagedCluster, revocation *revocationList) error {
 	secretName := getCacertsName(cluster.Name)
 	secret := &corev1.Secret{}
 	err := r.Get(ctx, key.Of(secretName, mesh.Namespace), secret)
@@ -860,6 +1187,21 @@ func (r *Reconciler) ensureCacertsManifestWork(ctx context.Context, mesh *meshv1
 		return fmt.Errorf("failed to get secret: %w", err)
 	}
 
+	crl := revocation.pem
+	if revocation.problem != "" {
+		work := &workv1.ManifestWork{}
+		if err := r.Get(ctx, key.Of(ManifestWorkNameCacerts, cluster.Name), work); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get cacerts ManifestWork: %w", err)
+		} else if err == nil {
+			current, err := cacertsSecretFromManifestWork(work)
+			if err != nil {
+				return err
+			}
+			crl = current.Data[CacertsCRLKey]
+		}
+	}
+	secret.Data = withRevocationList(secret.Data, crl)
+
 	work, err := r.workApplier.Apply(ctx, r.buildCacertsManifestWork(mesh, cluster.Name, secret))
 	if err != nil {
 		return fmt.Errorf("failed to apply cacerts ManifestWork on cluster %s: %w", cluster.Name, err)
@@ -877,7 +1219,8 @@ func (r *Reconciler) buildControlPlaneNamespaceManifestWork(mesh *meshv1alpha1.M
 		network = v
 	}
 
-	return buildMeshOwnedManifestWork(mesh, cluster.Name, ManifestWorkNameCPNSPrefix+cpNamespace, &corev1.Namespace{
+	policy := deletionPolicy(mesh, mesh.Spec.DeletionPolicy.ControlPlaneNamespace)
+	return buildMeshOwnedManifestWork(mesh, cluster.Name, ManifestWorkNameCPNSPrefix+cpNamespace, policy, &corev1.Namespace{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
 			Kind:       "Namespace",
@@ -906,10 +1249,11 @@ func (r *Reconciler) buildCacertsManifestWork(mesh *meshv1alpha1.MultiClusterMes
 		Data: secret.Data,
 	}
 
-	return buildMeshOwnedManifestWork(mesh, clusterName, ManifestWorkNameCacerts, cacertsSecret)
+	policy := deletionPolicy(mesh, mesh.Spec.DeletionPolicy.Cacerts)
+	return buildMeshOwnedManifestWork(mesh, clusterName, ManifestWorkNameCacerts, policy, cacertsSecret)
 }
 
-func buildMeshOwnedManifestWork(mesh *meshv1alpha1.MultiClusterMesh, clusterName, name string, obj runtime.Object) *workv1.ManifestWork {
+func buildMeshOwnedManifestWork(mesh *meshv1alpha1.MultiClusterMesh, clusterName, name string, policy meshv1alpha1.DeletionPolicyType, obj runtime.Object) *workv1.ManifestWork {
 	return &workv1.ManifestWork{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -922,6 +1266,7 @@ func buildMeshOwnedManifestWork(mesh *meshv1alpha1.MultiClusterMesh, clusterName
 					RawExtension: runtime.RawExtension{Object: obj},
 				}},
 			},
+			DeleteOption: deleteOption(policy),
 		},
 	}
 }
@@ -994,24 +1339,3 @@ func (r *Reconciler) cleanupManagedClusterSetBinding(ctx context.Context, mesh *
 	klog.Infof("Deleting ManagedClusterSetBinding %s/%s", mesh.Namespace, mesh.Spec.ClusterSet)
 	return client.IgnoreNotFound(r.Delete(ctx, binding))
 }
-
-// ensurePlacement creates a Placement referencing the mesh's ClusterSet
-func (r *Reconciler) ensurePlacement(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) error {
-	placement := &clusterv1beta1.Placement{
-		ObjectMeta: metav1.ObjectMeta{Name: mesh.Name, Namespace: mesh.Namespace},
-	}
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, placement, func() error {
-		if placement.Labels == nil {
-			placement.Labels = make(map[string]string)
-		}
-		placement.Labels[ManagedByLabel] = ManagedByValue
-		placement.Labels[MeshNameLabel] = mesh.Name
-		placement.Labels[MeshNamespaceLabel] = mesh.Namespace
-		placement.Spec.ClusterSets = []string{mesh.Spec.ClusterSet}
-		return controllerutil.SetControllerReference(mesh, placement, r.Scheme)
-	})
-	if err != nil {
-		return fmt.Errorf("failed to ensure placement %s: %w", placement.Name, err)
-	}
-	return nil
-}
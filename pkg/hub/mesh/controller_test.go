@@ -5,10 +5,14 @@ import (
 	"testing"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
@@ -110,6 +114,265 @@ func TestIsOlderMesh(t *testing.T) {
 	}
 }
 
+func TestValidateRolloutConflict(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clusterv1.Install(scheme)
+	_ = clusterv1beta2.Install(scheme)
+	_ = meshv1alpha1.Install(scheme)
+
+	now := metav1.Now()
+	newMesh := func(name, cpNamespace string, created metav1.Time, rollout *meshv1alpha1.RolloutStrategy) *meshv1alpha1.MultiClusterMesh {
+		mesh := meshWith("ns", name, created)
+		mesh.UID = types.UID(name)
+		mesh.Spec.ClusterSet = "test-set"
+		mesh.Spec.ControlPlane.Namespace = cpNamespace
+		mesh.Spec.Rollout = rollout
+		return mesh
+	}
+	canaries := &meshv1alpha1.RolloutStrategy{CanaryClusters: []string{"cluster-a"}, SoakTime: metav1.Duration{Duration: time.Minute}}
+
+	tests := []struct {
+		name     string
+		rollout  *meshv1alpha1.RolloutStrategy
+		expected bool
+	}{
+		{name: "same rollout strategy", rollout: canaries.DeepCopy()},
+		{name: "no rollout strategy", expected: true},
+		{name: "different rollout strategy", rollout: &meshv1alpha1.RolloutStrategy{CanaryClusters: []string{"cluster-b"}}, expected: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			older := newMesh("older", "istio-older", now, canaries)
+			newer := newMesh("newer", "istio-newer", metav1.NewTime(now.Add(time.Second)), tc.rollout)
+			r := &Reconciler{Client: fake.NewClientBuilder().WithScheme(scheme).
+				WithIndex(&meshv1alpha1.MultiClusterMesh{}, "spec.clusterSet", func(obj client.Object) []string {
+					return []string{obj.(*meshv1alpha1.MultiClusterMesh).Spec.ClusterSet}
+				}).
+				WithObjects(older, newer).Build()}
+
+			conflict, err := r.validate(context.Background(), newer)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if conflict != tc.expected {
+				t.Errorf("validate() conflict = %v, want %v", conflict, tc.expected)
+			}
+			if c := meta.FindStatusCondition(newer.Status.Conditions, meshv1alpha1.ConditionReady); tc.expected &&
+				(c == nil || c.Reason != meshv1alpha1.ReasonOperatorConfigConflict) {
+				t.Errorf("Ready condition = %v, want reason %s", c, meshv1alpha1.ReasonOperatorConfigConflict)
+			}
+
+			// The older mesh keeps reconciling its rollout.
+			if conflict, err := r.validate(context.Background(), older); err != nil || conflict {
+				t.Errorf("validate() of the older mesh = %v, %v, want no conflict", conflict, err)
+			}
+		})
+	}
+}
+
+func TestValidateOperatorConfigs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clusterv1.Install(scheme)
+	_ = clusterv1beta2.Install(scheme)
+	_ = meshv1alpha1.Install(scheme)
+
+	now := metav1.Now()
+	newMesh := func(name, cpNamespace string, created metav1.Time, operator string, profiles ...meshv1alpha1.OperatorProfile) *meshv1alpha1.MultiClusterMesh {
+		mesh := meshWith("ns", name, created)
+		mesh.UID = types.UID(name)
+		mesh.Spec.ClusterSet = "test-set"
+		mesh.Spec.ControlPlane.Namespace = cpNamespace
+		mesh.Spec.Operator.Name = operator
+		mesh.Spec.OperatorProfiles = profiles
+		return mesh
+	}
+	cluster := func(name, product string) *clusterv1.ManagedCluster {
+		return &clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{ClusterSetLabel: "test-set"}},
+			Status:     clusterv1.ManagedClusterStatus{ClusterClaims: []clusterv1.ManagedClusterClaim{{Name: ClusterClaimProduct, Value: product}}},
+		}
+	}
+	eksProfile := func(operator, minKubeVersion string) meshv1alpha1.OperatorProfile {
+		return meshv1alpha1.OperatorProfile{
+			Name:     "eks",
+			Selector: meshv1alpha1.PlatformSelector{Products: []string{"EKS"}, MinKubeVersion: minKubeVersion},
+			Operator: meshv1alpha1.OperatorConfig{Name: operator},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		clusters       []*clusterv1.ManagedCluster
+		profiles       []meshv1alpha1.OperatorProfile
+		operator       string
+		expectedReason string
+	}{
+		{
+			name:     "same operator",
+			clusters: []*clusterv1.ManagedCluster{cluster("openshift", "OpenShift")},
+			operator: "sailoperator",
+		},
+		{
+			name:           "different operator",
+			clusters:       []*clusterv1.ManagedCluster{cluster("openshift", "OpenShift")},
+			operator:       "servicemeshoperator3",
+			expectedReason: meshv1alpha1.ReasonOperatorConfigConflict,
+		},
+		{
+			name:     "profile matching no cluster",
+			clusters: []*clusterv1.ManagedCluster{cluster("openshift", "OpenShift")},
+			profiles: []meshv1alpha1.OperatorProfile{eksProfile("sailoperator-eks", "")},
+			operator: "sailoperator",
+		},
+		{
+			name:           "profile installing a different operator on a cluster",
+			clusters:       []*clusterv1.ManagedCluster{cluster("openshift", "OpenShift"), cluster("eks", "EKS")},
+			profiles:       []meshv1alpha1.OperatorProfile{eksProfile("sailoperator-eks", "")},
+			operator:       "sailoperator",
+			expectedReason: meshv1alpha1.ReasonOperatorConfigConflict,
+		},
+		{
+			name:           "profile with an invalid minimum Kubernetes version",
+			clusters:       []*clusterv1.ManagedCluster{cluster("openshift", "OpenShift")},
+			profiles:       []meshv1alpha1.OperatorProfile{eksProfile("sailoperator", "01.30")},
+			operator:       "sailoperator",
+			expectedReason: meshv1alpha1.ReasonInvalidOperatorProfile,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			older := newMesh("older", "istio-older", now, "sailoperator")
+			newer := newMesh("newer", "istio-newer", metav1.NewTime(now.Add(time.Second)), tc.operator, tc.profiles...)
+			objects := []client.Object{&clusterv1beta2.ManagedClusterSet{ObjectMeta: metav1.ObjectMeta{Name: "test-set"}}, older, newer}
+			for _, c := range tc.clusters {
+				objects = append(objects, c)
+			}
+			r := &Reconciler{Client: fake.NewClientBuilder().WithScheme(scheme).
+				WithIndex(&meshv1alpha1.MultiClusterMesh{}, "spec.clusterSet", func(obj client.Object) []string {
+					return []string{obj.(*meshv1alpha1.MultiClusterMesh).Spec.ClusterSet}
+				}).
+				WithObjects(objects...).Build()}
+
+			conflict, err := r.validate(context.Background(), newer)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if conflict != (tc.expectedReason != "") {
+				t.Errorf("validate() conflict = %v, want %v", conflict, tc.expectedReason != "")
+			}
+			if c := meta.FindStatusCondition(newer.Status.Conditions, meshv1alpha1.ConditionReady); tc.expectedReason != "" &&
+				(c == nil || c.Reason != tc.expectedReason) {
+				t.Errorf("Ready condition = %v, want reason %s", c, tc.expectedReason)
+			}
+		})
+	}
+}
+
+func TestManifestWorkState(t *testing.T) {
+	condition := func(conditionType string, status metav1.ConditionStatus, observedGeneration int64, message string) metav1.Condition {
+		return metav1.Condition{Type: conditionType, Status: status, ObservedGeneration: observedGeneration, Message: message}
+	}
+
+	tests := []struct {
+		name            string
+		conditions      []metav1.Condition
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "no conditions",
+			expectedReason: meshv1alpha1.ReasonApplyPending,
+		},
+		{
+			name: "applied and available",
+			conditions: []metav1.Condition{
+				condition(workv1.WorkApplied, metav1.ConditionTrue, 2, "Apply manifest work complete"),
+				condition(workv1.WorkAvailable, metav1.ConditionTrue, 2, "All resources are available"),
+			},
+			expectedReason:  meshv1alpha1.ReasonApplied,
+			expectedMessage: "Apply manifest work complete",
+		},
+		{
+			name: "applied for an older generation",
+			conditions: []metav1.Condition{
+				condition(workv1.WorkApplied, metav1.ConditionTrue, 1, ""),
+				condition(workv1.WorkAvailable, metav1.ConditionTrue, 1, ""),
+			},
+			expectedReason: meshv1alpha1.ReasonApplyPending,
+		},
+		{
+			name: "applied but not available",
+			conditions: []metav1.Condition{
+				condition(workv1.WorkApplied, metav1.ConditionTrue, 2, ""),
+				condition(workv1.WorkAvailable, metav1.ConditionFalse, 2, "1 of 2 resources are available"),
+			},
+			expectedReason:  meshv1alpha1.ReasonApplyPending,
+			expectedMessage: "1 of 2 resources are available",
+		},
+		{
+			name: "failed to apply",
+			conditions: []metav1.Condition{
+				condition(workv1.WorkApplied, metav1.ConditionFalse, 2, "secrets is forbidden"),
+			},
+			expectedReason:  meshv1alpha1.ReasonApplyFailed,
+			expectedMessage: "secrets is forbidden",
+		},
+		{
+			name: "stale apply failure",
+			conditions: []metav1.Condition{
+				condition(workv1.WorkApplied, metav1.ConditionFalse, 1, "secrets is forbidden"),
+			},
+			expectedReason: meshv1alpha1.ReasonApplyPending,
+		},
+		{
+			name: "degraded",
+			conditions: []metav1.Condition{
+				condition(workv1.WorkApplied, metav1.ConditionTrue, 2, ""),
+				condition(workv1.WorkAvailable, metav1.ConditionTrue, 2, ""),
+				condition(workv1.WorkDegraded, metav1.ConditionTrue, 2, "deployment has no ready replicas"),
+			},
+			expectedReason:  meshv1alpha1.ReasonDegraded,
+			expectedMessage: "deployment has no ready replicas",
+		},
+		{
+			name: "stale degradation",
+			conditions: []metav1.Condition{
+				condition(workv1.WorkApplied, metav1.ConditionTrue, 1, ""),
+				condition(workv1.WorkAvailable, metav1.ConditionTrue, 1, ""),
+				condition(workv1.WorkDegraded, metav1.ConditionTrue, 1, "deployment has no ready replicas"),
+			},
+			expectedReason: meshv1alpha1.ReasonApplyPending,
+		},
+		{
+			name: "available for an older generation",
+			conditions: []metav1.Condition{
+				condition(workv1.WorkApplied, metav1.ConditionTrue, 2, ""),
+				condition(workv1.WorkAvailable, metav1.ConditionTrue, 1, ""),
+			},
+			expectedReason:  meshv1alpha1.ReasonApplyPending,
+			expectedMessage: "Waiting for the applied resources to become available",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			work := &workv1.ManifestWork{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status:     workv1.ManifestWorkStatus{Conditions: tc.conditions},
+			}
+			reason, message := manifestWorkState(work)
+			if reason != tc.expectedReason {
+				t.Errorf("manifestWorkState() reason = %q, want %q", reason, tc.expectedReason)
+			}
+			if tc.expectedMessage != "" && message != tc.expectedMessage {
+				t.Errorf("manifestWorkState() message = %q, want %q", message, tc.expectedMessage)
+			}
+		})
+	}
+}
+
 func meshWith(namespace, name string, ts metav1.Time) *meshv1alpha1.MultiClusterMesh {
 	return &meshv1alpha1.MultiClusterMesh{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,432 @@
+package mesh
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+	msav1beta1 "open-cluster-management.io/managed-serviceaccount/apis/authentication/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+// AnnotationConfirmDeletion confirms the deletion of a mesh whose clusters still run Istio control planes.
+const AnnotationConfirmDeletion = "mesh.open-cluster-management.io/confirm-deletion"
+
+// ManifestWorkNameDeletionGuardPrefix prefixes the name of the read-only ManifestWork reporting the Istio control plane
+// in a control plane namespace while the mesh is being deleted.
+const ManifestWorkNameDeletionGuardPrefix = "multicluster-mesh-deletion-guard-"
+
+// Names of the Istio control plane resources checked before deleting a control plane namespace.
+const (
+	IstioResourceName    = "default"
+	IstiodDeploymentName = "istiod"
+)
+
+// Names of the Istio resource fields reported back to the hub by the deletion guard ManifestWork.
+const (
+	FeedbackIstioNamespace = "istioNamespace"
+	FeedbackIstioState     = "istioState"
+	FeedbackRevisionsInUse = "revisionsInUse"
+)
+
+// deletionPolicy returns the deletion policy of a resource type of the mesh, falling back to the default policy of the mesh.
+func deletionPolicy(mesh *meshv1alpha1.MultiClusterMesh, policy meshv1alpha1.DeletionPolicyType) meshv1alpha1.DeletionPolicyType {
+	if policy != "" {
+		return policy
+	}
+	if mesh.Spec.DeletionPolicy.Default != "" {
+		return mesh.Spec.DeletionPolicy.Default
+	}
+	return meshv1alpha1.DeletionPolicyDelete
+}
+
+// deleteOption returns the DeleteOption of a ManifestWork delivering resources with the given deletion policy.
+// The work agent deletes the resources along with the ManifestWork by default, and leaves them in place when orphaned.
+func deleteOption(policy meshv1alpha1.DeletionPolicyType) *workv1.DeleteOption {
+	if policy != meshv1alpha1.DeletionPolicyOrphan {
+		return nil
+	}
+	return &workv1.DeleteOption{PropagationPolicy: workv1.DeletePropagationPolicyTypeOrphan}
+}
+
+// deletionGuarded reports whether deleting the mesh must first check that its clusters no longer run Istio control
+// planes. Nothing is checked once the user confirmed the deletion, or when the mesh orphans both the control plane
+// namespace and the operator, since neither is then removed from the clusters.
+func deletionGuarded(mesh *meshv1alpha1.MultiClusterMesh) bool {
+	if mesh.Annotations[AnnotationConfirmDeletion] == "true" {
+		clearDeletionBlocked(mesh)
+		return false
+	}
+	return deletionPolicy(mesh, mesh.Spec.DeletionPolicy.ControlPlaneNamespace) == meshv1alpha1.DeletionPolicyDelete ||
+		deletionPolicy(mesh, mesh.Spec.DeletionPolicy.Operator) == meshv1alpha1.DeletionPolicyDelete
+}
+
+// guardDeletion checks, before the cleanup of a deleted mesh, whether its clusters still run an Istio control plane in
+// the control plane namespace. Each cluster with a control plane namespace ManifestWork reports it through a deletion
+// guard ManifestWork. The deletion is blocked, and the DeletionBlocked condition set, until every cluster reports no
+// control plane or the user confirms the deletion with the confirm-deletion annotation.
+func (r *Reconciler) guardDeletion(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) (blocked bool, err error) {
+	if !deletionGuarded(mesh) {
+		return false, nil
+	}
+
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}); err != nil {
+		return false, fmt.Errorf("failed to list mesh-owned ManifestWorks: %w", err)
+	}
+
+	oldStatus := mesh.Status.DeepCopy()
+	var pending, running []string
+	for _, work := range workList.Items {
+		cpNamespace, ok := strings.CutPrefix(work.Name, ManifestWorkNameCPNSPrefix)
+		if !ok || !work.DeletionTimestamp.IsZero() {
+			continue
+		}
+		guard, err := r.workApplier.Apply(ctx, buildDeletionGuardManifestWork(mesh, work.Namespace, cpNamespace))
+		if err != nil {
+			return false, fmt.Errorf("failed to apply deletion guard ManifestWork for cluster %s: %w", work.Namespace, err)
+		}
+
+		reported, findings := deletionGuardFindings(guard, cpNamespace)
+		switch {
+		case !reported:
+			pending = append(pending, work.Namespace)
+			mesh.SetClusterCondition(work.Namespace, meshv1alpha1.ConditionDeletionBlocked, metav1.ConditionTrue, meshv1alpha1.ReasonSafetyCheckPending,
+				"Waiting for the cluster to report the Istio control plane in namespace %s", cpNamespace)
+		case len(findings) > 0:
+			running = append(running, fmt.Sprintf("%s (%s)", work.Namespace, strings.Join(findings, ", ")))
+			mesh.SetClusterCondition(work.Namespace, meshv1alpha1.ConditionDeletionBlocked, metav1.ConditionTrue, meshv1alpha1.ReasonControlPlanesRunning,
+				"Cluster still runs %s", strings.Join(findings, ", "))
+		default:
+			mesh.SetClusterCondition(work.Namespace, meshv1alpha1.ConditionDeletionBlocked, metav1.ConditionFalse, meshv1alpha1.ReasonNoControlPlanes,
+				"No Istio control plane found in namespace %s", cpNamespace)
+		}
+	}
+	if len(pending) == 0 && len(running) == 0 {
+		clearDeletionBlocked(mesh)
+		return false, nil
+	}
+
+	if len(running) > 0 {
+		mesh.SetCondition(meshv1alpha1.ConditionDeletionBlocked, metav1.ConditionTrue, meshv1alpha1.ReasonControlPlanesRunning,
+			"Clusters still run Istio control planes: %s; annotate the mesh with %s=true to delete them",
+			strings.Join(running, "; "), AnnotationConfirmDeletion)
+	} else {
+		mesh.SetCondition(meshv1alpha1.ConditionDeletionBlocked, metav1.ConditionTrue, meshv1alpha1.ReasonSafetyCheckPending,
+			"Waiting for clusters %s to report their Istio control planes; annotate the mesh with %s=true to delete without checking",
+			strings.Join(pending, ", "), AnnotationConfirmDeletion)
+	}
+	klog.Infof("Deletion of MultiClusterMesh %s/%s is blocked: %s", mesh.Namespace, mesh.Name,
+		meta.FindStatusCondition(mesh.Status.Conditions, meshv1alpha1.ConditionDeletionBlocked).Message)
+	return true, r.updateStatus(ctx, mesh, oldStatus)
+}
+
+// clearDeletionBlocked removes the DeletionBlocked conditions of the mesh once its deletion proceeds.
+func clearDeletionBlocked(mesh *meshv1alpha1.MultiClusterMesh) {
+	meta.RemoveStatusCondition(&mesh.Status.Conditions, meshv1alpha1.ConditionDeletionBlocked)
+	for i := range mesh.Status.ClusterStatus {
+		meta.RemoveStatusCondition(&mesh.Status.ClusterStatus[i].Conditions, meshv1alpha1.ConditionDeletionBlocked)
+	}
+}
+
+// deletionGuardFindings describes the Istio control plane resources a deletion guard ManifestWork reports in the control
+// plane namespace: the Istio resource, with the number of its revisions still used by injected workloads, and the istiod
+// Deployment. The cluster has not reported yet until the work agent reports the existence of every guarded resource.
+func deletionGuardFindings(guard *workv1.ManifestWork, cpNamespace string) (reported bool, findings []string) {
+	for _, resource := range deletionGuardResources(cpNamespace) {
+		index := slices.IndexFunc(guard.Status.ResourceStatus.Manifests, func(m workv1.ManifestCondition) bool {
+			return m.ResourceMeta.Kind == resource.kind && m.ResourceMeta.Namespace == resource.namespace && m.ResourceMeta.Name == resource.name
+		})
+		if index < 0 {
+			return false, nil
+		}
+		status := guard.Status.ResourceStatus.Manifests[index]
+		available := meta.FindStatusCondition(status.Conditions, string(workv1.ManifestAvailable))
+		if available == nil || available.Status == metav1.ConditionUnknown {
+			return false, nil
+		}
+		if available.Status == metav1.ConditionFalse {
+			continue
+		}
+
+		feedback := feedbackValues(status.StatusFeedbacks.Values)
+		switch resource.kind {
+		case "Istio":
+			// The Istio resource is cluster-scoped and may deploy its control plane into another namespace.
+			if feedback[FeedbackIstioNamespace] != "" && feedback[FeedbackIstioNamespace] != cpNamespace {
+				continue
+			}
+			finding := "Istio " + resource.name
+			if state := feedback[FeedbackIstioState]; state != "" {
+				finding += " in state " + state
+			}
+			if inUse, _ := strconv.Atoi(feedback[FeedbackRevisionsInUse]); inUse > 0 {
+				finding += fmt.Sprintf(" with %d revisions in use by injected workloads", inUse)
+			}
+			findings = append(findings, finding)
+		default:
+			findings = append(findings, fmt.Sprintf("Deployment %s/%s with %s ready replicas", resource.namespace, resource.name, cmp.Or(feedback[FeedbackReadyReplicas], "0")))
+		}
+	}
+	return true, findings
+}
+
+// probedResource identifies a resource of a cluster that a read-only ManifestWork reports back to the hub.
+type probedResource struct {
+	groupVersion    schema.GroupVersion
+	kind, resource  string
+	name, namespace string
+}
+
+// istiodDeployment returns the istiod Deployment the Sail operator creates in the control plane namespace.
+func istiodDeployment(cpNamespace string) probedResource {
+	return probedResource{groupVersion: appsv1.SchemeGroupVersion, kind: "Deployment", resource: "deployments", name: IstiodDeploymentName, namespace: cpNamespace}
+}
+
+// readOnlyProbe returns the manifest and the ManifestConfig probing the resource. The resource is read-only, so the
+// work agent neither changes nor deletes it, and reports its status back to the hub with the feedback rules.
+func (p probedResource) readOnlyProbe(feedbackRules ...workv1.FeedbackRule) (workv1.Manifest, workv1.ManifestConfigOption) {
+	manifest := workv1.Manifest{RawExtension: runtime.RawExtension{Object: &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: p.groupVersion.String(), Kind: p.kind},
+		ObjectMeta: metav1.ObjectMeta{Name: p.name, Namespace: p.namespace},
+	}}}
+	config := workv1.ManifestConfigOption{
+		ResourceIdentifier: workv1.ResourceIdentifier{
+			Group:     p.groupVersion.Group,
+			Resource:  p.resource,
+			Name:      p.name,
+			Namespace: p.namespace,
+		},
+		UpdateStrategy: &workv1.UpdateStrategy{
+			Type: workv1.UpdateStrategyTypeReadOnly,
+		},
+		FeedbackRules: feedbackRules,
+	}
+	return manifest, config
+}
+
+// deletionGuardResources returns the Istio control plane resources checked before deleting a control plane namespace:
+// the default Istio resource of the Sail operator and the istiod Deployment it creates in the namespace.
+func deletionGuardResources(cpNamespace string) []probedResource {
+	return []probedResource{
+		{groupVersion: schema.GroupVersion{Group: "sailoperator.io", Version: "v1"}, kind: "Istio", resource: "istios", name: IstioResourceName},
+		istiodDeployment(cpNamespace),
+	}
+}
+
+// buildDeletionGuardManifestWork builds a read-only ManifestWork that reports whether a cluster runs an Istio control
+// plane in the control plane namespace. The work agent is granted read access to Istio resources by a ClusterRole.
+func buildDeletionGuardManifestWork(mesh *meshv1alpha1.MultiClusterMesh, clusterName, cpNamespace string) *workv1.ManifestWork {
+	manifests := []workv1.Manifest{{RawExtension: runtime.RawExtension{Object: &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "rbac.authorization.k8s.io/v1",
+			Kind:       "ClusterRole",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "klusterlet-work-sail-ossm",
+			Labels: map[string]string{
+				"open-cluster-management.io/aggregate-to-work": "true",
+			},
+		},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups: []string{"sailoperator.io"},
+			Resources: []string{"istios"},
+			Verbs:     []string{"get", "list", "watch"},
+		}},
+	}}}}
+	var manifestConfigs []workv1.ManifestConfigOption
+	for _, resource := range deletionGuardResources(cpNamespace) {
+		feedbackRules := []workv1.FeedbackRule{{Type: workv1.WellKnownStatusType}}
+		if resource.kind == "Istio" {
+			feedbackRules = []workv1.FeedbackRule{{
+				Type: workv1.JSONPathsType,
+				JsonPaths: []workv1.JsonPath{
+					{Name: FeedbackIstioNamespace, Path: ".spec.namespace"},
+					{Name: FeedbackIstioState, Path: ".status.state"},
+					{Name: FeedbackRevisionsInUse, Path: ".status.revisions.inUse"},
+				},
+			}}
+		}
+		manifest, config := resource.readOnlyProbe(feedbackRules...)
+		manifests = append(manifests, manifest)
+		manifestConfigs = append(manifestConfigs, config)
+	}
+
+	return &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ManifestWorkNameDeletionGuardPrefix + cpNamespace,
+			Namespace: clusterName,
+			Labels:    meshOwnedLabels(mesh, clusterName),
+		},
+		Spec: workv1.ManifestWorkSpec{
+			Workload: workv1.ManifestsTemplate{
+				Manifests: manifests,
+			},
+			ManifestConfigs: manifestConfigs,
+		},
+	}
+}
+
+// defaultCleanupTimeout is how long the deletion of a mesh waits for its clusters to clean up if the mesh does not set it.
+const defaultCleanupTimeout = 10 * time.Minute
+
+// cleanupTimeout returns how long the deletion of the mesh waits for its clusters to clean up.
+func cleanupTimeout(mesh *meshv1alpha1.MultiClusterMesh) time.Duration {
+	if mesh.Spec.DeletionPolicy.CleanupTimeout != nil {
+		return mesh.Spec.DeletionPolicy.CleanupTimeout.Duration
+	}
+	return defaultCleanupTimeout
+}
+
+// awaitCleanup tracks the deletion of the mesh-owned ManifestWorks, including the ones delivering remote secrets, and
+// ManagedServiceAccounts, which remain until the agents on the clusters deleted their resources, and reports it with the
+// Terminating condition. It returns how long to wait before checking again while clusters are still cleaning up, or zero
+// once the cleanup is complete. Clusters that have not cleaned up within the cleanup timeout are recorded and skipped:
+// their ManifestWorks remain terminating in the cluster namespace, and the work agent deletes their resources once it is
+// reachable again.
+func (r *Reconciler) awaitCleanup(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) (time.Duration, error) {
+	remaining, err := r.remainingMeshResources(ctx, mesh)
+	if err != nil {
+		return 0, err
+	}
+	if len(remaining) == 0 {
+		klog.Infof("Clusters cleaned up the resources of MultiClusterMesh %s/%s", mesh.Namespace, mesh.Name)
+		return 0, nil
+	}
+
+	oldStatus := mesh.Status.DeepCopy()
+	started := time.Now()
+	if c := meta.FindStatusCondition(mesh.Status.Conditions, meshv1alpha1.ConditionTerminating); c != nil && c.Status == metav1.ConditionTrue {
+		started = c.LastTransitionTime.Time
+	}
+	timeout := cleanupTimeout(mesh)
+	elapsed := time.Since(started)
+	timedOut := elapsed >= timeout
+
+	clusterNames := slices.Sorted(maps.Keys(remaining))
+	for _, cs := range mesh.Status.ClusterStatus {
+		if _, ok := remaining[cs.ClusterName]; !ok {
+			mesh.SetClusterCondition(cs.ClusterName, meshv1alpha1.ConditionTerminating, metav1.ConditionFalse, meshv1alpha1.ReasonCleanupComplete,
+				"Cluster deleted the resources of the mesh")
+		}
+	}
+	for _, clusterName := range clusterNames {
+		resources := strings.Join(remaining[clusterName], ", ")
+		if timedOut {
+			mesh.SetClusterCondition(clusterName, meshv1alpha1.ConditionTerminating, metav1.ConditionTrue, meshv1alpha1.ReasonCleanupTimedOut,
+				"Skipped the cleanup of %s after %s, the cluster agents delete them once the cluster is reachable again", resources, timeout)
+		} else {
+			mesh.SetClusterCondition(clusterName, meshv1alpha1.ConditionTerminating, metav1.ConditionTrue, meshv1alpha1.ReasonCleanupInProgress,
+				"Waiting for the cluster to delete %s", resources)
+		}
+	}
+
+	if timedOut {
+		mesh.SetCondition(meshv1alpha1.ConditionTerminating, metav1.ConditionTrue, meshv1alpha1.ReasonCleanupTimedOut,
+			"Clusters %s did not clean up within %s and were skipped", strings.Join(clusterNames, ", "), timeout)
+		klog.Warningf("Clusters %s did not clean up MultiClusterMesh %s/%s within %s, skipping them: %v",
+			strings.Join(clusterNames, ", "), mesh.Namespace, mesh.Name, timeout, remaining)
+		return 0, r.updateStatus(ctx, mesh, oldStatus)
+	}
+
+	mesh.SetCondition(meshv1alpha1.ConditionTerminating, metav1.ConditionTrue, meshv1alpha1.ReasonCleanupInProgress,
+		"Waiting for clusters %s to clean up", strings.Join(clusterNames, ", "))
+	klog.Infof("Waiting for clusters %s to clean up MultiClusterMesh %s/%s", strings.Join(clusterNames, ", "), mesh.Namespace, mesh.Name)
+	if err := r.updateStatus(ctx, mesh, oldStatus); err != nil {
+		return 0, err
+	}
+	return timeout - elapsed, nil
+}
+
+// remainingMeshResources returns the mesh-owned ManifestWorks, including the ones delivering remote secrets, and
+// ManagedServiceAccounts that still exist, by cluster.
+func (r *Reconciler) remainingMeshResources(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) (map[string][]string, error) {
+	labels := client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}
+	remaining := map[string][]string{}
+
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, labels); err != nil {
+		return nil, fmt.Errorf("failed to list mesh-owned ManifestWorks: %w", err)
+	}
+	for _, work := range workList.Items {
+		remaining[work.Namespace] = append(remaining[work.Namespace], "ManifestWork "+work.Name)
+	}
+
+	remoteSecretWorks, err := r.listRemoteSecretManifestWorks(ctx, mesh)
+	if err != nil {
+		return nil, err
+	}
+	for _, work := range remoteSecretWorks {
+		remaining[work.Namespace] = append(remaining[work.Namespace], "ManifestWork "+work.Name)
+	}
+
+	msaList := &msav1beta1.ManagedServiceAccountList{}
+	if err := r.List(ctx, msaList, labels); err != nil {
+		return nil, fmt.Errorf("failed to list mesh-owned ManagedServiceAccounts: %w", err)
+	}
+	for _, msa := range msaList.Items {
+		remaining[msa.Namespace] = append(remaining[msa.Namespace], "ManagedServiceAccount "+msa.Name)
+	}
+	return remaining, nil
+}
+
+// listRemoteSecretManifestWorks returns the ManifestWorks generated from the remote secret ManifestWorkReplicaSets of the
+// mesh. They carry the "<namespace>.<name>" of their ManifestWorkReplicaSet instead of the mesh labels, taken from the
+// existing ManifestWorkReplicaSets and from the names of the clusters in the status, whose ManifestWorkReplicaSet may
+// already be gone while the work agents still delete the remote secrets.
+func (r *Reconciler) listRemoteSecretManifestWorks(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) ([]workv1.ManifestWork, error) {
+	owners := map[string]bool{}
+	mwrsetList := &workv1alpha1.ManifestWorkReplicaSetList{}
+	if err := r.List(ctx, mwrsetList, client.InNamespace(mesh.Namespace),
+		client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}); err != nil {
+		return nil, fmt.Errorf("failed to list ManifestWorkReplicaSets: %w", err)
+	}
+	for _, mwrset := range mwrsetList.Items {
+		owners[mwrset.Namespace+"."+mwrset.Name] = true
+	}
+	for _, cs := range mesh.Status.ClusterStatus {
+		owners[mesh.Namespace+"."+RemoteSecretDistributionName(mesh, cs.ClusterName)] = true
+	}
+
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, client.HasLabels{workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey}); err != nil {
+		return nil, fmt.Errorf("failed to list remote secret ManifestWorks: %w", err)
+	}
+	return slices.DeleteFunc(workList.Items, func(work workv1.ManifestWork) bool {
+		return !owners[work.Labels[workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey]]
+	}), nil
+}
+
+// cleanupRemoteSecretDistributions deletes the remote secret ManifestWorkReplicaSets and Placements of the mesh, and the
+// ManifestWorks generated from them, so that the work agents remove the remote secrets from the clusters.
+func (r *Reconciler) cleanupRemoteSecretDistributions(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) error {
+	works, err := r.listRemoteSecretManifestWorks(ctx, mesh)
+	if err != nil {
+		return err
+	}
+	if err := r.cleanupRemoteSecretDistribution(ctx, mesh, nil); err != nil {
+		return err
+	}
+	for _, work := range works {
+		if err := r.deleteManifestWork(ctx, &work); err != nil {
+			return err
+		}
+	}
+	return nil
+}
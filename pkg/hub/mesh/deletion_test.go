@@ -0,0 +1,301 @@
+package mesh
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+	msav1beta1 "open-cluster-management.io/managed-serviceaccount/apis/authentication/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         meshv1alpha1.DeletionPolicy
+		expectedPolicy meshv1alpha1.DeletionPolicyType
+	}{
+		{
+			name:           "deletes without a policy",
+			expectedPolicy: meshv1alpha1.DeletionPolicyDelete,
+		},
+		{
+			name:           "falls back to the default policy",
+			policy:         meshv1alpha1.DeletionPolicy{Default: meshv1alpha1.DeletionPolicyOrphan},
+			expectedPolicy: meshv1alpha1.DeletionPolicyOrphan,
+		},
+		{
+			name: "prefers the policy of the resource type",
+			policy: meshv1alpha1.DeletionPolicy{
+				Default:               meshv1alpha1.DeletionPolicyOrphan,
+				ControlPlaneNamespace: meshv1alpha1.DeletionPolicyDelete,
+			},
+			expectedPolicy: meshv1alpha1.DeletionPolicyDelete,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mesh := &meshv1alpha1.MultiClusterMesh{Spec: meshv1alpha1.MultiClusterMeshSpec{DeletionPolicy: tc.policy}}
+			policy := deletionPolicy(mesh, tc.policy.ControlPlaneNamespace)
+			if policy != tc.expectedPolicy {
+				t.Errorf("deletionPolicy() = %s, want %s", policy, tc.expectedPolicy)
+			}
+			if orphaned := deleteOption(policy) != nil; orphaned != (policy == meshv1alpha1.DeletionPolicyOrphan) {
+				t.Errorf("deleteOption(%s) orphans = %v", policy, orphaned)
+			}
+		})
+	}
+}
+
+func TestDeletionGuardFindings(t *testing.T) {
+	resource := func(kind, namespace, name string, available metav1.ConditionStatus, feedback map[string]int64) workv1.ManifestCondition {
+		condition := workv1.ManifestCondition{
+			ResourceMeta: workv1.ManifestResourceMeta{Kind: kind, Name: name, Namespace: namespace},
+			Conditions:   []metav1.Condition{{Type: workv1.ManifestAvailable, Status: available}},
+		}
+		for name, value := range feedback {
+			condition.StatusFeedbacks.Values = append(condition.StatusFeedbacks.Values, workv1.FeedbackValue{
+				Name: name, Value: workv1.FieldValue{Type: workv1.Integer, Integer: &value},
+			})
+		}
+		return condition
+	}
+	istio := func(namespace, state string, inUse int64) workv1.ManifestCondition {
+		condition := resource("Istio", "", "default", metav1.ConditionTrue, map[string]int64{FeedbackRevisionsInUse: inUse})
+		for name, value := range map[string]string{FeedbackIstioNamespace: namespace, FeedbackIstioState: state} {
+			condition.StatusFeedbacks.Values = append(condition.StatusFeedbacks.Values, workv1.FeedbackValue{
+				Name: name, Value: workv1.FieldValue{Type: workv1.String, String: &value},
+			})
+		}
+		return condition
+	}
+	guard := func(manifests ...workv1.ManifestCondition) *workv1.ManifestWork {
+		return &workv1.ManifestWork{Status: workv1.ManifestWorkStatus{ResourceStatus: workv1.ManifestResourceStatus{Manifests: manifests}}}
+	}
+
+	tests := []struct {
+		name             string
+		guard            *workv1.ManifestWork
+		expectedReported bool
+		expectedFindings []string
+	}{
+		{
+			name:  "not reported yet",
+			guard: guard(),
+		},
+		{
+			name: "some resources not reported yet",
+			guard: guard(
+				resource("Istio", "", "default", metav1.ConditionFalse, nil),
+				resource("Deployment", "istio-system", "istiod", metav1.ConditionUnknown, nil),
+			),
+		},
+		{
+			name: "no control plane",
+			guard: guard(
+				resource("Istio", "", "default", metav1.ConditionFalse, nil),
+				resource("Deployment", "istio-system", "istiod", metav1.ConditionFalse, nil),
+			),
+			expectedReported: true,
+		},
+		{
+			name: "control plane in use",
+			guard: guard(
+				istio("istio-system", "Healthy", 2),
+				resource("Deployment", "istio-system", "istiod", metav1.ConditionTrue, map[string]int64{FeedbackReadyReplicas: 1}),
+			),
+			expectedReported: true,
+			expectedFindings: []string{
+				"Istio default in state Healthy with 2 revisions in use by injected workloads",
+				"Deployment istio-system/istiod with 1 ready replicas",
+			},
+		},
+		{
+			name: "Istio resource of another namespace",
+			guard: guard(
+				istio("other-system", "Healthy", 1),
+				resource("Deployment", "istio-system", "istiod", metav1.ConditionFalse, nil),
+			),
+			expectedReported: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reported, findings := deletionGuardFindings(tc.guard, "istio-system")
+			if reported != tc.expectedReported {
+				t.Errorf("deletionGuardFindings() reported = %v, want %v", reported, tc.expectedReported)
+			}
+			if !slices.Equal(findings, tc.expectedFindings) {
+				t.Errorf("deletionGuardFindings() findings = %q, want %q", findings, tc.expectedFindings)
+			}
+		})
+	}
+}
+
+func TestDeletionGuarded(t *testing.T) {
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		policy          meshv1alpha1.DeletionPolicy
+		expectedGuarded bool
+	}{
+		{
+			name:            "guards the deletion by default",
+			expectedGuarded: true,
+		},
+		{
+			name:        "skips the check once confirmed",
+			annotations: map[string]string{AnnotationConfirmDeletion: "true"},
+		},
+		{
+			name:            "guards the deletion of the operator",
+			policy:          meshv1alpha1.DeletionPolicy{ControlPlaneNamespace: meshv1alpha1.DeletionPolicyOrphan},
+			expectedGuarded: true,
+		},
+		{
+			name:   "skips the check when nothing is deleted",
+			policy: meshv1alpha1.DeletionPolicy{Default: meshv1alpha1.DeletionPolicyOrphan},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mesh := &meshv1alpha1.MultiClusterMesh{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+				Spec:       meshv1alpha1.MultiClusterMeshSpec{DeletionPolicy: tc.policy},
+			}
+			if guarded := deletionGuarded(mesh); guarded != tc.expectedGuarded {
+				t.Errorf("deletionGuarded() = %v, want %v", guarded, tc.expectedGuarded)
+			}
+		})
+	}
+}
+
+func TestAwaitCleanup(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = workv1.Install(scheme)
+	_ = workv1alpha1.Install(scheme)
+	_ = msav1beta1.AddToScheme(scheme)
+	_ = meshv1alpha1.Install(scheme)
+
+	newMesh := func(terminatingSince time.Duration) *meshv1alpha1.MultiClusterMesh {
+		mesh := &meshv1alpha1.MultiClusterMesh{
+			ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: "mesh-ns"},
+			Spec: meshv1alpha1.MultiClusterMeshSpec{DeletionPolicy: meshv1alpha1.DeletionPolicy{
+				CleanupTimeout: &metav1.Duration{Duration: time.Minute},
+			}},
+			Status: meshv1alpha1.MultiClusterMeshStatus{ClusterStatus: []meshv1alpha1.ClusterMeshStatus{
+				{ClusterName: "cluster1"}, {ClusterName: "cluster2"},
+			}},
+		}
+		if terminatingSince > 0 {
+			mesh.Status.Conditions = []metav1.Condition{{
+				Type:               meshv1alpha1.ConditionTerminating,
+				Status:             metav1.ConditionTrue,
+				Reason:             meshv1alpha1.ReasonCleanupInProgress,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-terminatingSince)),
+			}}
+		}
+		return mesh
+	}
+	cpNamespaceWork := func(mesh *meshv1alpha1.MultiClusterMesh) client.Object {
+		return &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{
+			Name:      ManifestWorkNameCPNSPrefix + "istio-system",
+			Namespace: "cluster1",
+			Labels:    meshOwnedLabels(mesh, "cluster1"),
+		}}
+	}
+
+	// The ManifestWorks generated from a remote secret ManifestWorkReplicaSet carry none of the mesh labels.
+	remoteSecretWork := func(mesh *meshv1alpha1.MultiClusterMesh) client.Object {
+		name := RemoteSecretDistributionName(mesh, "cluster2")
+		return &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "cluster1",
+			Labels:    map[string]string{workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey: mesh.Namespace + "." + name},
+		}}
+	}
+
+	tests := []struct {
+		name                   string
+		terminatingSince       time.Duration
+		remaining              bool
+		remainingRemoteSecret  bool
+		expectWait             bool
+		expectedReason         string
+		expectedCluster2Reason string
+	}{
+		{
+			name: "clusters cleaned up",
+		},
+		{
+			name:                   "clusters still cleaning up",
+			remaining:              true,
+			expectWait:             true,
+			expectedReason:         meshv1alpha1.ReasonCleanupInProgress,
+			expectedCluster2Reason: meshv1alpha1.ReasonCleanupComplete,
+		},
+		{
+			name:                   "remote secrets still being deleted",
+			remainingRemoteSecret:  true,
+			expectWait:             true,
+			expectedReason:         meshv1alpha1.ReasonCleanupInProgress,
+			expectedCluster2Reason: meshv1alpha1.ReasonCleanupComplete,
+		},
+		{
+			name:                   "cleanup timed out",
+			terminatingSince:       2 * time.Minute,
+			remaining:              true,
+			expectedReason:         meshv1alpha1.ReasonCleanupTimedOut,
+			expectedCluster2Reason: meshv1alpha1.ReasonCleanupComplete,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mesh := newMesh(tc.terminatingSince)
+			objects := []client.Object{mesh.DeepCopy()}
+			if tc.remaining {
+				objects = append(objects, cpNamespaceWork(mesh))
+			}
+			if tc.remainingRemoteSecret {
+				objects = append(objects, remoteSecretWork(mesh))
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(mesh).Build()
+			r := &Reconciler{Client: c, Scheme: scheme}
+
+			requeueAfter, err := r.awaitCleanup(context.Background(), mesh)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if waiting := requeueAfter > 0; waiting != tc.expectWait {
+				t.Errorf("awaitCleanup() requeueAfter = %s, want waiting %v", requeueAfter, tc.expectWait)
+			}
+			if tc.expectedReason == "" {
+				return
+			}
+
+			latest := &meshv1alpha1.MultiClusterMesh{}
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(mesh), latest); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c := meta.FindStatusCondition(latest.Status.Conditions, meshv1alpha1.ConditionTerminating); c == nil || c.Reason != tc.expectedReason {
+				t.Errorf("Terminating condition = %v, want reason %s", c, tc.expectedReason)
+			}
+			if c := meta.FindStatusCondition(latest.Status.ClusterStatus[1].Conditions, meshv1alpha1.ConditionTerminating); c == nil || c.Reason != tc.expectedCluster2Reason {
+				t.Errorf("cluster2 Terminating condition = %v, want reason %s", c, tc.expectedCluster2Reason)
+			}
+		})
+	}
+}
@@ -3,20 +3,29 @@ package mesh
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 
 	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
 	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/tools/clientcmd/api/latest"
 	"k8s.io/klog/v2"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
 	workv1 "open-cluster-management.io/api/work/v1"
 	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
 	msav1beta1 "open-cluster-management.io/managed-serviceaccount/apis/authentication/v1beta1"
@@ -59,31 +68,6 @@ func (r *Reconciler) ensureManagedServiceAccount(ctx context.Context, mesh *mesh
 	return nil
 }
 
-// cleanupManagedServiceAccounts deletes ManagedServiceAccount when the cluster(s) are removed from the given mesh's ClusterSet.
-func (r *Reconciler) cleanupManagedServiceAccounts(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster) error {
-	clusterNames := clusterNameSet(clusters)
-
-	msaList := &msav1beta1.ManagedServiceAccountList{}
-	if err := r.List(ctx, msaList,
-		client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}); err != nil {
-		return fmt.Errorf("failed to list ManagedServiceAccounts: %w", err)
-	}
-
-	for _, msa := range msaList.Items {
-		clusterName := msa.Namespace
-		if clusterNames[clusterName] {
-			continue
-		}
-
-		klog.Infof("Deleting ManagedServiceAccount %s/%s (cluster %s no longer in ClusterSet %s)", msa.Namespace, msa.Name, clusterName, mesh.Spec.ClusterSet)
-		if err := client.IgnoreNotFound(r.Delete(ctx, &msa)); err != nil {
-			return fmt.Errorf("failed to delete ManagedServiceAccount %s/%s: %w", msa.Namespace, msa.Name, err)
-		}
-	}
-
-	return nil
-}
-
 // deleteAllManagedServiceAccounts deletes all ManagedServiceAccount resources managed by a mesh
 func (r *Reconciler) deleteAllManagedServiceAccounts(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) error {
 	msaList := &msav1beta1.ManagedServiceAccountList{}
@@ -92,6 +76,9 @@ func (r *Reconciler) deleteAllManagedServiceAccounts(ctx context.Context, mesh *
 	}
 
 	for _, msa := range msaList.Items {
+		if !msa.DeletionTimestamp.IsZero() {
+			continue
+		}
 		klog.Infof("Deleting ManagedServiceAccount %s/%s", msa.Namespace, msa.Name)
 		if err := client.IgnoreNotFound(r.Delete(ctx, &msa)); err != nil {
 			return fmt.Errorf("failed to delete ManagedServiceAccount %s/%s: %w", msa.Namespace, msa.Name, err)
@@ -120,16 +107,43 @@ func (r *Reconciler) ensureManagedServiceAccountUpdated(ctx context.Context, mes
 	return nil
 }
 
-// ensureRemoteSecretDistribution builds Istio remote discovery secrets from ManagedServiceAccount tokens and distributes them via ManifestWorkReplicaSet.
-func (r *Reconciler) ensureRemoteSecretDistribution(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster) error {
+// RemoteSecretDistributionName returns the name shared by the Placement and ManifestWorkReplicaSet
+// that distribute a cluster's remote secret to its peers.
+// Joining the mesh and cluster names is ambiguous, e.g. mesh "a-b" with cluster "c" and mesh "a" with cluster "b-c",
+// so the name always ends with a hash of the pair. OCM copies the Placement name into PlacementDecision labels and
+// "<namespace>.<name>" of the ManifestWorkReplicaSet into labels of the ManifestWorks it generates, so long names
+// are truncated before the hash to stay valid label values.
+func RemoteSecretDistributionName(mesh *meshv1alpha1.MultiClusterMesh, clusterName string) string {
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(mesh.Name+"/"+clusterName)))[:10]
+	name := fmt.Sprintf("%s-%s", mesh.Name, clusterName)
+	prefixLen := validation.LabelValueMaxLength - len(mesh.Namespace) - 1 - len(hash) - 1
+	if prefixLen <= 0 {
+		return hash
+	}
+	if len(name) > prefixLen {
+		name = strings.TrimRight(name[:prefixLen], "-.")
+	}
+	return name + "-" + hash
+}
+
+// ensureRemoteSecretDistribution builds Istio remote discovery secrets from ManagedServiceAccount tokens and distributes them to peers.
+// Each cluster's remote secret is delivered by its own ManifestWorkReplicaSet, placed on every other cluster in the
+// ClusterSet, so a cluster only ever receives the secrets of its peers (all-to-all for multi-primary) and never its own.
+// The excluded clusters, quarantined or not past the readiness gate, neither distribute nor receive remote secrets.
+// The held clusters, in maintenance, still distribute their remote secret but no longer receive any.
+func (r *Reconciler) ensureRemoteSecretDistribution(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster, excluded, held []string) error {
 	msaName := msaName(mesh)
-	manifests := []workv1.Manifest{}
+	distributed := make(map[string]bool, len(clusters))
 	for _, cluster := range clusters {
-		if len(cluster.Spec.ManagedClusterClientConfigs) == 0 {
-			klog.V(4).Infof("no API endpoint found, skipping secret distribution for cluster %s", cluster.Name)
+		if slices.Contains(excluded, cluster.Name) {
+			continue
+		}
+
+		endpoint, err := resolveAPIServerEndpoint(mesh, &cluster)
+		if err != nil {
+			klog.Warningf("no usable API endpoint found, skipping secret distribution for cluster %s: %v", cluster.Name, err)
 			continue
 		}
-		server := cluster.Spec.ManagedClusterClientConfigs[0].URL
 
 		tokenSecret, err := r.getMSATokenSecret(ctx, msaName, cluster.Name)
 		if err != nil {
@@ -143,38 +157,413 @@ func (r *Reconciler) ensureRemoteSecretDistribution(ctx context.Context, mesh *m
 			continue
 		}
 
-		remoteSecret, err := buildIstioRemoteSecret(tokenSecret, cluster.Name, server, mesh.Spec.ControlPlane.Namespace)
+		remoteSecret, err := buildIstioRemoteSecret(tokenSecret, cluster.Name, endpoint, mesh.Spec.ControlPlane.Namespace)
 		if err != nil {
 			return fmt.Errorf("failed to build Istio remote secret for cluster %s: %w", cluster.Name, err)
 		}
-		manifests = append(manifests, workv1.Manifest{
-			RawExtension: runtime.RawExtension{Object: remoteSecret},
-		})
+
+		if err := r.ensureRemoteSecretPlacement(ctx, mesh, cluster.Name, append(slices.Clone(excluded), held...)); err != nil {
+			return err
+		}
+		if err := r.ensureRemoteSecretManifestWorkReplicaSet(ctx, mesh, cluster.Name, remoteSecret); err != nil {
+			return err
+		}
+		distributed[cluster.Name] = true
+	}
+
+	return r.cleanupRemoteSecretDistribution(ctx, mesh, distributed)
+}
+
+// apiServerEndpoint describes how peers reach a cluster's API server.
+type apiServerEndpoint struct {
+	server        string
+	caData        []byte // nil uses the service account root CA
+	tlsServerName string
+	proxyURL      string
+	impersonate   string
+}
+
+// resolveAPIServerEndpoint selects the endpoint written into the cluster's remote secret according to the mesh's
+// discovery transport and the cluster's annotation overrides.
+func resolveAPIServerEndpoint(mesh *meshv1alpha1.MultiClusterMesh, cluster *clusterv1.ManagedCluster) (*apiServerEndpoint, error) {
+	transport := mesh.Spec.Security.Discovery.Transport
+	if t, ok := cluster.Annotations[meshv1alpha1.AnnotationDiscoveryTransport]; ok {
+		transport = meshv1alpha1.DiscoveryTransport(t)
+	}
+
+	switch transport {
+	case "", meshv1alpha1.DiscoveryTransportDirect:
+		return resolveDirectAPIServerEndpoint(mesh.Spec.Security.Discovery.APIServer, cluster)
+	case meshv1alpha1.DiscoveryTransportClusterProxy:
+		return resolveClusterProxyEndpoint(mesh.Spec.Security.Discovery.ClusterProxy, cluster.Name)
+	default:
+		return nil, fmt.Errorf("unknown discovery transport %q", transport)
+	}
+}
+
+// resolveClusterProxyEndpoint returns the cluster-proxy endpoint routing requests to the cluster's API server.
+func resolveClusterProxyEndpoint(proxy *meshv1alpha1.ClusterProxyConfig, clusterName string) (*apiServerEndpoint, error) {
+	if proxy == nil || proxy.URL == "" {
+		return nil, errors.New("no cluster-proxy URL configured")
+	}
+	if len(proxy.CABundle) == 0 {
+		return nil, errors.New("no cluster-proxy CABundle configured")
+	}
+
+	return &apiServerEndpoint{
+		server:        strings.TrimSuffix(proxy.URL, "/") + "/" + url.PathEscape(clusterName),
+		caData:        proxy.CABundle,
+		tlsServerName: proxy.TLSServerName,
+		impersonate:   proxy.Impersonate,
+	}, nil
+}
+
+// resolveDirectAPIServerEndpoint applies the mesh's API server policy and the cluster's annotation overrides
+// to select one of the cluster's own API server endpoints.
+func resolveDirectAPIServerEndpoint(policy meshv1alpha1.APIServerConfig, cluster *clusterv1.ManagedCluster) (*apiServerEndpoint, error) {
+	annotations := cluster.Annotations
+
+	urlPattern := policy.URLPattern
+	if pattern, ok := annotations[meshv1alpha1.AnnotationAPIServerURLPattern]; ok {
+		urlPattern = pattern
+	}
+	caSource := policy.CASource
+	if source, ok := annotations[meshv1alpha1.AnnotationAPIServerCASource]; ok {
+		caSource = meshv1alpha1.APIServerCASource(source)
+	}
+
+	clientConfig, err := selectClientConfig(cluster.Spec.ManagedClusterClientConfigs, policy.ClientConfigIndex, urlPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &apiServerEndpoint{
+		tlsServerName: policy.TLSServerName,
+		proxyURL:      policy.ProxyURL,
+	}
+	if clientConfig != nil {
+		endpoint.server = clientConfig.URL
+	}
+	if server, ok := annotations[meshv1alpha1.AnnotationAPIServerURL]; ok {
+		endpoint.server = server
+	}
+	if tlsServerName, ok := annotations[meshv1alpha1.AnnotationAPIServerTLSServerName]; ok {
+		endpoint.tlsServerName = tlsServerName
+	}
+	if proxyURL, ok := annotations[meshv1alpha1.AnnotationAPIServerProxyURL]; ok {
+		endpoint.proxyURL = proxyURL
+	}
+
+	if endpoint.server == "" {
+		return nil, errors.New("no client config found")
+	}
+
+	switch caSource {
+	case "", meshv1alpha1.APIServerCASourceServiceAccount:
+	case meshv1alpha1.APIServerCASourceClientConfig:
+		if clientConfig == nil || len(clientConfig.CABundle) == 0 {
+			return nil, errors.New("no CABundle found in the selected client config")
+		}
+		endpoint.caData = clientConfig.CABundle
+	default:
+		return nil, fmt.Errorf("unknown CA source %q", caSource)
+	}
+
+	return endpoint, nil
+}
+
+// selectClientConfig returns the client config at the given index, the first one matching the URL pattern,
+// or the first one if neither is set. It returns nil if the cluster has no client configs.
+func selectClientConfig(clientConfigs []clusterv1.ClientConfig, index *int32, urlPattern string) (*clusterv1.ClientConfig, error) {
+	switch {
+	case urlPattern != "":
+		re, err := regexp.Compile(urlPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL pattern %q: %w", urlPattern, err)
+		}
+		for i := range clientConfigs {
+			if re.MatchString(clientConfigs[i].URL) {
+				return &clientConfigs[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no client config URL matches pattern %q", urlPattern)
+	case index != nil:
+		if int(*index) >= len(clientConfigs) {
+			return nil, fmt.Errorf("client config index %d out of range, cluster has %d client configs", *index, len(clientConfigs))
+		}
+		return &clientConfigs[*index], nil
+	case len(clientConfigs) > 0:
+		return &clientConfigs[0], nil
+	}
+	return nil, nil
+}
+
+// ensureRemoteSecretPlacement ensures a Placement selecting every cluster in the mesh's ClusterSet except the source cluster
+// and the excluded clusters.
+func (r *Reconciler) ensureRemoteSecretPlacement(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, sourceCluster string, excluded []string) error {
+	placement := &clusterv1beta1.Placement{
+		ObjectMeta: metav1.ObjectMeta{Name: RemoteSecretDistributionName(mesh, sourceCluster), Namespace: mesh.Namespace},
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, placement, func() error {
+		placement.Labels = meshOwnedLabels(mesh, sourceCluster)
+		placement.Spec.ClusterSets = []string{mesh.Spec.ClusterSet}
+		placement.Spec.Predicates = []clusterv1beta1.ClusterPredicate{{
+			RequiredClusterSelector: clusterv1beta1.ClusterSelector{
+				LabelSelector: metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      clusterv1.ClusterNameLabelKey,
+						Operator: metav1.LabelSelectorOpNotIn,
+						Values:   append([]string{sourceCluster}, excluded...),
+					}},
+				},
+			},
+		}}
+		return controllerutil.SetControllerReference(mesh, placement, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to ensure Placement %s/%s: %w", placement.Namespace, placement.Name, err)
 	}
+	return nil
+}
 
+// ensureRemoteSecretManifestWorkReplicaSet ensures a ManifestWorkReplicaSet delivering the source cluster's remote secret to its peers.
+// Since every source cluster has its own ManifestWorkReplicaSet, a token rotation only updates the rotated cluster's object.
+func (r *Reconciler) ensureRemoteSecretManifestWorkReplicaSet(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, sourceCluster string, remoteSecret *corev1.Secret) error {
+	desired := buildRemoteSecretManifestWorkReplicaSet(mesh, sourceCluster, remoteSecret)
 	mwrset := &workv1alpha1.ManifestWorkReplicaSet{
-		ObjectMeta: metav1.ObjectMeta{Name: mesh.Name, Namespace: mesh.Namespace},
+		ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace},
 	}
 
 	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, mwrset, func() error {
-		if mwrset.Labels == nil {
-			mwrset.Labels = make(map[string]string)
-		}
-		mwrset.Labels[ManagedByLabel] = ManagedByValue
-		mwrset.Labels[MeshNameLabel] = mesh.Name
-		mwrset.Labels[MeshNamespaceLabel] = mesh.Namespace
-		mwrset.Spec.PlacementRefs = []workv1alpha1.LocalPlacementReference{{Name: mesh.Name}}
-		mwrset.Spec.ManifestWorkTemplate = workv1.ManifestWorkSpec{Workload: workv1.ManifestsTemplate{Manifests: manifests}}
+		mwrset.Labels = desired.Labels
+		mwrset.Spec = desired.Spec
 		return controllerutil.SetControllerReference(mesh, mwrset, r.Scheme)
 	})
-
 	if err != nil {
-		return fmt.Errorf("failed to ensure ManifestWorkReplicaSet %s/%s: %w", mesh.Namespace, mesh.Name, err)
+		return fmt.Errorf("failed to ensure ManifestWorkReplicaSet %s/%s: %w", desired.Namespace, desired.Name, err)
 	}
 
 	if result != controllerutil.OperationResultNone {
-		klog.Infof("Ensured ManifestWorkReplicaSet %s/%s state: %s", mesh.Namespace, mesh.Name, result)
+		klog.Infof("Ensured ManifestWorkReplicaSet %s/%s state: %s", desired.Namespace, desired.Name, result)
+	}
+	return nil
+}
+
+// buildRemoteSecretManifestWorkReplicaSet builds the ManifestWorkReplicaSet for a single source cluster.
+// Its template holds exactly one remote secret, so its size does not grow with the number of clusters in the mesh.
+func buildRemoteSecretManifestWorkReplicaSet(mesh *meshv1alpha1.MultiClusterMesh, sourceCluster string, remoteSecret *corev1.Secret) *workv1alpha1.ManifestWorkReplicaSet {
+	name := RemoteSecretDistributionName(mesh, sourceCluster)
+	return &workv1alpha1.ManifestWorkReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: mesh.Namespace,
+			Labels:    meshOwnedLabels(mesh, sourceCluster),
+		},
+		Spec: workv1alpha1.ManifestWorkReplicaSetSpec{
+			PlacementRefs: []workv1alpha1.LocalPlacementReference{{Name: name}},
+			ManifestWorkTemplate: workv1.ManifestWorkSpec{
+				Workload: workv1.ManifestsTemplate{Manifests: []workv1.Manifest{{
+					RawExtension: runtime.RawExtension{Object: remoteSecret},
+				}}},
+				DeleteOption: deleteOption(deletionPolicy(mesh, mesh.Spec.DeletionPolicy.RemoteSecrets)),
+			},
+		},
+	}
+}
+
+// ManifestWorkNamePeerSecretPrefix prefixes the name of the ManifestWork delivering the remote secret of a peer directly
+// to a cluster outside the ClusterSet, followed by the name of the peer.
+const ManifestWorkNamePeerSecretPrefix = "multicluster-mesh-peer-secret-"
+
+// ensureDirectRemoteSecrets delivers the remote secrets of the peers to the receivers, clusters that left the ClusterSet
+// but still take part in endpoint discovery. The Placements of the peers only select clusters of the ClusterSet, so
+// each peer's remote secret is copied from its ManifestWorkReplicaSet into a mesh-owned ManifestWork in the receiver's
+// namespace. Since the work agent only deletes a resource once no ManifestWork applies it anymore, the secrets stay in
+// place when the ManifestWorkReplicaSet withdraws its own ManifestWork. The direct ManifestWorks of a cluster that
+// rejoined the ClusterSet are only deleted once the ManifestWorkReplicaSet of the peer delivers the secret again.
+//
+// The ManifestWorks of the held clusters are left untouched, except that a member of the ClusterSet in maintenance gets
+// a direct copy of each ManifestWork the ManifestWorkReplicaSets of its peers generated in its namespace, which is never
+// updated. It returns the members whose copies are all applied: the Placements of the peers can leave them out, so that
+// the ManifestWorkReplicaSets no longer change their remote secrets.
+func (r *Reconciler) ensureDirectRemoteSecrets(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster, receivers []string, held map[string]bool) ([]string, error) {
+	mwrsetList := &workv1alpha1.ManifestWorkReplicaSetList{}
+	if err := r.List(ctx, mwrsetList, client.InNamespace(mesh.Namespace),
+		client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}); err != nil {
+		return nil, fmt.Errorf("failed to list ManifestWorkReplicaSets: %w", err)
+	}
+	distributions := map[string]*workv1alpha1.ManifestWorkReplicaSet{}
+	for i, mwrset := range mwrsetList.Items {
+		peer := mwrset.Labels[ClusterNameLabel]
+		if peer != "" && mwrset.DeletionTimestamp.IsZero() && mwrset.Name == RemoteSecretDistributionName(mesh, peer) {
+			distributions[peer] = &mwrsetList.Items[i]
+		}
+	}
+
+	desired := map[string]bool{}
+	for _, receiver := range receivers {
+		for _, peer := range slices.Sorted(maps.Keys(distributions)) {
+			if peer == receiver {
+				continue
+			}
+			desired[receiver+"/"+ManifestWorkNamePeerSecretPrefix+peer] = true
+			if held[receiver] {
+				continue
+			}
+			work, err := r.workApplier.Apply(ctx, buildDirectRemoteSecretManifestWork(mesh, receiver, peer, distributions[peer].Spec.ManifestWorkTemplate))
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply the remote secret of cluster %s on cluster %s: %w", peer, receiver, err)
+			}
+			klog.V(4).Infof("Applied ManifestWork %s/%s delivering the remote secret of cluster %s", work.Namespace, work.Name, peer)
+		}
+	}
+
+	var covered []string
+	for _, cluster := range clusters {
+		if !held[cluster.Name] || quarantined(&cluster) {
+			continue
+		}
+		ok, err := r.holdRemoteSecrets(ctx, mesh, cluster.Name, distributions)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			covered = append(covered, cluster.Name)
+		}
+	}
+
+	members := clusterNameSet(clusters)
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}); err != nil {
+		return nil, fmt.Errorf("failed to list mesh-owned ManifestWorks: %w", err)
+	}
+	for _, work := range workList.Items {
+		peer, ok := strings.CutPrefix(work.Name, ManifestWorkNamePeerSecretPrefix)
+		if !ok || desired[work.Namespace+"/"+work.Name] || held[work.Namespace] {
+			continue
+		}
+		if members[work.Namespace] && distributions[peer] != nil {
+			delivered, err := r.remoteSecretDelivered(ctx, mesh, work.Namespace, peer)
+			if err != nil {
+				return nil, err
+			}
+			if !delivered {
+				continue
+			}
+		}
+		if err := r.deleteManifestWork(ctx, &work); err != nil {
+			return nil, err
+		}
+	}
+	return covered, nil
+}
+
+// holdRemoteSecrets copies the ManifestWorks generated in the namespace of a cluster in maintenance by the
+// ManifestWorkReplicaSets of its peers into direct ManifestWorks, created once and never updated, and reports whether
+// every copy is applied.
+func (r *Reconciler) holdRemoteSecrets(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string, distributions map[string]*workv1alpha1.ManifestWorkReplicaSet) (bool, error) {
+	peers := map[string]string{}
+	for peer, mwrset := range distributions {
+		peers[mwrset.Namespace+"."+mwrset.Name] = peer
+	}
+
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, client.InNamespace(clusterName), client.HasLabels{workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey}); err != nil {
+		return false, fmt.Errorf("failed to list ManifestWorks for cluster %s: %w", clusterName, err)
+	}
+	covered := true
+	for _, generated := range workList.Items {
+		peer, ok := peers[generated.Labels[workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey]]
+		if !ok || !generated.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		work := &workv1.ManifestWork{}
+		if err := r.Get(ctx, key.Of(ManifestWorkNamePeerSecretPrefix+peer, clusterName), work); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return false, fmt.Errorf("failed to get ManifestWork %s/%s: %w", clusterName, ManifestWorkNamePeerSecretPrefix+peer, err)
+			}
+			klog.Infof("Holding the remote secret of cluster %s on cluster %s in maintenance", peer, clusterName)
+			if work, err = r.workApplier.Apply(ctx, buildDirectRemoteSecretManifestWork(mesh, clusterName, peer, generated.Spec)); err != nil {
+				return false, fmt.Errorf("failed to apply the remote secret of cluster %s on cluster %s: %w", peer, clusterName, err)
+			}
+		}
+		if reason, _ := manifestWorkState(work); reason != meshv1alpha1.ReasonApplied {
+			covered = false
+		}
+	}
+	return covered, nil
+}
+
+// remoteSecretDelivered reports whether the ManifestWorkReplicaSet of the peer applied its remote secret on the cluster.
+func (r *Reconciler) remoteSecretDelivered(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName, peer string) (bool, error) {
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, client.InNamespace(clusterName), client.MatchingLabels{
+		workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey: mesh.Namespace + "." + RemoteSecretDistributionName(mesh, peer),
+	}); err != nil {
+		return false, fmt.Errorf("failed to list ManifestWorks for cluster %s: %w", clusterName, err)
+	}
+	return slices.ContainsFunc(workList.Items, func(work workv1.ManifestWork) bool {
+		reason, _ := manifestWorkState(&work)
+		return reason == meshv1alpha1.ReasonApplied
+	}), nil
+}
+
+// buildDirectRemoteSecretManifestWork builds the ManifestWork delivering the remote secret of a peer to a cluster,
+// with the manifests and the delete option of the ManifestWork delivering it through the ManifestWorkReplicaSet of the peer.
+func buildDirectRemoteSecretManifestWork(mesh *meshv1alpha1.MultiClusterMesh, clusterName, peer string, spec workv1.ManifestWorkSpec) *workv1.ManifestWork {
+	return &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ManifestWorkNamePeerSecretPrefix + peer,
+			Namespace: clusterName,
+			Labels:    meshOwnedLabels(mesh, clusterName),
+		},
+		Spec: workv1.ManifestWorkSpec{
+			Workload:     spec.Workload,
+			DeleteOption: spec.DeleteOption,
+		},
+	}
+}
+
+// cleanupRemoteSecretDistribution deletes the ManifestWorkReplicaSets and Placements of clusters whose remote secret is no longer distributed.
+// This also removes the mesh-wide ManifestWorkReplicaSet and Placement created by earlier versions, which carry no cluster label,
+// and the ones named without a hash.
+func (r *Reconciler) cleanupRemoteSecretDistribution(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, distributed map[string]bool) error {
+	selector := client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}
+
+	mwrsetList := &workv1alpha1.ManifestWorkReplicaSetList{}
+	if err := r.List(ctx, mwrsetList, client.InNamespace(mesh.Namespace), selector); err != nil {
+		return fmt.Errorf("failed to list ManifestWorkReplicaSets: %w", err)
+	}
+	// Objects named by earlier versions are replaced by the ones named after the current scheme.
+	current := func(obj metav1.Object) bool {
+		clusterName := obj.GetLabels()[ClusterNameLabel]
+		return distributed[clusterName] && obj.GetName() == RemoteSecretDistributionName(mesh, clusterName)
+	}
+	for _, mwrset := range mwrsetList.Items {
+		if current(&mwrset) {
+			continue
+		}
+		klog.Infof("Deleting ManifestWorkReplicaSet %s/%s", mwrset.Namespace, mwrset.Name)
+		if err := client.IgnoreNotFound(r.Delete(ctx, &mwrset)); err != nil {
+			return fmt.Errorf("failed to delete ManifestWorkReplicaSet %s/%s: %w", mwrset.Namespace, mwrset.Name, err)
+		}
+	}
+
+	placementList := &clusterv1beta1.PlacementList{}
+	if err := r.List(ctx, placementList, client.InNamespace(mesh.Namespace), selector); err != nil {
+		return fmt.Errorf("failed to list Placements: %w", err)
+	}
+	for _, placement := range placementList.Items {
+		if current(&placement) {
+			continue
+		}
+		klog.Infof("Deleting Placement %s/%s", placement.Namespace, placement.Name)
+		if err := client.IgnoreNotFound(r.Delete(ctx, &placement)); err != nil {
+			return fmt.Errorf("failed to delete Placement %s/%s: %w", placement.Namespace, placement.Name, err)
+		}
 	}
+
 	return nil
 }
 
@@ -190,12 +579,178 @@ func (r *Reconciler) getMSATokenSecret(ctx context.Context, msaName, clusterName
 	return secret, r.Get(ctx, key.Of(msa.Status.TokenSecretRef.Name, clusterName), secret)
 }
 
+// determineDiscoveryStatus sets the DiscoveryReady and RemoteSecretsApplied conditions of every cluster.
+// It returns true if every cluster's remote secret is available on all its peers and every cluster has applied the remote secrets of its peers.
+func (r *Reconciler) determineDiscoveryStatus(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster) (bool, error) {
+	allReady := true
+	// distributed maps a source cluster to the "<namespace>.<name>" of its ManifestWorkReplicaSet,
+	// the label value OCM sets on the ManifestWorks it generates.
+	distributed := make(map[string]string, len(clusters))
+	for _, cluster := range clusters {
+		mwrset, ready, err := r.determineClusterDiscoveryStatus(ctx, mesh, &cluster)
+		if err != nil {
+			return false, err
+		}
+		allReady = allReady && ready
+		if mwrset != nil {
+			distributed[cluster.Name] = mwrset.Namespace + "." + mwrset.Name
+		}
+	}
+
+	for _, cluster := range clusters {
+		ready, err := r.determineRemoteSecretsStatus(ctx, mesh, cluster.Name, distributed)
+		if err != nil {
+			return false, err
+		}
+		allReady = allReady && ready
+	}
+
+	return allReady, nil
+}
+
+// determineClusterDiscoveryStatus sets the cluster's DiscoveryReady condition and token times.
+// It returns the ManifestWorkReplicaSet distributing the cluster's remote secret, if any,
+// and true if the remote secret is available on all peers selected by its Placement.
+func (r *Reconciler) determineClusterDiscoveryStatus(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, cluster *clusterv1.ManagedCluster) (*workv1alpha1.ManifestWorkReplicaSet, bool, error) {
+	if _, err := resolveAPIServerEndpoint(mesh, cluster); err != nil {
+		mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionDiscoveryReady, metav1.ConditionFalse,
+			meshv1alpha1.ReasonNoAPIEndpoint, "No usable API server endpoint: %v", err)
+		return nil, false, nil
+	}
+
+	msa := &msav1beta1.ManagedServiceAccount{}
+	if err := r.Get(ctx, key.Of(msaName(mesh), cluster.Name), msa); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, false, fmt.Errorf("failed to get ManagedServiceAccount for cluster %s: %w", cluster.Name, err)
+		}
+		mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionDiscoveryReady, metav1.ConditionFalse,
+			meshv1alpha1.ReasonTokenPending, "ManagedServiceAccount has not been created yet")
+		return nil, false, nil
+	}
+
+	discovery := &meshv1alpha1.ClusterDiscoveryStatus{TokenExpirationTime: msa.Status.ExpirationTimestamp}
+	if msa.Status.TokenSecretRef != nil {
+		discovery.LastTokenRotationTime = &msa.Status.TokenSecretRef.LastRefreshTimestamp
+	}
+	mesh.SetClusterDiscovery(cluster.Name, discovery)
+
+	tokenSecret, err := r.getMSATokenSecret(ctx, msa.Name, cluster.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, false, fmt.Errorf("failed to get ManagedServiceAccount token for cluster %s: %w", cluster.Name, err)
+	}
+	if err != nil || tokenSecret == nil {
+		mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionDiscoveryReady, metav1.ConditionFalse,
+			meshv1alpha1.ReasonTokenPending, "Waiting for ManagedServiceAccount %s/%s to issue a token", msa.Namespace, msa.Name)
+		return nil, false, nil
+	}
+
+	mwrset := &workv1alpha1.ManifestWorkReplicaSet{}
+	name := RemoteSecretDistributionName(mesh, cluster.Name)
+	if err := r.Get(ctx, key.Of(name, mesh.Namespace), mwrset); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, false, fmt.Errorf("failed to get ManifestWorkReplicaSet %s/%s: %w", mesh.Namespace, name, err)
+		}
+		mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionDiscoveryReady, metav1.ConditionFalse,
+			meshv1alpha1.ReasonDistributionPending, "Remote secret has not been distributed yet")
+		return nil, false, nil
+	}
+
+	if placementVerified := meta.FindStatusCondition(mwrset.Status.Conditions, workv1alpha1.ManifestWorkReplicaSetConditionPlacementVerified); placementVerified != nil &&
+		placementVerified.Reason == workv1alpha1.ReasonPlacementDecisionEmpty {
+		mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionDiscoveryReady, metav1.ConditionTrue,
+			meshv1alpha1.ReasonDistributed, "No peers to distribute the remote secret to")
+		return mwrset, true, nil
+	}
+
+	var summary workv1alpha1.ManifestWorkReplicaSetSummary
+	for _, placementSummary := range mwrset.Status.PlacementsSummary {
+		if placementSummary.Name == name {
+			summary = placementSummary.Summary
+		}
+	}
+	if summary.DesiredTotal == 0 || summary.Available < summary.DesiredTotal {
+		mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionDiscoveryReady, metav1.ConditionFalse,
+			meshv1alpha1.ReasonDistributionPending, "Remote secret is available on %d of %d peers (applied: %d, degraded: %d)",
+			summary.Available, summary.DesiredTotal, summary.Applied, summary.Degraded)
+		return mwrset, false, nil
+	}
+
+	mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionDiscoveryReady, metav1.ConditionTrue,
+		meshv1alpha1.ReasonDistributed, "Remote secret is available on all %d peers", summary.DesiredTotal)
+	return mwrset, true, nil
+}
+
+// determineRemoteSecretsStatus sets the RemoteSecretsApplied condition of a cluster from the ManifestWorks
+// generated in its namespace by the ManifestWorkReplicaSets of its peers, or from the direct ManifestWorks holding the
+// remote secrets of the peers while the cluster is in maintenance.
+// It returns true if the remote secrets of all distributed peers are applied and available.
+func (r *Reconciler) determineRemoteSecretsStatus(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string, distributed map[string]string) (bool, error) {
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, client.InNamespace(clusterName),
+		client.HasLabels{workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey}); err != nil {
+		return false, fmt.Errorf("failed to list ManifestWorks for cluster %s: %w", clusterName, err)
+	}
+	works := make(map[string]*workv1.ManifestWork, len(workList.Items))
+	for i := range workList.Items {
+		works[workList.Items[i].Labels[workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey]] = &workList.Items[i]
+	}
+
+	var applied int
+	var pending, failed []string
+	for _, peer := range slices.Sorted(maps.Keys(distributed)) {
+		if peer == clusterName {
+			continue
+		}
+		work, ok := works[distributed[peer]]
+		if !ok {
+			work = &workv1.ManifestWork{}
+			if err := r.Get(ctx, key.Of(ManifestWorkNamePeerSecretPrefix+peer, clusterName), work); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return false, fmt.Errorf("failed to get ManifestWork %s/%s: %w", clusterName, ManifestWorkNamePeerSecretPrefix+peer, err)
+				}
+				pending = append(pending, peer)
+				continue
+			}
+		}
+		switch reason, message := manifestWorkState(work); reason {
+		case meshv1alpha1.ReasonApplied:
+			applied++
+		case meshv1alpha1.ReasonApplyPending:
+			pending = append(pending, peer)
+		default:
+			failed = append(failed, fmt.Sprintf("%s: %s", peer, message))
+		}
+	}
+
+	switch {
+	case len(failed) > 0:
+		mesh.SetClusterCondition(clusterName, meshv1alpha1.ConditionRemoteSecretsApplied, metav1.ConditionFalse,
+			meshv1alpha1.ReasonApplyFailed, "Failed to apply remote secrets of peers: %s", strings.Join(failed, "; "))
+	case len(pending) > 0:
+		mesh.SetClusterCondition(clusterName, meshv1alpha1.ConditionRemoteSecretsApplied, metav1.ConditionFalse,
+			meshv1alpha1.ReasonApplyPending, "Waiting for remote secrets of peers: %s", strings.Join(pending, ", "))
+	default:
+		mesh.SetClusterCondition(clusterName, meshv1alpha1.ConditionRemoteSecretsApplied, metav1.ConditionTrue,
+			meshv1alpha1.ReasonApplied, "Remote secrets of %d peers applied", applied)
+		return true, nil
+	}
+	return false, nil
+}
+
+// remoteSecretName returns the name of the Istio remote secret giving access to a cluster.
+func remoteSecretName(clusterName string) string {
+	return "istio-remote-secret-" + clusterName
+}
+
 // buildIstioRemoteSecret builds a remote API server access secret.
 // The secret includes required label and annotation for Istio remote endpoint discovery and data from a ManagedServiceAccount secret.
-func buildIstioRemoteSecret(tokenSecret *corev1.Secret, clusterName, server, namespace string) (*corev1.Secret, error) {
-	ca, ok := tokenSecret.Data[corev1.ServiceAccountRootCAKey]
-	if !ok {
-		return nil, fmt.Errorf("no %q data found", corev1.ServiceAccountRootCAKey)
+func buildIstioRemoteSecret(tokenSecret *corev1.Secret, clusterName string, endpoint *apiServerEndpoint, namespace string) (*corev1.Secret, error) {
+	ca := endpoint.caData
+	if ca == nil {
+		var ok bool
+		if ca, ok = tokenSecret.Data[corev1.ServiceAccountRootCAKey]; !ok {
+			return nil, fmt.Errorf("no %q data found", corev1.ServiceAccountRootCAKey)
+		}
 	}
 	token, ok := tokenSecret.Data[corev1.ServiceAccountTokenKey]
 	if !ok {
@@ -203,8 +758,13 @@ func buildIstioRemoteSecret(tokenSecret *corev1.Secret, clusterName, server, nam
 	}
 
 	kubeconfig := &api.Config{
-		Clusters:       map[string]*api.Cluster{clusterName: {CertificateAuthorityData: ca, Server: server}},
-		AuthInfos:      map[string]*api.AuthInfo{clusterName: {Token: string(token)}},
+		Clusters: map[string]*api.Cluster{clusterName: {
+			CertificateAuthorityData: ca,
+			Server:                   endpoint.server,
+			TLSServerName:            endpoint.tlsServerName,
+			ProxyURL:                 endpoint.proxyURL,
+		}},
+		AuthInfos:      map[string]*api.AuthInfo{clusterName: {Token: string(token), Impersonate: endpoint.impersonate}},
 		Contexts:       map[string]*api.Context{clusterName: {Cluster: clusterName, AuthInfo: clusterName}},
 		CurrentContext: clusterName,
 	}
@@ -220,7 +780,7 @@ func buildIstioRemoteSecret(tokenSecret *corev1.Secret, clusterName, server, nam
 	return &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        "istio-remote-secret-" + clusterName,
+			Name:        remoteSecretName(clusterName),
 			Namespace:   namespace,
 			Annotations: map[string]string{"networking.istio.io/cluster": clusterName},
 			Labels:      map[string]string{"istio/multiCluster": "true"},
@@ -0,0 +1,554 @@
+package mesh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/ptr"
+	workfake "open-cluster-management.io/api/client/work/clientset/versioned/fake"
+	workv1lister "open-cluster-management.io/api/client/work/listers/work/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	"open-cluster-management.io/api/utils/work/v1/workbuilder"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+	"open-cluster-management.io/sdk-go/pkg/apis/work/v1/applier"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestRemoteSecretDistributionName(t *testing.T) {
+	longMesh := strings.Repeat("m", 40)
+	longCluster := strings.Repeat("c", 40)
+
+	tests := []struct {
+		name      string
+		mesh      *meshv1alpha1.MultiClusterMesh
+		cluster   string
+		prefix    string
+		truncated bool
+	}{
+		{
+			name:    "short names are joined and hashed",
+			mesh:    meshWith("mesh-ns", "mesh", metav1.Now()),
+			cluster: "cluster1",
+			prefix:  "mesh-cluster1-",
+		},
+		{
+			name:      "long names are truncated and hashed",
+			mesh:      meshWith("mesh-ns", longMesh, metav1.Now()),
+			cluster:   longCluster,
+			truncated: true,
+		},
+		{
+			name:      "long namespace shortens the name",
+			mesh:      meshWith(strings.Repeat("n", 50), "mesh", metav1.Now()),
+			cluster:   "cluster1",
+			truncated: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := RemoteSecretDistributionName(tc.mesh, tc.cluster)
+			if label := tc.mesh.Namespace + "." + got; len(label) > validation.LabelValueMaxLength {
+				t.Errorf("label value %q exceeds %d characters", label, validation.LabelValueMaxLength)
+			}
+			if errs := validation.IsDNS1123Subdomain(got); len(errs) > 0 {
+				t.Errorf("name %q is not a valid object name: %v", got, errs)
+			}
+			if !strings.HasPrefix(got, tc.prefix) {
+				t.Errorf("RemoteSecretDistributionName() = %q, want prefix %q", got, tc.prefix)
+			}
+			if tc.truncated && strings.HasPrefix(got, fmt.Sprintf("%s-%s", tc.mesh.Name, tc.cluster)) {
+				t.Errorf("expected %q to be truncated", got)
+			}
+			if again := RemoteSecretDistributionName(tc.mesh, tc.cluster); again != got {
+				t.Errorf("name is not deterministic: %q != %q", got, again)
+			}
+		})
+	}
+
+	mesh := meshWith("mesh-ns", longMesh, metav1.Now())
+	if RemoteSecretDistributionName(mesh, longCluster+"-a") == RemoteSecretDistributionName(mesh, longCluster+"-b") {
+		t.Error("truncated names of different clusters must not collide")
+	}
+	if RemoteSecretDistributionName(meshWith("mesh-ns", "a-b", metav1.Now()), "c") == RemoteSecretDistributionName(meshWith("mesh-ns", "a", metav1.Now()), "b-c") {
+		t.Error("names of different mesh and cluster pairs joining to the same string must not collide")
+	}
+}
+
+func TestResolveAPIServerEndpoint(t *testing.T) {
+	clientConfigs := []clusterv1.ClientConfig{
+		{URL: "https://api.public.example.com:6443", CABundle: []byte("public-ca")},
+		{URL: "https://api.private.example.internal:6443", CABundle: []byte("private-ca")},
+	}
+	clusterProxy := &meshv1alpha1.ClusterProxyConfig{
+		URL:           "https://cluster-proxy.hub.example.com/",
+		CABundle:      []byte("proxy-ca"),
+		TLSServerName: "cluster-proxy-addon-user",
+		Impersonate:   "system:serviceaccount:istio-system:istio-reader",
+	}
+
+	tests := []struct {
+		name          string
+		policy        meshv1alpha1.APIServerConfig
+		transport     meshv1alpha1.DiscoveryTransport
+		clusterProxy  *meshv1alpha1.ClusterProxyConfig
+		annotations   map[string]string
+		clientConfigs []clusterv1.ClientConfig
+		expected      *apiServerEndpoint
+		expectErr     bool
+	}{
+		{
+			name:          "defaults to the first client config and service account CA",
+			clientConfigs: clientConfigs,
+			expected:      &apiServerEndpoint{server: "https://api.public.example.com:6443"},
+		},
+		{
+			name:          "selects client config by index",
+			policy:        meshv1alpha1.APIServerConfig{ClientConfigIndex: ptr.To[int32](1)},
+			clientConfigs: clientConfigs,
+			expected:      &apiServerEndpoint{server: "https://api.private.example.internal:6443"},
+		},
+		{
+			name:          "fails on out of range index",
+			policy:        meshv1alpha1.APIServerConfig{ClientConfigIndex: ptr.To[int32](2)},
+			clientConfigs: clientConfigs,
+			expectErr:     true,
+		},
+		{
+			name:          "selects client config by URL pattern",
+			policy:        meshv1alpha1.APIServerConfig{URLPattern: `\.internal:`},
+			clientConfigs: clientConfigs,
+			expected:      &apiServerEndpoint{server: "https://api.private.example.internal:6443"},
+		},
+		{
+			name:          "fails when no URL matches the pattern",
+			policy:        meshv1alpha1.APIServerConfig{URLPattern: "nomatch"},
+			clientConfigs: clientConfigs,
+			expectErr:     true,
+		},
+		{
+			name:          "annotation overrides the URL pattern",
+			policy:        meshv1alpha1.APIServerConfig{URLPattern: "nomatch"},
+			annotations:   map[string]string{meshv1alpha1.AnnotationAPIServerURLPattern: "public"},
+			clientConfigs: clientConfigs,
+			expected:      &apiServerEndpoint{server: "https://api.public.example.com:6443"},
+		},
+		{
+			name:          "uses the CABundle of the selected client config",
+			policy:        meshv1alpha1.APIServerConfig{ClientConfigIndex: ptr.To[int32](1), CASource: meshv1alpha1.APIServerCASourceClientConfig},
+			clientConfigs: clientConfigs,
+			expected:      &apiServerEndpoint{server: "https://api.private.example.internal:6443", caData: []byte("private-ca")},
+		},
+		{
+			name:          "fails when the selected client config has no CABundle",
+			policy:        meshv1alpha1.APIServerConfig{CASource: meshv1alpha1.APIServerCASourceClientConfig},
+			clientConfigs: []clusterv1.ClientConfig{{URL: "https://api.example.com:6443"}},
+			expectErr:     true,
+		},
+		{
+			name:          "annotation overrides the CA source",
+			annotations:   map[string]string{meshv1alpha1.AnnotationAPIServerCASource: string(meshv1alpha1.APIServerCASourceClientConfig)},
+			clientConfigs: clientConfigs,
+			expected:      &apiServerEndpoint{server: "https://api.public.example.com:6443", caData: []byte("public-ca")},
+		},
+		{
+			name:          "fails on unknown CA source",
+			annotations:   map[string]string{meshv1alpha1.AnnotationAPIServerCASource: "Unknown"},
+			clientConfigs: clientConfigs,
+			expectErr:     true,
+		},
+		{
+			name:        "annotation overrides the URL without client configs",
+			annotations: map[string]string{meshv1alpha1.AnnotationAPIServerURL: "https://api.override.example.com:6443"},
+			expected:    &apiServerEndpoint{server: "https://api.override.example.com:6443"},
+		},
+		{
+			name:      "fails without client configs",
+			expectErr: true,
+		},
+		{
+			name:          "sets TLS server name and proxy URL from the policy",
+			policy:        meshv1alpha1.APIServerConfig{TLSServerName: "kubernetes.default.svc", ProxyURL: "http://proxy.example.com:3128"},
+			clientConfigs: clientConfigs,
+			expected: &apiServerEndpoint{
+				server:        "https://api.public.example.com:6443",
+				tlsServerName: "kubernetes.default.svc",
+				proxyURL:      "http://proxy.example.com:3128",
+			},
+		},
+		{
+			name:   "annotations override TLS server name and proxy URL",
+			policy: meshv1alpha1.APIServerConfig{TLSServerName: "kubernetes.default.svc", ProxyURL: "http://proxy.example.com:3128"},
+			annotations: map[string]string{
+				meshv1alpha1.AnnotationAPIServerTLSServerName: "api.example.com",
+				meshv1alpha1.AnnotationAPIServerProxyURL:      "",
+			},
+			clientConfigs: clientConfigs,
+			expected:      &apiServerEndpoint{server: "https://api.public.example.com:6443", tlsServerName: "api.example.com"},
+		},
+		{
+			name:          "routes through the cluster-proxy",
+			policy:        meshv1alpha1.APIServerConfig{TLSServerName: "kubernetes.default.svc"},
+			transport:     meshv1alpha1.DiscoveryTransportClusterProxy,
+			clusterProxy:  clusterProxy,
+			clientConfigs: clientConfigs,
+			expected: &apiServerEndpoint{
+				server:        "https://cluster-proxy.hub.example.com/cluster1",
+				caData:        []byte("proxy-ca"),
+				tlsServerName: "cluster-proxy-addon-user",
+				impersonate:   "system:serviceaccount:istio-system:istio-reader",
+			},
+		},
+		{
+			name:         "routes through the cluster-proxy without client configs",
+			transport:    meshv1alpha1.DiscoveryTransportClusterProxy,
+			clusterProxy: &meshv1alpha1.ClusterProxyConfig{URL: "https://cluster-proxy.hub.example.com", CABundle: []byte("proxy-ca")},
+			expected:     &apiServerEndpoint{server: "https://cluster-proxy.hub.example.com/cluster1", caData: []byte("proxy-ca")},
+		},
+		{
+			name:          "annotation selects the cluster-proxy transport",
+			clusterProxy:  clusterProxy,
+			annotations:   map[string]string{meshv1alpha1.AnnotationDiscoveryTransport: string(meshv1alpha1.DiscoveryTransportClusterProxy)},
+			clientConfigs: clientConfigs,
+			expected: &apiServerEndpoint{
+				server:        "https://cluster-proxy.hub.example.com/cluster1",
+				caData:        []byte("proxy-ca"),
+				tlsServerName: "cluster-proxy-addon-user",
+				impersonate:   "system:serviceaccount:istio-system:istio-reader",
+			},
+		},
+		{
+			name:          "annotation selects the direct transport",
+			transport:     meshv1alpha1.DiscoveryTransportClusterProxy,
+			clusterProxy:  clusterProxy,
+			annotations:   map[string]string{meshv1alpha1.AnnotationDiscoveryTransport: string(meshv1alpha1.DiscoveryTransportDirect)},
+			clientConfigs: clientConfigs,
+			expected:      &apiServerEndpoint{server: "https://api.public.example.com:6443"},
+		},
+		{
+			name:          "fails when the cluster-proxy is not configured",
+			annotations:   map[string]string{meshv1alpha1.AnnotationDiscoveryTransport: string(meshv1alpha1.DiscoveryTransportClusterProxy)},
+			clientConfigs: clientConfigs,
+			expectErr:     true,
+		},
+		{
+			name:          "fails on unknown transport",
+			annotations:   map[string]string{meshv1alpha1.AnnotationDiscoveryTransport: "Unknown"},
+			clientConfigs: clientConfigs,
+			expectErr:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mesh := meshWith("mesh-ns", "mesh", metav1.Now())
+			mesh.Spec.Security.Discovery.APIServer = tc.policy
+			mesh.Spec.Security.Discovery.Transport = tc.transport
+			mesh.Spec.Security.Discovery.ClusterProxy = tc.clusterProxy
+			cluster := &clusterv1.ManagedCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Annotations: tc.annotations},
+				Spec:       clusterv1.ManagedClusterSpec{ManagedClusterClientConfigs: tc.clientConfigs},
+			}
+
+			got, err := resolveAPIServerEndpoint(mesh, cluster)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got endpoint %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("resolveAPIServerEndpoint() = %+v, want %+v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestBuildIstioRemoteSecretKubeconfig(t *testing.T) {
+	tokenSecret := &corev1.Secret{
+		Data: map[string][]byte{
+			corev1.ServiceAccountRootCAKey: []byte("sa-ca"),
+			corev1.ServiceAccountTokenKey:  []byte("token"),
+		},
+	}
+	endpoint := &apiServerEndpoint{
+		server:        "https://api.example.com:6443",
+		caData:        []byte("client-config-ca"),
+		tlsServerName: "kubernetes.default.svc",
+		proxyURL:      "http://proxy.example.com:3128",
+		impersonate:   "system:serviceaccount:istio-system:istio-reader",
+	}
+
+	secret, err := buildIstioRemoteSecret(tokenSecret, "cluster1", endpoint, "istio-system")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kubeconfig, err := clientcmd.Load(secret.Data["cluster1"])
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+
+	cluster := kubeconfig.Clusters["cluster1"]
+	if cluster == nil {
+		t.Fatal("kubeconfig has no cluster1 entry")
+	}
+	if cluster.Server != endpoint.server {
+		t.Errorf("server = %q, want %q", cluster.Server, endpoint.server)
+	}
+	if string(cluster.CertificateAuthorityData) != "client-config-ca" {
+		t.Errorf("CA = %q, want the client config CA", cluster.CertificateAuthorityData)
+	}
+	if cluster.TLSServerName != endpoint.tlsServerName {
+		t.Errorf("tls-server-name = %q, want %q", cluster.TLSServerName, endpoint.tlsServerName)
+	}
+	if cluster.ProxyURL != endpoint.proxyURL {
+		t.Errorf("proxy-url = %q, want %q", cluster.ProxyURL, endpoint.proxyURL)
+	}
+	if authInfo := kubeconfig.AuthInfos["cluster1"]; authInfo == nil || authInfo.Impersonate != endpoint.impersonate {
+		t.Errorf("expected user cluster1 to impersonate %q, got %+v", endpoint.impersonate, authInfo)
+	}
+}
+
+// BenchmarkRemoteSecretDistribution builds the remote secret distribution of a 500 cluster mesh, verifies that no
+// single object grows with the number of clusters, and reports the ManifestWorks and the writes a token rotation causes,
+// compared to one ManifestWork per receiver carrying the secrets of all its peers.
+func BenchmarkRemoteSecretDistribution(b *testing.B) {
+	const clusterCount = 500
+
+	mesh := meshWith("mesh-ns", "mesh", metav1.Now())
+	mesh.Spec.ControlPlane.Namespace = "istio-system"
+	tokenSecret := &corev1.Secret{
+		Data: map[string][]byte{
+			// Sizes are representative of a PEM encoded service account CA and a bound service account token.
+			corev1.ServiceAccountRootCAKey: []byte(strings.Repeat("c", 1600)),
+			corev1.ServiceAccountTokenKey:  []byte(strings.Repeat("t", 1200)),
+		},
+	}
+
+	var maxSize, maxWorkSize int
+	var manifests []workv1.Manifest
+	for b.Loop() {
+		maxSize, maxWorkSize = 0, 0
+		manifests = manifests[:0]
+		for i := range clusterCount {
+			cluster := fmt.Sprintf("cluster-%03d", i)
+			endpoint := &apiServerEndpoint{server: "https://api." + cluster + ".example.com:6443"}
+			remoteSecret, err := buildIstioRemoteSecret(tokenSecret, cluster, endpoint, mesh.Spec.ControlPlane.Namespace)
+			if err != nil {
+				b.Fatalf("failed to build remote secret: %v", err)
+			}
+			mwrset := buildRemoteSecretManifestWorkReplicaSet(mesh, cluster, remoteSecret)
+			data, err := json.Marshal(mwrset)
+			if err != nil {
+				b.Fatalf("failed to marshal ManifestWorkReplicaSet: %v", err)
+			}
+			maxSize = max(maxSize, len(data))
+			// Every peer receives a ManifestWork generated from the template of the ManifestWorkReplicaSet.
+			data, err = json.Marshal(&workv1.ManifestWork{Spec: mwrset.Spec.ManifestWorkTemplate})
+			if err != nil {
+				b.Fatalf("failed to marshal ManifestWork: %v", err)
+			}
+			maxWorkSize = max(maxWorkSize, len(data))
+			manifests = append(manifests, mwrset.Spec.ManifestWorkTemplate.Workload.Manifests...)
+		}
+	}
+
+	// A rotation of one token updates its ManifestWorkReplicaSet, which updates the ManifestWork of every peer.
+	b.ReportMetric(float64(maxSize), "max-bytes/object")
+	b.ReportMetric(float64(clusterCount*(clusterCount-1)), "manifestworks")
+	b.ReportMetric(float64(clusterCount), "writes/rotation")
+	b.ReportMetric(float64(maxSize+(clusterCount-1)*maxWorkSize), "bytes/rotation")
+	if maxSize > workbuilder.DefaultManifestLimit {
+		b.Fatalf("largest ManifestWorkReplicaSet is %d bytes, exceeding the %d bytes manifest limit", maxSize, workbuilder.DefaultManifestLimit)
+	}
+
+	// With one ManifestWork per receiver, a rotation rewrites the ManifestWork of every peer, which holds the secrets
+	// of all the other clusters.
+	receiverWork, err := json.Marshal(&workv1.ManifestWork{Spec: workv1.ManifestWorkSpec{
+		Workload: workv1.ManifestsTemplate{Manifests: manifests[1:]},
+	}})
+	if err != nil {
+		b.Fatalf("failed to marshal ManifestWork: %v", err)
+	}
+	b.ReportMetric(float64(len(receiverWork)), "per-receiver-bytes/object")
+	b.ReportMetric(float64(clusterCount), "per-receiver-manifestworks")
+	b.ReportMetric(float64(clusterCount-1), "per-receiver-writes/rotation")
+	b.ReportMetric(float64((clusterCount-1)*len(receiverWork)), "per-receiver-bytes/rotation")
+}
+
+func TestEnsureDirectRemoteSecrets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = workv1.Install(scheme)
+	_ = workv1alpha1.Install(scheme)
+
+	mesh := &meshv1alpha1.MultiClusterMesh{ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: "mesh-ns"}}
+	distribution := func(peer string) *workv1alpha1.ManifestWorkReplicaSet {
+		return &workv1alpha1.ManifestWorkReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: RemoteSecretDistributionName(mesh, peer), Namespace: mesh.Namespace, Labels: meshOwnedLabels(mesh, peer)},
+			Spec: workv1alpha1.ManifestWorkReplicaSetSpec{ManifestWorkTemplate: workv1.ManifestWorkSpec{
+				Workload: workv1.ManifestsTemplate{Manifests: []workv1.Manifest{{RawExtension: runtime.RawExtension{Raw: []byte(`{"kind":"Secret"}`)}}}},
+			}},
+		}
+	}
+	direct := func(receiver, peer string) *workv1.ManifestWork {
+		return &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{
+			Name: ManifestWorkNamePeerSecretPrefix + peer, Namespace: receiver, Labels: meshOwnedLabels(mesh, receiver),
+		}}
+	}
+	generated := func(receiver, peer string) *workv1.ManifestWork {
+		return &workv1.ManifestWork{
+			ObjectMeta: metav1.ObjectMeta{Name: "generated-" + peer, Namespace: receiver, Labels: map[string]string{
+				workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey: mesh.Namespace + "." + RemoteSecretDistributionName(mesh, peer),
+			}},
+			Status: workv1.ManifestWorkStatus{Conditions: []metav1.Condition{
+				{Type: workv1.WorkApplied, Status: metav1.ConditionTrue},
+				{Type: workv1.WorkAvailable, Status: metav1.ConditionTrue},
+			}},
+		}
+	}
+	applied := func(work *workv1.ManifestWork) *workv1.ManifestWork {
+		work.Status = generated(work.Namespace, "").Status
+		return work
+	}
+	clusters := []clusterv1.ManagedCluster{
+		{ObjectMeta: metav1.ObjectMeta{Name: "member"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "rejoined"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "maintenance"}},
+	}
+
+	tests := []struct {
+		name            string
+		objects         []client.Object
+		receivers       []string
+		held            map[string]bool
+		expectedWorks   []string
+		expectedDeleted []string
+		expectedCovered []string
+	}{
+		{
+			name:          "delivers the secrets of the peers to a cluster pending removal",
+			objects:       []client.Object{distribution("member"), distribution("pending")},
+			receivers:     []string{"pending"},
+			expectedWorks: []string{"pending/" + ManifestWorkNamePeerSecretPrefix + "member"},
+		},
+		{
+			name:    "holds the ManifestWorks of a cluster in maintenance",
+			objects: []client.Object{distribution("member"), direct("gone", "member")},
+			held:    map[string]bool{"gone": true},
+			expectedWorks: []string{
+				"gone/" + ManifestWorkNamePeerSecretPrefix + "member",
+			},
+		},
+		{
+			name:          "copies the secrets delivered to a member in maintenance",
+			objects:       []client.Object{distribution("member"), generated("maintenance", "member")},
+			held:          map[string]bool{"maintenance": true},
+			expectedWorks: []string{"maintenance/" + ManifestWorkNamePeerSecretPrefix + "member"},
+		},
+		{
+			name: "covers a member in maintenance once the copies are applied",
+			objects: []client.Object{
+				distribution("member"), generated("maintenance", "member"), applied(direct("maintenance", "member")),
+			},
+			held:            map[string]bool{"maintenance": true},
+			expectedWorks:   []string{"maintenance/" + ManifestWorkNamePeerSecretPrefix + "member"},
+			expectedCovered: []string{"maintenance"},
+		},
+		{
+			name:            "covers a member in maintenance without remote secrets",
+			objects:         []client.Object{distribution("maintenance")},
+			held:            map[string]bool{"maintenance": true},
+			expectedCovered: []string{"maintenance"},
+		},
+		{
+			name:            "withdraws the secrets once the cluster is removed",
+			objects:         []client.Object{distribution("member"), direct("gone", "member")},
+			expectedDeleted: []string{"gone/" + ManifestWorkNamePeerSecretPrefix + "member"},
+		},
+		{
+			name:            "withdraws the secrets of peers that are no longer distributed",
+			objects:         []client.Object{direct("pending", "member")},
+			receivers:       []string{"pending"},
+			expectedDeleted: []string{"pending/" + ManifestWorkNamePeerSecretPrefix + "member"},
+		},
+		{
+			name:          "keeps the secrets of a rejoined cluster until the peers deliver them again",
+			objects:       []client.Object{distribution("member"), direct("rejoined", "member")},
+			expectedWorks: []string{"rejoined/" + ManifestWorkNamePeerSecretPrefix + "member"},
+		},
+		{
+			name:            "withdraws the secrets of a rejoined cluster delivered by the peers",
+			objects:         []client.Object{distribution("member"), direct("rejoined", "member"), generated("rejoined", "member")},
+			expectedDeleted: []string{"rejoined/" + ManifestWorkNamePeerSecretPrefix + "member"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var works []runtime.Object
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, obj := range tc.objects {
+				if work, ok := obj.(*workv1.ManifestWork); ok {
+					works = append(works, work.DeepCopy())
+					_ = indexer.Add(work)
+				}
+			}
+			workClient := workfake.NewSimpleClientset(works...)
+			r := &Reconciler{
+				Client:      fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build(),
+				Scheme:      scheme,
+				workApplier: applier.NewWorkApplierWithTypedClient(workClient, workv1lister.NewManifestWorkLister(indexer)),
+			}
+
+			covered, err := r.ensureDirectRemoteSecrets(ctx, mesh, clusters, tc.receivers, tc.held)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(covered, tc.expectedCovered) {
+				t.Errorf("expected covered clusters %v, got %v", tc.expectedCovered, covered)
+			}
+
+			for _, name := range tc.expectedWorks {
+				namespace, name, _ := strings.Cut(name, "/")
+				if _, err := workClient.WorkV1().ManifestWorks(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+					t.Errorf("ManifestWork %s/%s not found: %v", namespace, name, err)
+				}
+			}
+			for _, name := range tc.expectedDeleted {
+				namespace, name, _ := strings.Cut(name, "/")
+				if _, err := workClient.WorkV1().ManifestWorks(namespace).Get(ctx, name, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+					t.Errorf("ManifestWork %s/%s was not deleted", namespace, name)
+				}
+			}
+			list, err := workClient.WorkV1().ManifestWorks("pending").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, work := range list.Items {
+				if work.Name == ManifestWorkNamePeerSecretPrefix+"pending" {
+					t.Errorf("cluster pending received its own remote secret")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+	msav1beta1 "open-cluster-management.io/managed-serviceaccount/apis/authentication/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+)
+
+var (
+	orphanedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "multicluster_mesh_gc_orphaned_resources",
+		Help: "Number of resources labelled with a mesh that no longer exists, found by the last garbage collection sweep.",
+	}, []string{"kind"})
+	deletedOrphanedResources = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "multicluster_mesh_gc_deleted_resources_total",
+		Help: "Total number of resources labelled with a mesh that no longer exists, deleted by the garbage collector.",
+	}, []string{"kind"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(orphanedResources, deletedOrphanedResources)
+}
+
+// GarbageCollectorOptions configures the garbage collector of resources left behind by deleted meshes.
+type GarbageCollectorOptions struct {
+	// Interval is the time between two sweeps. The garbage collector is disabled if it is zero.
+	Interval time.Duration
+	// DryRun only logs and counts the orphaned resources without deleting them.
+	DryRun bool
+}
+
+// GarbageCollector periodically deletes the resources carrying the mesh-name and mesh-namespace labels of a mesh that
+// no longer exists. They are left behind when the finalizer of a mesh is removed by hand, or when the mesh is deleted
+// while the controller is down, and nothing reconciles them anymore since all cleanup hangs off a live mesh.
+type GarbageCollector struct {
+	client client.Client
+	// reader reads the meshes from the API server, so that a mesh missing from a stale cache is not taken for deleted
+	reader  client.Reader
+	options GarbageCollectorOptions
+}
+
+// RegisterGarbageCollector adds the garbage collector to the manager. Like the controllers, it only runs on the leader.
+func RegisterGarbageCollector(mgr manager.Manager, options GarbageCollectorOptions) error {
+	if options.Interval <= 0 {
+		klog.Info("Orphaned resource garbage collection is disabled")
+		return nil
+	}
+	return mgr.Add(&GarbageCollector{client: mgr.GetClient(), reader: mgr.GetAPIReader(), options: options})
+}
+
+// Start runs a sweep every interval until the context is done.
+func (gc *GarbageCollector) Start(ctx context.Context) error {
+	klog.Infof("Starting orphaned resource garbage collection every %s (dry run: %v)", gc.options.Interval, gc.options.DryRun)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := gc.sweep(ctx); err != nil {
+			klog.Errorf("Orphaned resource garbage collection failed: %v", err)
+		}
+	}, gc.options.Interval)
+	return nil
+}
+
+// orphanableKind is a kind of resources labelled with the mesh that creates them.
+type orphanableKind struct {
+	kind string
+	list client.ObjectList
+}
+
+// orphanableKinds returns the kinds of resources labelled with the mesh that creates them.
+func orphanableKinds() []orphanableKind {
+	return []orphanableKind{
+		{kind: "ManifestWork", list: &workv1.ManifestWorkList{}},
+		{kind: "ManifestWorkReplicaSet", list: &workv1alpha1.ManifestWorkReplicaSetList{}},
+		{kind: "Placement", list: &clusterv1beta1.PlacementList{}},
+		{kind: "Certificate", list: &certmanagerv1.CertificateList{}},
+		{kind: "ManagedServiceAccount", list: &msav1beta1.ManagedServiceAccountList{}},
+	}
+}
+
+// sweep deletes, or only reports in dry run, the resources labelled with a mesh that no longer exists.
+func (gc *GarbageCollector) sweep(ctx context.Context) error {
+	meshExists := map[types.NamespacedName]bool{}
+	for _, orphanable := range orphanableKinds() {
+		kind, list := orphanable.kind, orphanable.list
+		if err := gc.client.List(ctx, list, client.HasLabels{MeshNameLabel, MeshNamespaceLabel}); err != nil {
+			return fmt.Errorf("failed to list %s resources: %w", kind, err)
+		}
+		objects, err := meta.ExtractList(list)
+		if err != nil {
+			return fmt.Errorf("failed to extract %s resources: %w", kind, err)
+		}
+
+		orphaned := 0
+		for _, o := range objects {
+			obj := o.(client.Object)
+			if !obj.GetDeletionTimestamp().IsZero() {
+				continue
+			}
+			meshKey := key.Of(obj.GetLabels()[MeshNameLabel], obj.GetLabels()[MeshNamespaceLabel])
+			exists, checked := meshExists[meshKey]
+			if !checked {
+				err := gc.reader.Get(ctx, meshKey, &meshv1alpha1.MultiClusterMesh{})
+				if err != nil && !apierrors.IsNotFound(err) {
+					return fmt.Errorf("failed to get MultiClusterMesh %s: %w", meshKey, err)
+				}
+				exists = err == nil
+				meshExists[meshKey] = exists
+			}
+			if exists {
+				continue
+			}
+
+			orphaned++
+			if gc.options.DryRun {
+				klog.Infof("Dry run: would delete %s %s/%s of deleted MultiClusterMesh %s", kind, obj.GetNamespace(), obj.GetName(), meshKey)
+				continue
+			}
+			klog.Infof("Deleting %s %s/%s of deleted MultiClusterMesh %s", kind, obj.GetNamespace(), obj.GetName(), meshKey)
+			if err := client.IgnoreNotFound(gc.client.Delete(ctx, obj)); err != nil {
+				return fmt.Errorf("failed to delete %s %s/%s: %w", kind, obj.GetNamespace(), obj.GetName(), err)
+			}
+			deletedOrphanedResources.WithLabelValues(kind).Inc()
+		}
+		orphanedResources.WithLabelValues(kind).Set(float64(orphaned))
+	}
+	return nil
+}
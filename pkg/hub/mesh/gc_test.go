@@ -0,0 +1,105 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+	msav1beta1 "open-cluster-management.io/managed-serviceaccount/apis/authentication/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestGarbageCollectorSweep(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = meshv1alpha1.Install(scheme)
+	_ = workv1.Install(scheme)
+	_ = workv1alpha1.Install(scheme)
+	_ = clusterv1beta1.Install(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+	_ = msav1beta1.AddToScheme(scheme)
+
+	live := &meshv1alpha1.MultiClusterMesh{ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "mesh-ns"}}
+	deleted := &meshv1alpha1.MultiClusterMesh{ObjectMeta: metav1.ObjectMeta{Name: "deleted", Namespace: "mesh-ns"}}
+	objects := func() []client.Object {
+		return []client.Object{
+			live,
+			&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "live-work", Namespace: "cluster1", Labels: meshOwnedLabels(live, "cluster1")}},
+			&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: "orphaned-work", Namespace: "cluster1", Labels: meshOwnedLabels(deleted, "cluster1")}},
+			&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: OperatorManifestWorkName, Namespace: "cluster1", Labels: map[string]string{ManagedByLabel: ManagedByValue}}},
+			&workv1alpha1.ManifestWorkReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "orphaned-mwrs", Namespace: "mesh-ns", Labels: meshOwnedLabels(deleted, "cluster1")}},
+			&clusterv1beta1.Placement{ObjectMeta: metav1.ObjectMeta{Name: "orphaned-placement", Namespace: "mesh-ns", Labels: meshOwnedLabels(deleted, "cluster1")}},
+			&certmanagerv1.Certificate{ObjectMeta: metav1.ObjectMeta{Name: "orphaned-cert", Namespace: "mesh-ns", Labels: meshOwnedLabels(deleted, "cluster1")}},
+			&msav1beta1.ManagedServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "live-msa", Namespace: "cluster1", Labels: meshOwnedLabels(live, "cluster1")}},
+			&msav1beta1.ManagedServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "orphaned-msa", Namespace: "cluster1", Labels: meshOwnedLabels(deleted, "cluster1")}},
+		}
+	}
+	exists := func(c client.Client, obj client.Object, name, namespace string) bool {
+		err := c.Get(context.Background(), client.ObjectKey{Name: name, Namespace: namespace}, obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return err == nil
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects()...).Build()
+		gc := &GarbageCollector{client: c, reader: c, options: GarbageCollectorOptions{DryRun: true}}
+		deletedBefore := testutil.ToFloat64(deletedOrphanedResources.WithLabelValues("ManifestWork"))
+
+		if err := gc.sweep(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !exists(c, &workv1.ManifestWork{}, "orphaned-work", "cluster1") {
+			t.Errorf("orphaned ManifestWork deleted in dry run")
+		}
+		if found := testutil.ToFloat64(orphanedResources.WithLabelValues("ManifestWork")); found != 1 {
+			t.Errorf("orphaned ManifestWorks = %v, want 1", found)
+		}
+		if deletedAfter := testutil.ToFloat64(deletedOrphanedResources.WithLabelValues("ManifestWork")); deletedAfter != deletedBefore {
+			t.Errorf("deleted ManifestWorks = %v, want %v", deletedAfter, deletedBefore)
+		}
+	})
+
+	t.Run("deletes the resources of deleted meshes", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects()...).Build()
+		gc := &GarbageCollector{client: c, reader: c}
+		deletedBefore := testutil.ToFloat64(deletedOrphanedResources.WithLabelValues("ManagedServiceAccount"))
+
+		if err := gc.sweep(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, orphaned := range []struct {
+			obj             client.Object
+			name, namespace string
+		}{
+			{&workv1.ManifestWork{}, "orphaned-work", "cluster1"},
+			{&workv1alpha1.ManifestWorkReplicaSet{}, "orphaned-mwrs", "mesh-ns"},
+			{&clusterv1beta1.Placement{}, "orphaned-placement", "mesh-ns"},
+			{&certmanagerv1.Certificate{}, "orphaned-cert", "mesh-ns"},
+			{&msav1beta1.ManagedServiceAccount{}, "orphaned-msa", "cluster1"},
+		} {
+			if exists(c, orphaned.obj, orphaned.name, orphaned.namespace) {
+				t.Errorf("orphaned %T %s/%s not deleted", orphaned.obj, orphaned.namespace, orphaned.name)
+			}
+		}
+		if !exists(c, &workv1.ManifestWork{}, "live-work", "cluster1") || !exists(c, &msav1beta1.ManagedServiceAccount{}, "live-msa", "cluster1") {
+			t.Errorf("resources of a live mesh deleted")
+		}
+		if !exists(c, &workv1.ManifestWork{}, OperatorManifestWorkName, "cluster1") {
+			t.Errorf("shared ManifestWork deleted")
+		}
+		if deletedAfter := testutil.ToFloat64(deletedOrphanedResources.WithLabelValues("ManagedServiceAccount")); deletedAfter != deletedBefore+1 {
+			t.Errorf("deleted ManagedServiceAccounts = %v, want %v", deletedAfter, deletedBefore+1)
+		}
+	})
+}
@@ -0,0 +1,384 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/klog/v2"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+)
+
+// ManifestWorkNameInstallPlanApproval is the name of the ManifestWork approving the operator InstallPlan on a cluster.
+const ManifestWorkNameInstallPlanApproval = "multicluster-mesh-operator-approval"
+
+// csvVersionRegexp matches the version of a CSV named after the OLM convention, e.g. servicemeshoperator3.v3.0.0.
+var csvVersionRegexp = regexp.MustCompile(`^.+?\.v([0-9]+\.[0-9]+\.[0-9]+.*)$`)
+
+// Names of the Subscription status fields reported back to the hub via ManifestWork feedback.
+const (
+	FeedbackInstalledCSV      = "installedCSV"
+	FeedbackSubscriptionState = "state"
+	FeedbackCurrentCSV        = "currentCSV"
+	FeedbackInstallPlanName   = "installPlanName"
+
+	FeedbackCatalogSourcesUnhealthy        = "catalogSourcesUnhealthy"
+	FeedbackCatalogSourcesUnhealthyMessage = "catalogSourcesUnhealthyMessage"
+	FeedbackResolutionFailed               = "resolutionFailed"
+	FeedbackResolutionFailedMessage        = "resolutionFailedMessage"
+	FeedbackInstallPlanPending             = "installPlanPending"
+	FeedbackInstallPlanPhase               = "installPlanPhase"
+	FeedbackInstallPlanFailed              = "installPlanFailed"
+	FeedbackInstallPlanFailedMessage       = "installPlanFailedMessage"
+)
+
+// Names of the CatalogSource status fields reported back to the hub via ManifestWork feedback.
+const (
+	FeedbackCatalogSourceState   = "catalogSourceState"
+	FeedbackCatalogSourceMessage = "catalogSourceMessage"
+)
+
+// CatalogSourceStateReady is the gRPC connection state of a CatalogSource serving its catalog.
+const CatalogSourceStateReady = "READY"
+
+// subscriptionFeedbackPaths returns the JSONPaths of the Subscription status fields the work agent reports back to the hub.
+// OLM sets installedCSV only after the operator's CSV reaches the Succeeded phase, so a non-empty value confirms
+// the operator is installed. The remaining fields explain why an installation is stuck. The InstallPlan itself is
+// created by OLM and cannot carry feedback, so its phase is taken from the reason of the InstallPlanPending condition.
+func subscriptionFeedbackPaths() []workv1.JsonPath {
+	conditionPath := func(conditionType operatorsv1alpha1.SubscriptionConditionType, field string) string {
+		return fmt.Sprintf(`.status.conditions[?(@.type=="%s")].%s`, conditionType, field)
+	}
+	return []workv1.JsonPath{
+		{Name: FeedbackInstalledCSV, Path: ".status.installedCSV"},
+		{Name: FeedbackSubscriptionState, Path: ".status.state"},
+		{Name: FeedbackCurrentCSV, Path: ".status.currentCSV"},
+		{Name: FeedbackInstallPlanName, Path: ".status.installPlanRef.name"},
+		{Name: FeedbackCatalogSourcesUnhealthy, Path: conditionPath(operatorsv1alpha1.SubscriptionCatalogSourcesUnhealthy, "status")},
+		{Name: FeedbackCatalogSourcesUnhealthyMessage, Path: conditionPath(operatorsv1alpha1.SubscriptionCatalogSourcesUnhealthy, "message")},
+		{Name: FeedbackResolutionFailed, Path: conditionPath(operatorsv1alpha1.SubscriptionResolutionFailed, "status")},
+		{Name: FeedbackResolutionFailedMessage, Path: conditionPath(operatorsv1alpha1.SubscriptionResolutionFailed, "message")},
+		{Name: FeedbackInstallPlanPending, Path: conditionPath(operatorsv1alpha1.SubscriptionInstallPlanPending, "status")},
+		{Name: FeedbackInstallPlanPhase, Path: conditionPath(operatorsv1alpha1.SubscriptionInstallPlanPending, "reason")},
+		{Name: FeedbackInstallPlanFailed, Path: conditionPath(operatorsv1alpha1.SubscriptionInstallPlanFailed, "status")},
+		{Name: FeedbackInstallPlanFailedMessage, Path: conditionPath(operatorsv1alpha1.SubscriptionInstallPlanFailed, "message")},
+	}
+}
+
+// buildCatalogSource builds the gRPC CatalogSource serving the operator catalog image of an operator configuration.
+func buildCatalogSource(config meshv1alpha1.OperatorConfig) *operatorsv1alpha1.CatalogSource {
+	catalog := config.Catalog
+	source := &operatorsv1alpha1.CatalogSource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "operators.coreos.com/v1alpha1",
+			Kind:       "CatalogSource",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.Source,
+			Namespace: config.SourceNamespace,
+		},
+		Spec: operatorsv1alpha1.CatalogSourceSpec{
+			SourceType:  operatorsv1alpha1.SourceTypeGrpc,
+			Image:       catalog.Image,
+			DisplayName: catalog.DisplayName,
+			Secrets:     catalog.PullSecrets,
+		},
+	}
+	if catalog.PollInterval != nil {
+		source.Spec.UpdateStrategy = &operatorsv1alpha1.UpdateStrategy{
+			RegistryPoll: &operatorsv1alpha1.RegistryPoll{RawInterval: catalog.PollInterval.Duration.String()},
+		}
+	}
+	return source
+}
+
+// catalogSourceFeedbackPaths returns the JSONPaths of the CatalogSource status fields the work agent reports back to the hub.
+func catalogSourceFeedbackPaths() []workv1.JsonPath {
+	return []workv1.JsonPath{
+		{Name: FeedbackCatalogSourceState, Path: ".status.connectionState.lastObservedState"},
+		{Name: FeedbackCatalogSourceMessage, Path: ".status.message"},
+	}
+}
+
+// catalogSourceState derives the CatalogSourceReady condition from the CatalogSource feedback of the operator ManifestWork.
+func catalogSourceState(feedback map[string]string, config meshv1alpha1.OperatorConfig) (status metav1.ConditionStatus, reason, message string) {
+	name := config.SourceNamespace + "/" + config.Source
+	state := feedback[FeedbackCatalogSourceState]
+	switch state {
+	case CatalogSourceStateReady:
+		return metav1.ConditionTrue, meshv1alpha1.ReasonCatalogReady, fmt.Sprintf("CatalogSource %s is connected", name)
+	case "":
+		return metav1.ConditionUnknown, meshv1alpha1.ReasonCatalogConnecting, fmt.Sprintf("CatalogSource %s has not reported its state yet", name)
+	case "TRANSIENT_FAILURE":
+		message := fmt.Sprintf("CatalogSource %s cannot connect to %s", name, config.Catalog.Image)
+		if detail := feedback[FeedbackCatalogSourceMessage]; detail != "" {
+			message += ": " + detail
+		}
+		return metav1.ConditionFalse, meshv1alpha1.ReasonCatalogUnhealthy, message
+	}
+	return metav1.ConditionFalse, meshv1alpha1.ReasonCatalogConnecting, fmt.Sprintf("CatalogSource %s is %s", name, state)
+}
+
+// getManifestWorkFeedback returns the string and integer feedback values reported for the resources of a ManifestWork by name.
+func getManifestWorkFeedback(work *workv1.ManifestWork) map[string]string {
+	feedback := map[string]string{}
+	for _, manifest := range work.Status.ResourceStatus.Manifests {
+		maps.Copy(feedback, feedbackValues(manifest.StatusFeedbacks.Values))
+	}
+	return feedback
+}
+
+// feedbackValues returns the string and integer feedback values reported for a resource by name.
+func feedbackValues(values []workv1.FeedbackValue) map[string]string {
+	feedback := map[string]string{}
+	for _, value := range values {
+		switch {
+		case value.Value.String != nil:
+			feedback[value.Name] = *value.Value.String
+		case value.Value.Integer != nil:
+			feedback[value.Name] = strconv.FormatInt(*value.Value.Integer, 10)
+		}
+	}
+	return feedback
+}
+
+// installPlanAwaitingApproval returns the name of the InstallPlan and the CSV it installs if the Subscription feedback
+// reports an InstallPlan that requires manual approval.
+func installPlanAwaitingApproval(feedback map[string]string) (installPlan, csv string, ok bool) {
+	if feedback[FeedbackInstallPlanPending] != string(metav1.ConditionTrue) ||
+		feedback[FeedbackInstallPlanPhase] != string(operatorsv1alpha1.InstallPlanPhaseRequiresApproval) ||
+		feedback[FeedbackInstallPlanName] == "" {
+		return "", "", false
+	}
+	return feedback[FeedbackInstallPlanName], feedback[FeedbackCurrentCSV], true
+}
+
+// ensureInstallPlanApproval approves the operator InstallPlan on a cluster when the mesh uses Manual approval
+// and the InstallPlan installs the approved CSV. InstallPlans for any other CSV are left pending.
+// The approval ManifestWork is removed once the approved CSV is installed, or when approval is no longer Manual.
+func (r *Reconciler) ensureInstallPlanApproval(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, cluster *clusterv1.ManagedCluster, config meshv1alpha1.OperatorConfig, operatorWork *workv1.ManifestWork) error {
+	feedback := getManifestWorkFeedback(operatorWork)
+	approvedCSV := config.GetApprovedCSV()
+
+	if approvedCSV != "" {
+		if installPlan, csv, ok := installPlanAwaitingApproval(feedback); ok && csv == approvedCSV {
+			work, err := r.workApplier.Apply(ctx, buildInstallPlanApprovalManifestWork(mesh, cluster.Name, config.Namespace, installPlan))
+			if err != nil {
+				return fmt.Errorf("failed to apply InstallPlan approval ManifestWork: %w", err)
+			}
+			klog.V(4).Infof("Applied InstallPlan approval ManifestWork %s/%s for %s", work.Namespace, work.Name, csv)
+			return nil
+		}
+		if feedback[FeedbackInstalledCSV] != approvedCSV {
+			return nil
+		}
+	}
+
+	existing := &workv1.ManifestWork{}
+	if err := r.Get(ctx, key.Of(ManifestWorkNameInstallPlanApproval, cluster.Name), existing); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get InstallPlan approval ManifestWork: %w", err)
+	}
+	klog.Infof("Deleting InstallPlan approval ManifestWork %s/%s", existing.Namespace, existing.Name)
+	return r.workApplier.Delete(ctx, existing.Namespace, existing.Name)
+}
+
+// buildInstallPlanApprovalManifestWork builds a ManifestWork that sets spec.approved on an InstallPlan created by OLM.
+// It is applied server-side so that only the approval is owned by the work agent,
+// and orphans the InstallPlan on deletion so that OLM keeps managing it.
+// The InstallPlan is unstructured, since the typed one would also apply the zero values of the fields OLM owns,
+// such as spec.approval, which conflict with OLM and fail validation.
+func buildInstallPlanApprovalManifestWork(mesh *meshv1alpha1.MultiClusterMesh, clusterName, namespace, installPlan string) *workv1.ManifestWork {
+	identifier := workv1.ResourceIdentifier{
+		Group:     "operators.coreos.com",
+		Resource:  "installplans",
+		Name:      installPlan,
+		Namespace: namespace,
+	}
+	approval := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"approved": true},
+	}}
+	approval.SetAPIVersion("operators.coreos.com/v1alpha1")
+	approval.SetKind("InstallPlan")
+	approval.SetName(installPlan)
+	approval.SetNamespace(namespace)
+	return &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ManifestWorkNameInstallPlanApproval,
+			Namespace: clusterName,
+			Labels: map[string]string{
+				ManagedByLabel:  ManagedByValue,
+				ClusterSetLabel: mesh.Spec.ClusterSet,
+			},
+		},
+		Spec: workv1.ManifestWorkSpec{
+			Workload: workv1.ManifestsTemplate{
+				Manifests: []workv1.Manifest{{
+					RawExtension: runtime.RawExtension{Object: approval},
+				}},
+			},
+			DeleteOption: &workv1.DeleteOption{
+				PropagationPolicy: workv1.DeletePropagationPolicyTypeOrphan,
+			},
+			ManifestConfigs: []workv1.ManifestConfigOption{{
+				ResourceIdentifier: identifier,
+				UpdateStrategy: &workv1.UpdateStrategy{
+					Type: workv1.UpdateStrategyTypeServerSideApply,
+				},
+			}},
+		},
+	}
+}
+
+// operatorInstallState derives the OperatorInstalled condition from the Subscription feedback of the operator ManifestWork.
+// Failures reported by OLM take precedence over the pending InstallPlan, and carry OLM's message.
+// An InstallPlan awaiting approval for the approved CSV is reported as pending, since the hub approves it.
+func operatorInstallState(feedback map[string]string, approvedCSV string) (status metav1.ConditionStatus, reason, message string) {
+	isTrue := func(name string) bool { return feedback[name] == string(metav1.ConditionTrue) }
+	withDetail := func(summary, detail string) string {
+		if detail == "" {
+			return summary
+		}
+		return summary + ": " + detail
+	}
+
+	switch {
+	case feedback[FeedbackInstalledCSV] != "":
+		if feedback[FeedbackSubscriptionState] == string(operatorsv1alpha1.SubscriptionStateFailed) {
+			return metav1.ConditionTrue, meshv1alpha1.ReasonUpgradeFailed,
+				fmt.Sprintf("Operator installed: %s, upgrade to %s failed", feedback[FeedbackInstalledCSV], feedback[FeedbackCurrentCSV])
+		}
+		return metav1.ConditionTrue, meshv1alpha1.ReasonOperatorInstalled, "Operator installed: " + feedback[FeedbackInstalledCSV]
+	case isTrue(FeedbackResolutionFailed):
+		return metav1.ConditionFalse, meshv1alpha1.ReasonResolutionFailed,
+			withDetail("OLM failed to resolve the Subscription", feedback[FeedbackResolutionFailedMessage])
+	case isTrue(FeedbackCatalogSourcesUnhealthy):
+		return metav1.ConditionFalse, meshv1alpha1.ReasonCatalogUnhealthy,
+			withDetail("CatalogSources of the Subscription are unhealthy", feedback[FeedbackCatalogSourcesUnhealthyMessage])
+	case isTrue(FeedbackInstallPlanFailed):
+		return metav1.ConditionFalse, meshv1alpha1.ReasonInstallPlanFailed,
+			withDetail(fmt.Sprintf("InstallPlan %s failed", feedback[FeedbackInstallPlanName]), feedback[FeedbackInstallPlanFailedMessage])
+	case isTrue(FeedbackInstallPlanPending) && feedback[FeedbackInstallPlanPhase] == string(operatorsv1alpha1.InstallPlanPhaseRequiresApproval):
+		installPlan, csv := feedback[FeedbackInstallPlanName], feedback[FeedbackCurrentCSV]
+		if approvedCSV == "" {
+			return metav1.ConditionFalse, meshv1alpha1.ReasonAwaitingApproval,
+				fmt.Sprintf("InstallPlan %s for %s requires approval", installPlan, csv)
+		}
+		if csv != approvedCSV {
+			return metav1.ConditionFalse, meshv1alpha1.ReasonAwaitingApproval,
+				fmt.Sprintf("InstallPlan %s for %s requires approval, only %s is approved", installPlan, csv, approvedCSV)
+		}
+		return metav1.ConditionFalse, meshv1alpha1.ReasonInstallationPending,
+			fmt.Sprintf("InstallPlan %s for %s is approved", installPlan, csv)
+	case isTrue(FeedbackInstallPlanPending):
+		return metav1.ConditionFalse, meshv1alpha1.ReasonInstallationPending,
+			fmt.Sprintf("InstallPlan %s for %s is %s", feedback[FeedbackInstallPlanName], feedback[FeedbackCurrentCSV], feedback[FeedbackInstallPlanPhase])
+	}
+	return metav1.ConditionFalse, meshv1alpha1.ReasonInstallationPending, "Operator installation is pending"
+}
+
+// csvVersion returns the operator version encoded in the name of a CSV, or the name itself if it has no version.
+func csvVersion(csv string) string {
+	if match := csvVersionRegexp.FindStringSubmatch(csv); match != nil {
+		return match[1]
+	}
+	return csv
+}
+
+// determineVersionConsistency sets the VersionConsistent condition and the per-cluster operator versions
+// from the operator versions installed on the clusters, keyed by cluster name.
+func determineVersionConsistency(mesh *meshv1alpha1.MultiClusterMesh, installedVersions map[string]string) {
+	versions := map[string]*utilversion.Version{}
+	var unknown []string
+	for _, clusterName := range slices.Sorted(maps.Keys(installedVersions)) {
+		version, err := utilversion.ParseGeneric(installedVersions[clusterName])
+		if err != nil {
+			klog.V(4).Infof("Cannot determine the operator version of cluster %s: %v", clusterName, err)
+			unknown = append(unknown, clusterName)
+			continue
+		}
+		versions[clusterName] = version
+		mesh.SetClusterOperatorVersion(clusterName, version.String())
+	}
+
+	if len(unknown) > 0 {
+		mesh.SetCondition(meshv1alpha1.ConditionVersionConsistent, metav1.ConditionUnknown, meshv1alpha1.ReasonVersionUnknown,
+			"Cannot determine the operator version of clusters %s", strings.Join(unknown, ", "))
+		return
+	}
+	if len(versions) == 0 {
+		mesh.SetCondition(meshv1alpha1.ConditionVersionConsistent, metav1.ConditionUnknown, meshv1alpha1.ReasonVersionUnknown,
+			"No cluster has installed the operator yet")
+		return
+	}
+
+	if skew := versionSkew(versions, mesh.Spec.VersionSkew); skew != "" {
+		mesh.SetCondition(meshv1alpha1.ConditionVersionConsistent, metav1.ConditionFalse, meshv1alpha1.ReasonVersionSkew,
+			"Clusters run operator versions %s: %s", skew, describeVersions(versions))
+		return
+	}
+	mesh.SetCondition(meshv1alpha1.ConditionVersionConsistent, metav1.ConditionTrue, meshv1alpha1.ReasonVersionsConsistent,
+		"Clusters run operator versions within the allowed skew: %s", describeVersions(versions))
+}
+
+// versionSkew describes the skew between the oldest and the newest version if it exceeds the policy, or returns an empty string.
+func versionSkew(versions map[string]*utilversion.Version, policy *meshv1alpha1.VersionSkewPolicy) string {
+	if policy == nil {
+		policy = &meshv1alpha1.VersionSkewPolicy{}
+	}
+
+	var oldest, newest *utilversion.Version
+	for _, version := range versions {
+		if oldest == nil || version.LessThan(oldest) {
+			oldest = version
+		}
+		if newest == nil || newest.LessThan(version) {
+			newest = version
+		}
+	}
+
+	switch {
+	case oldest.Major() != newest.Major():
+		return fmt.Sprintf("with different major versions %d and %d", oldest.Major(), newest.Major())
+	case newest.Minor()-oldest.Minor() > uint(policy.MaxMinorVersions):
+		return fmt.Sprintf("%d minor versions apart, at most %d allowed", newest.Minor()-oldest.Minor(), policy.MaxMinorVersions)
+	case oldest.Minor() == newest.Minor() && !oldest.EqualTo(newest) && !policy.IgnorePatchVersions:
+		return fmt.Sprintf("%s and %s", oldest, newest)
+	}
+	return ""
+}
+
+// describeVersions lists the clusters running each version, e.g. "3.0.0 (cluster1, cluster2), 3.1.0 (cluster3)".
+func describeVersions(versions map[string]*utilversion.Version) string {
+	clustersByVersion := map[string][]string{}
+	for _, clusterName := range slices.Sorted(maps.Keys(versions)) {
+		version := versions[clusterName].String()
+		clustersByVersion[version] = append(clustersByVersion[version], clusterName)
+	}
+
+	ordered := slices.SortedFunc(maps.Keys(clustersByVersion), func(a, b string) int {
+		c, _ := utilversion.MustParseGeneric(a).Compare(b)
+		return c
+	})
+	parts := make([]string, 0, len(ordered))
+	for _, version := range ordered {
+		parts = append(parts, fmt.Sprintf("%s (%s)", version, strings.Join(clustersByVersion[version], ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
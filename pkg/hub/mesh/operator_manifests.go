@@ -0,0 +1,235 @@
+package mesh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+)
+
+// Names of the operator Deployment status fields reported back to the hub via ManifestWork feedback.
+// The replica counts are the well-known status fields the work agent reports for Deployments.
+const (
+	FeedbackReplicas        = "Replicas"
+	FeedbackReadyReplicas   = "ReadyReplicas"
+	FeedbackUpdatedReplicas = "updatedReplicas"
+	FeedbackOperatorImage   = "operatorImage"
+)
+
+// operatorManifestConfigs returns the ManifestConfigs reporting the installation state of the operator back to the hub:
+// the Subscription and CatalogSource status in the OLM install mode, and the operator Deployment status in the Manifests install mode.
+func operatorManifestConfigs(config meshv1alpha1.OperatorConfig) []workv1.ManifestConfigOption {
+	if config.InstallMode == meshv1alpha1.OperatorInstallModeManifests {
+		return []workv1.ManifestConfigOption{{
+			ResourceIdentifier: workv1.ResourceIdentifier{
+				Group:     "apps",
+				Resource:  "deployments",
+				Name:      config.Manifests.DeploymentName,
+				Namespace: config.Namespace,
+			},
+			FeedbackRules: []workv1.FeedbackRule{
+				{Type: workv1.WellKnownStatusType},
+				{Type: workv1.JSONPathsType, JsonPaths: []workv1.JsonPath{
+					{Name: FeedbackUpdatedReplicas, Path: ".status.updatedReplicas"},
+					{Name: FeedbackOperatorImage, Path: ".spec.template.spec.containers[0].image"},
+				}},
+			},
+		}}
+	}
+
+	configs := []workv1.ManifestConfigOption{{
+		ResourceIdentifier: workv1.ResourceIdentifier{
+			Group:     "operators.coreos.com",
+			Resource:  "subscriptions",
+			Name:      config.Name,
+			Namespace: config.Namespace,
+		},
+		FeedbackRules: []workv1.FeedbackRule{{
+			Type:      workv1.JSONPathsType,
+			JsonPaths: subscriptionFeedbackPaths(),
+		}},
+	}}
+	if config.Catalog != nil {
+		configs = append(configs, workv1.ManifestConfigOption{
+			ResourceIdentifier: workv1.ResourceIdentifier{
+				Group:     "operators.coreos.com",
+				Resource:  "catalogsources",
+				Name:      config.Source,
+				Namespace: config.SourceNamespace,
+			},
+			FeedbackRules: []workv1.FeedbackRule{{
+				Type:      workv1.JSONPathsType,
+				JsonPaths: catalogSourceFeedbackPaths(),
+			}},
+		})
+	}
+	return configs
+}
+
+// desiredOperatorManifests returns the manifests of the operator ManifestWork for the install mode of an operator
+// configuration of the mesh.
+func (r *Reconciler) desiredOperatorManifests(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, config meshv1alpha1.OperatorConfig) ([]workv1.Manifest, error) {
+	if config.InstallMode != meshv1alpha1.OperatorInstallModeManifests {
+		return buildOLMOperatorManifests(config), nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, key.Of(config.Manifests.ConfigMapName, mesh.Namespace), configMap); err != nil {
+		return nil, fmt.Errorf("failed to get operator manifests ConfigMap %s/%s: %w", mesh.Namespace, config.Manifests.ConfigMapName, err)
+	}
+	manifests, err := parseOperatorManifests(configMap, config.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse operator manifests ConfigMap %s/%s: %w", mesh.Namespace, config.Manifests.ConfigMapName, err)
+	}
+	return manifests, nil
+}
+
+// parseOperatorManifests parses the YAML documents of every ConfigMap key, in the order of the keys.
+// The operator namespace is created first unless the manifests already contain it.
+func parseOperatorManifests(configMap *corev1.ConfigMap, operatorNamespace string) ([]workv1.Manifest, error) {
+	var manifests []workv1.Manifest
+	hasNamespace := false
+	for _, name := range slices.Sorted(maps.Keys(configMap.Data)) {
+		decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(configMap.Data[name]), 4096)
+		for {
+			obj := &unstructured.Unstructured{}
+			if err := decoder.Decode(&obj.Object); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("invalid manifest in key %s: %w", name, err)
+			}
+			if len(obj.Object) == 0 {
+				continue
+			}
+			if obj.GetAPIVersion() == "" || obj.GetKind() == "" || obj.GetName() == "" {
+				return nil, fmt.Errorf("manifest in key %s lacks apiVersion, kind or metadata.name", name)
+			}
+			if obj.GetAPIVersion() == "v1" && obj.GetKind() == "Namespace" && obj.GetName() == operatorNamespace {
+				hasNamespace = true
+			}
+			raw, err := obj.MarshalJSON()
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode manifest %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+			manifests = append(manifests, workv1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}})
+		}
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no manifests found")
+	}
+
+	if !hasNamespace {
+		namespace := &unstructured.Unstructured{}
+		namespace.SetAPIVersion("v1")
+		namespace.SetKind("Namespace")
+		namespace.SetName(operatorNamespace)
+		raw, err := namespace.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode operator namespace: %w", err)
+		}
+		manifests = append([]workv1.Manifest{{RawExtension: runtime.RawExtension{Raw: raw}}}, manifests...)
+	}
+	return manifests, nil
+}
+
+// operatorInstallStatus derives the OperatorInstalled condition from the feedback of the operator ManifestWork
+// for the install mode of the operator configuration.
+func operatorInstallStatus(config meshv1alpha1.OperatorConfig, feedback map[string]string) (status metav1.ConditionStatus, reason, message string) {
+	if config.InstallMode == meshv1alpha1.OperatorInstallModeManifests {
+		return deploymentInstallState(feedback, config.Manifests.DeploymentName)
+	}
+	return operatorInstallState(feedback, config.GetApprovedCSV())
+}
+
+// deploymentInstallState derives the OperatorInstalled condition from the operator Deployment feedback.
+// The operator is installed once every replica of the Deployment is updated and ready.
+func deploymentInstallState(feedback map[string]string, deploymentName string) (status metav1.ConditionStatus, reason, message string) {
+	replicas, err := strconv.Atoi(feedback[FeedbackReplicas])
+	if err != nil {
+		return metav1.ConditionFalse, meshv1alpha1.ReasonInstallationPending,
+			fmt.Sprintf("Operator deployment %s is not reported yet", deploymentName)
+	}
+	ready, _ := strconv.Atoi(feedback[FeedbackReadyReplicas])
+	updated, err := strconv.Atoi(feedback[FeedbackUpdatedReplicas])
+	if err != nil {
+		updated = replicas
+	}
+
+	if replicas > 0 && ready == replicas && updated == replicas {
+		return metav1.ConditionTrue, meshv1alpha1.ReasonOperatorInstalled, "Operator installed: " + feedback[FeedbackOperatorImage]
+	}
+	return metav1.ConditionFalse, meshv1alpha1.ReasonInstallationPending,
+		fmt.Sprintf("Operator deployment %s has %d of %d replicas ready, %d updated", deploymentName, ready, replicas, updated)
+}
+
+// installedOperatorVersion returns the operator version installed on a cluster, taken from the installed CSV in the
+// OLM install mode and from the image tag of the operator Deployment in the Manifests install mode.
+func installedOperatorVersion(config meshv1alpha1.OperatorConfig, feedback map[string]string) string {
+	if config.InstallMode == meshv1alpha1.OperatorInstallModeManifests {
+		return imageTag(feedback[FeedbackOperatorImage])
+	}
+	return csvVersion(feedback[FeedbackInstalledCSV])
+}
+
+// imageTag returns the tag of an image reference without digest, or the reference itself if it has no tag.
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return image
+}
+
+// operatorConfigMapIndex indexes meshes by the ConfigMaps their operator configurations install the operator from.
+const operatorConfigMapIndex = "spec.operator.manifests.configMapName"
+
+// operatorConfigMapNames returns the names of the ConfigMaps a mesh installs the operator from, for operatorConfigMapIndex.
+func operatorConfigMapNames(obj client.Object) []string {
+	var names []string
+	for _, config := range operatorConfigs(obj.(*meshv1alpha1.MultiClusterMesh)) {
+		if config.InstallMode == meshv1alpha1.OperatorInstallModeManifests && !slices.Contains(names, config.Manifests.ConfigMapName) {
+			names = append(names, config.Manifests.ConfigMapName)
+		}
+	}
+	return names
+}
+
+// isOperatorConfigMap reports whether a mesh in the namespace of a ConfigMap installs the operator from it,
+// so that changes to any other ConfigMap of the hub are filtered out before they are mapped to meshes.
+func (r *Reconciler) isOperatorConfigMap(obj client.Object) bool {
+	return len(r.findMeshesForConfigMap(context.Background(), obj)) > 0
+}
+
+// findMeshesForConfigMap returns the meshes in the namespace of a ConfigMap that install the operator from it
+func (r *Reconciler) findMeshesForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	meshes := &meshv1alpha1.MultiClusterMeshList{}
+	if err := r.List(ctx, meshes, client.InNamespace(obj.GetNamespace()), client.MatchingFields{operatorConfigMapIndex: obj.GetName()}); err != nil {
+		klog.Errorf("Failed to list meshes for ConfigMap %s/%s: %v", obj.GetNamespace(), obj.GetName(), err)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, mesh := range meshes.Items {
+		klog.V(4).Infof("ConfigMap %s/%s triggered reconcile for mesh %s/%s", obj.GetNamespace(), obj.GetName(), mesh.Namespace, mesh.Name)
+		requests = append(requests, reconcile.Request{NamespacedName: key.For(&mesh)})
+	}
+	return requests
+}
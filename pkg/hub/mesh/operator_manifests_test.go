@@ -0,0 +1,178 @@
+package mesh
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestParseOperatorManifests(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          map[string]string
+		expectedKinds []string
+		expectedError bool
+	}{
+		{
+			name: "prepends the operator namespace",
+			data: map[string]string{
+				"2-deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: sail-operator\n  namespace: sail-operator\n",
+				"1-rbac.yaml": "---\napiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: sail-operator\n  namespace: sail-operator\n" +
+					"---\n# comment only\n---\napiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: sail-operator\n",
+			},
+			expectedKinds: []string{"Namespace/sail-operator", "ServiceAccount/sail-operator", "ClusterRole/sail-operator", "Deployment/sail-operator"},
+		},
+		{
+			name: "keeps the operator namespace of the manifests",
+			data: map[string]string{
+				"manifests.yaml": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: sail-operator\n  labels:\n    a: b\n" +
+					"---\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: sail-operator\n  namespace: sail-operator\n",
+			},
+			expectedKinds: []string{"Namespace/sail-operator", "Deployment/sail-operator"},
+		},
+		{
+			name:          "rejects an empty ConfigMap",
+			data:          map[string]string{"manifests.yaml": "---\n"},
+			expectedError: true,
+		},
+		{
+			name:          "rejects manifests without kind",
+			data:          map[string]string{"manifests.yaml": "apiVersion: v1\nmetadata:\n  name: sail-operator\n"},
+			expectedError: true,
+		},
+		{
+			name:          "rejects invalid YAML",
+			data:          map[string]string{"manifests.yaml": "apiVersion: v1\nkind: [\n"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := parseOperatorManifests(&corev1.ConfigMap{Data: tc.data}, "sail-operator")
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("parseOperatorManifests() error = %v, want error %v", err, tc.expectedError)
+			}
+			if len(manifests) != len(tc.expectedKinds) {
+				t.Fatalf("parseOperatorManifests() returned %d manifests, want %d", len(manifests), len(tc.expectedKinds))
+			}
+			for i, manifest := range manifests {
+				obj := &metav1.PartialObjectMetadata{}
+				if err := json.Unmarshal(manifest.Raw, obj); err != nil {
+					t.Fatalf("manifest %d is not valid JSON: %v", i, err)
+				}
+				if got := obj.Kind + "/" + obj.Name; got != tc.expectedKinds[i] {
+					t.Errorf("manifest %d = %s, want %s", i, got, tc.expectedKinds[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDeploymentInstallState(t *testing.T) {
+	tests := []struct {
+		name           string
+		feedback       map[string]string
+		expectedStatus metav1.ConditionStatus
+		expectedReason string
+	}{
+		{
+			name:           "no feedback",
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: meshv1alpha1.ReasonInstallationPending,
+		},
+		{
+			name:           "ready",
+			feedback:       map[string]string{FeedbackReplicas: "1", FeedbackReadyReplicas: "1", FeedbackUpdatedReplicas: "1"},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: meshv1alpha1.ReasonOperatorInstalled,
+		},
+		{
+			name:           "not ready",
+			feedback:       map[string]string{FeedbackReplicas: "2", FeedbackReadyReplicas: "1", FeedbackUpdatedReplicas: "2"},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: meshv1alpha1.ReasonInstallationPending,
+		},
+		{
+			name:           "rolling out",
+			feedback:       map[string]string{FeedbackReplicas: "1", FeedbackReadyReplicas: "1", FeedbackUpdatedReplicas: "0"},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: meshv1alpha1.ReasonInstallationPending,
+		},
+		{
+			name:           "scaled to zero",
+			feedback:       map[string]string{FeedbackReplicas: "0", FeedbackReadyReplicas: "0"},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: meshv1alpha1.ReasonInstallationPending,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, reason, _ := deploymentInstallState(tc.feedback, "sail-operator")
+			if status != tc.expectedStatus {
+				t.Errorf("deploymentInstallState() status = %q, want %q", status, tc.expectedStatus)
+			}
+			if reason != tc.expectedReason {
+				t.Errorf("deploymentInstallState() reason = %q, want %q", reason, tc.expectedReason)
+			}
+		})
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	tests := map[string]string{
+		"quay.io/sail-dev/sail-operator:1.27.0":             "1.27.0",
+		"localhost:5000/sail-operator:1.27.1":               "1.27.1",
+		"localhost:5000/sail-operator":                      "localhost:5000/sail-operator",
+		"quay.io/sail-dev/sail-operator:1.27.0@sha256:abcd": "1.27.0",
+	}
+
+	for image, expectedTag := range tests {
+		t.Run(image, func(t *testing.T) {
+			if tag := imageTag(image); tag != expectedTag {
+				t.Errorf("imageTag() = %s, want %s", tag, expectedTag)
+			}
+		})
+	}
+}
+
+func TestIsOperatorConfigMap(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = meshv1alpha1.Install(scheme)
+
+	manifestsMesh := meshWith("mesh-ns", "manifests", metav1.Now())
+	manifestsMesh.Spec.Operator.InstallMode = meshv1alpha1.OperatorInstallModeManifests
+	manifestsMesh.Spec.Operator.Manifests.ConfigMapName = "operator-manifests"
+	olmMesh := meshWith("mesh-ns", "olm", metav1.Now())
+	olmMesh.Spec.Operator.Manifests.ConfigMapName = "unused-manifests"
+
+	r := &Reconciler{Client: fake.NewClientBuilder().WithScheme(scheme).
+		WithIndex(&meshv1alpha1.MultiClusterMesh{}, operatorConfigMapIndex, operatorConfigMapNames).
+		WithObjects(manifestsMesh, olmMesh).Build()}
+
+	tests := []struct {
+		namespace, name string
+		expected        bool
+	}{
+		{namespace: "mesh-ns", name: "operator-manifests", expected: true},
+		{namespace: "other-ns", name: "operator-manifests"},
+		{namespace: "mesh-ns", name: "unused-manifests"},
+		{namespace: "mesh-ns", name: "kube-root-ca.crt"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.namespace+"/"+tc.name, func(t *testing.T) {
+			configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: tc.name, Namespace: tc.namespace}}
+			if got := r.isOperatorConfigMap(configMap); got != tc.expected {
+				t.Errorf("isOperatorConfigMap() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package mesh
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestOperatorInstallState(t *testing.T) {
+	tests := []struct {
+		name            string
+		feedback        map[string]string
+		approvedCSV     string
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "no feedback",
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: meshv1alpha1.ReasonInstallationPending,
+		},
+		{
+			name: "installed",
+			feedback: map[string]string{
+				FeedbackInstalledCSV:      "sailoperator.v1.0.0",
+				FeedbackSubscriptionState: "AtLatestKnown",
+			},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  meshv1alpha1.ReasonOperatorInstalled,
+			expectedMessage: "Operator installed: sailoperator.v1.0.0",
+		},
+		{
+			name: "upgrade failed",
+			feedback: map[string]string{
+				FeedbackInstalledCSV:      "sailoperator.v1.0.0",
+				FeedbackCurrentCSV:        "sailoperator.v1.1.0",
+				FeedbackSubscriptionState: "UpgradeFailed",
+			},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  meshv1alpha1.ReasonUpgradeFailed,
+			expectedMessage: "Operator installed: sailoperator.v1.0.0, upgrade to sailoperator.v1.1.0 failed",
+		},
+		{
+			name: "resolution failed",
+			feedback: map[string]string{
+				FeedbackResolutionFailed:        "True",
+				FeedbackResolutionFailedMessage: "constraints not satisfiable",
+				FeedbackCatalogSourcesUnhealthy: "True",
+			},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  meshv1alpha1.ReasonResolutionFailed,
+			expectedMessage: "OLM failed to resolve the Subscription: constraints not satisfiable",
+		},
+		{
+			name: "catalog unhealthy",
+			feedback: map[string]string{
+				FeedbackCatalogSourcesUnhealthy:        "True",
+				FeedbackCatalogSourcesUnhealthyMessage: "targeted catalogsource openshift-marketplace/redhat-operators missing",
+			},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  meshv1alpha1.ReasonCatalogUnhealthy,
+			expectedMessage: "CatalogSources of the Subscription are unhealthy: targeted catalogsource openshift-marketplace/redhat-operators missing",
+		},
+		{
+			name: "healthy catalog",
+			feedback: map[string]string{
+				FeedbackCatalogSourcesUnhealthy: "False",
+			},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: meshv1alpha1.ReasonInstallationPending,
+		},
+		{
+			name: "install plan failed",
+			feedback: map[string]string{
+				FeedbackInstallPlanName:          "install-abcde",
+				FeedbackInstallPlanFailed:        "True",
+				FeedbackInstallPlanFailedMessage: "install strategy failed",
+			},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  meshv1alpha1.ReasonInstallPlanFailed,
+			expectedMessage: "InstallPlan install-abcde failed: install strategy failed",
+		},
+		{
+			name: "install plan requires approval",
+			feedback: map[string]string{
+				FeedbackCurrentCSV:         "sailoperator.v1.0.0",
+				FeedbackInstallPlanName:    "install-abcde",
+				FeedbackInstallPlanPending: "True",
+				FeedbackInstallPlanPhase:   "RequiresApproval",
+			},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  meshv1alpha1.ReasonAwaitingApproval,
+			expectedMessage: "InstallPlan install-abcde for sailoperator.v1.0.0 requires approval",
+		},
+		{
+			name: "install plan for another version than approved",
+			feedback: map[string]string{
+				FeedbackCurrentCSV:         "sailoperator.v1.1.0",
+				FeedbackInstallPlanName:    "install-abcde",
+				FeedbackInstallPlanPending: "True",
+				FeedbackInstallPlanPhase:   "RequiresApproval",
+			},
+			approvedCSV:     "sailoperator.v1.0.0",
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  meshv1alpha1.ReasonAwaitingApproval,
+			expectedMessage: "InstallPlan install-abcde for sailoperator.v1.1.0 requires approval, only sailoperator.v1.0.0 is approved",
+		},
+		{
+			name: "install plan for the approved version",
+			feedback: map[string]string{
+				FeedbackCurrentCSV:         "sailoperator.v1.0.0",
+				FeedbackInstallPlanName:    "install-abcde",
+				FeedbackInstallPlanPending: "True",
+				FeedbackInstallPlanPhase:   "RequiresApproval",
+			},
+			approvedCSV:     "sailoperator.v1.0.0",
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  meshv1alpha1.ReasonInstallationPending,
+			expectedMessage: "InstallPlan install-abcde for sailoperator.v1.0.0 is approved",
+		},
+		{
+			name: "install plan installing",
+			feedback: map[string]string{
+				FeedbackCurrentCSV:         "sailoperator.v1.0.0",
+				FeedbackInstallPlanName:    "install-abcde",
+				FeedbackInstallPlanPending: "True",
+				FeedbackInstallPlanPhase:   "Installing",
+			},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  meshv1alpha1.ReasonInstallationPending,
+			expectedMessage: "InstallPlan install-abcde for sailoperator.v1.0.0 is Installing",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, reason, message := operatorInstallState(tc.feedback, tc.approvedCSV)
+			if status != tc.expectedStatus {
+				t.Errorf("operatorInstallState() status = %q, want %q", status, tc.expectedStatus)
+			}
+			if reason != tc.expectedReason {
+				t.Errorf("operatorInstallState() reason = %q, want %q", reason, tc.expectedReason)
+			}
+			if tc.expectedMessage != "" && message != tc.expectedMessage {
+				t.Errorf("operatorInstallState() message = %q, want %q", message, tc.expectedMessage)
+			}
+		})
+	}
+}
+
+func TestBuildInstallPlanApprovalManifestWork(t *testing.T) {
+	mesh := meshWith("mesh-ns", "mesh", metav1.Now())
+	work := buildInstallPlanApprovalManifestWork(mesh, "cluster1", "operator-ns", "install-abcde")
+	if len(work.Spec.Workload.Manifests) != 1 {
+		t.Fatalf("expected 1 manifest, got %d", len(work.Spec.Workload.Manifests))
+	}
+
+	raw, err := json.Marshal(work.Spec.Workload.Manifests[0])
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	var manifest map[string]interface{}
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		t.Fatalf("failed to unmarshal manifest: %v", err)
+	}
+
+	// Only the approval may be applied, any other field would conflict with the fields owned by OLM.
+	expected := map[string]interface{}{
+		"apiVersion": "operators.coreos.com/v1alpha1",
+		"kind":       "InstallPlan",
+		"metadata":   map[string]interface{}{"name": "install-abcde", "namespace": "operator-ns"},
+		"spec":       map[string]interface{}{"approved": true},
+	}
+	if !reflect.DeepEqual(manifest, expected) {
+		t.Errorf("manifest = %s, want only apiVersion, kind, metadata and spec.approved", raw)
+	}
+}
+
+func TestCSVVersion(t *testing.T) {
+	tests := []struct {
+		csv             string
+		expectedVersion string
+	}{
+		{csv: "servicemeshoperator3.v3.0.0", expectedVersion: "3.0.0"},
+		{csv: "sailoperator.v1.27.1", expectedVersion: "1.27.1"},
+		{csv: "sailoperator.v1.28.0-nightly-2025-11-04", expectedVersion: "1.28.0-nightly-2025-11-04"},
+		{csv: "sailoperator.latest", expectedVersion: "sailoperator.latest"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.csv, func(t *testing.T) {
+			if version := csvVersion(tc.csv); version != tc.expectedVersion {
+				t.Errorf("csvVersion() = %s, want %s", version, tc.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestDetermineVersionConsistency(t *testing.T) {
+	tests := []struct {
+		name              string
+		installedVersions map[string]string
+		policy            *meshv1alpha1.VersionSkewPolicy
+		expectedStatus    metav1.ConditionStatus
+		expectedReason    string
+		expectedMessage   string
+	}{
+		{
+			name:           "no installed operator",
+			expectedStatus: metav1.ConditionUnknown,
+			expectedReason: meshv1alpha1.ReasonVersionUnknown,
+		},
+		{
+			name:              "same version",
+			installedVersions: map[string]string{"a": "1.27.0", "b": "1.27.0"},
+			expectedStatus:    metav1.ConditionTrue,
+			expectedReason:    meshv1alpha1.ReasonVersionsConsistent,
+			expectedMessage:   "Clusters run operator versions within the allowed skew: 1.27.0 (a, b)",
+		},
+		{
+			name:              "patch skew",
+			installedVersions: map[string]string{"a": "1.27.1", "b": "1.27.0", "c": "1.27.1"},
+			expectedStatus:    metav1.ConditionFalse,
+			expectedReason:    meshv1alpha1.ReasonVersionSkew,
+			expectedMessage:   "Clusters run operator versions 1.27.0 and 1.27.1: 1.27.0 (b), 1.27.1 (a, c)",
+		},
+		{
+			name:              "ignored patch skew",
+			installedVersions: map[string]string{"a": "1.27.1", "b": "1.27.0"},
+			policy:            &meshv1alpha1.VersionSkewPolicy{IgnorePatchVersions: true},
+			expectedStatus:    metav1.ConditionTrue,
+			expectedReason:    meshv1alpha1.ReasonVersionsConsistent,
+		},
+		{
+			name:              "minor skew within the allowed skew",
+			installedVersions: map[string]string{"a": "1.27.1", "b": "1.26.3"},
+			policy:            &meshv1alpha1.VersionSkewPolicy{MaxMinorVersions: 1},
+			expectedStatus:    metav1.ConditionTrue,
+			expectedReason:    meshv1alpha1.ReasonVersionsConsistent,
+		},
+		{
+			name:              "minor skew beyond the allowed skew",
+			installedVersions: map[string]string{"a": "1.27.0", "b": "1.25.0"},
+			policy:            &meshv1alpha1.VersionSkewPolicy{MaxMinorVersions: 1},
+			expectedStatus:    metav1.ConditionFalse,
+			expectedReason:    meshv1alpha1.ReasonVersionSkew,
+			expectedMessage:   "Clusters run operator versions 2 minor versions apart, at most 1 allowed: 1.25.0 (b), 1.27.0 (a)",
+		},
+		{
+			name:              "major skew",
+			installedVersions: map[string]string{"a": "3.0.0", "b": "2.6.0"},
+			policy:            &meshv1alpha1.VersionSkewPolicy{MaxMinorVersions: 10},
+			expectedStatus:    metav1.ConditionFalse,
+			expectedReason:    meshv1alpha1.ReasonVersionSkew,
+		},
+		{
+			name:              "unparseable version",
+			installedVersions: map[string]string{"a": "1.27.0", "b": "latest"},
+			expectedStatus:    metav1.ConditionUnknown,
+			expectedReason:    meshv1alpha1.ReasonVersionUnknown,
+			expectedMessage:   "Cannot determine the operator version of clusters b",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mesh := &meshv1alpha1.MultiClusterMesh{Spec: meshv1alpha1.MultiClusterMeshSpec{VersionSkew: tc.policy}}
+			determineVersionConsistency(mesh, tc.installedVersions)
+			condition := meta.FindStatusCondition(mesh.Status.Conditions, meshv1alpha1.ConditionVersionConsistent)
+			if condition == nil {
+				t.Fatalf("determineVersionConsistency() did not set the %s condition", meshv1alpha1.ConditionVersionConsistent)
+			}
+			if condition.Status != tc.expectedStatus {
+				t.Errorf("determineVersionConsistency() status = %q, want %q", condition.Status, tc.expectedStatus)
+			}
+			if condition.Reason != tc.expectedReason {
+				t.Errorf("determineVersionConsistency() reason = %q, want %q", condition.Reason, tc.expectedReason)
+			}
+			if tc.expectedMessage != "" && condition.Message != tc.expectedMessage {
+				t.Errorf("determineVersionConsistency() message = %q, want %q", condition.Message, tc.expectedMessage)
+			}
+		})
+	}
+}
+
+func TestCatalogSourceState(t *testing.T) {
+	config := meshv1alpha1.OperatorConfig{
+		Source:          "mirrored-operators",
+		SourceNamespace: "openshift-marketplace",
+		Catalog:         &meshv1alpha1.OperatorCatalog{Image: "mirror.example.com/redhat-operator-index:v4.18"},
+	}
+
+	tests := []struct {
+		name            string
+		feedback        map[string]string
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:           "no feedback",
+			expectedStatus: metav1.ConditionUnknown,
+			expectedReason: meshv1alpha1.ReasonCatalogConnecting,
+		},
+		{
+			name:            "ready",
+			feedback:        map[string]string{FeedbackCatalogSourceState: "READY"},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  meshv1alpha1.ReasonCatalogReady,
+			expectedMessage: "CatalogSource openshift-marketplace/mirrored-operators is connected",
+		},
+		{
+			name:            "connecting",
+			feedback:        map[string]string{FeedbackCatalogSourceState: "CONNECTING"},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  meshv1alpha1.ReasonCatalogConnecting,
+			expectedMessage: "CatalogSource openshift-marketplace/mirrored-operators is CONNECTING",
+		},
+		{
+			name: "transient failure",
+			feedback: map[string]string{
+				FeedbackCatalogSourceState:   "TRANSIENT_FAILURE",
+				FeedbackCatalogSourceMessage: "image pull failed",
+			},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: meshv1alpha1.ReasonCatalogUnhealthy,
+			expectedMessage: "CatalogSource openshift-marketplace/mirrored-operators cannot connect to " +
+				"mirror.example.com/redhat-operator-index:v4.18: image pull failed",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, reason, message := catalogSourceState(tc.feedback, config)
+			if status != tc.expectedStatus {
+				t.Errorf("catalogSourceState() status = %q, want %q", status, tc.expectedStatus)
+			}
+			if reason != tc.expectedReason {
+				t.Errorf("catalogSourceState() reason = %q, want %q", reason, tc.expectedReason)
+			}
+			if tc.expectedMessage != "" && message != tc.expectedMessage {
+				t.Errorf("catalogSourceState() message = %q, want %q", message, tc.expectedMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package mesh
+
+import (
+	"slices"
+
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+// Names of the ClusterClaims the klusterlet reports about the platform of a managed cluster.
+const (
+	ClusterClaimProduct     = "product.open-cluster-management.io"
+	ClusterClaimPlatform    = "platform.open-cluster-management.io"
+	ClusterClaimKubeVersion = "kubeversion.open-cluster-management.io"
+)
+
+// clusterOperatorConfig returns the operator configuration of a cluster, taken from the first operator profile
+// matching the cluster's platform, and the name of that profile. Clusters matching no profile use spec.operator.
+func clusterOperatorConfig(mesh *meshv1alpha1.MultiClusterMesh, cluster *clusterv1.ManagedCluster) (config meshv1alpha1.OperatorConfig, profile string) {
+	for _, p := range mesh.Spec.OperatorProfiles {
+		if matchesPlatform(p.Selector, cluster) {
+			return p.Operator, p.Name
+		}
+	}
+	return mesh.Spec.Operator, ""
+}
+
+// operatorConfigs returns every operator configuration a mesh may install, spec.operator first.
+func operatorConfigs(mesh *meshv1alpha1.MultiClusterMesh) []meshv1alpha1.OperatorConfig {
+	configs := []meshv1alpha1.OperatorConfig{mesh.Spec.Operator}
+	for _, p := range mesh.Spec.OperatorProfiles {
+		configs = append(configs, p.Operator)
+	}
+	return configs
+}
+
+// matchesPlatform reports whether a cluster matches every criterion set in a platform selector.
+// A cluster that does not report a ClusterClaim only matches selectors that don't use it. A MinKubeVersion that
+// cannot be parsed, which validate reports, matches no cluster.
+func matchesPlatform(selector meshv1alpha1.PlatformSelector, cluster *clusterv1.ManagedCluster) bool {
+	if len(selector.Products) > 0 && !slices.Contains(selector.Products, clusterClaim(cluster, ClusterClaimProduct)) {
+		return false
+	}
+	if len(selector.Platforms) > 0 && !slices.Contains(selector.Platforms, clusterClaim(cluster, ClusterClaimPlatform)) {
+		return false
+	}
+	if selector.MinKubeVersion != "" {
+		minVersion, err := utilversion.ParseGeneric(selector.MinKubeVersion)
+		if err != nil {
+			return false
+		}
+		kubeVersion, err := utilversion.ParseGeneric(clusterClaim(cluster, ClusterClaimKubeVersion))
+		if err != nil || kubeVersion.LessThan(minVersion) {
+			return false
+		}
+	}
+	return true
+}
+
+// clusterClaim returns the value of a ClusterClaim reported by a cluster, or an empty string.
+func clusterClaim(cluster *clusterv1.ManagedCluster, name string) string {
+	for _, claim := range cluster.Status.ClusterClaims {
+		if claim.Name == name {
+			return claim.Value
+		}
+	}
+	return ""
+}
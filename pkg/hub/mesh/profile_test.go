@@ -0,0 +1,87 @@
+package mesh
+
+import (
+	"testing"
+
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestClusterOperatorConfig(t *testing.T) {
+	cluster := func(claims ...string) *clusterv1.ManagedCluster {
+		c := &clusterv1.ManagedCluster{}
+		for i := 0; i < len(claims); i += 2 {
+			c.Status.ClusterClaims = append(c.Status.ClusterClaims, clusterv1.ManagedClusterClaim{Name: claims[i], Value: claims[i+1]})
+		}
+		return c
+	}
+	mesh := &meshv1alpha1.MultiClusterMesh{Spec: meshv1alpha1.MultiClusterMeshSpec{
+		Operator: meshv1alpha1.OperatorConfig{Name: "default"},
+		OperatorProfiles: []meshv1alpha1.OperatorProfile{
+			{
+				Name:     "openshift",
+				Selector: meshv1alpha1.PlatformSelector{Products: []string{"OpenShift", "ROSA"}},
+				Operator: meshv1alpha1.OperatorConfig{Name: "servicemeshoperator3"},
+			},
+			{
+				Name:     "eks-recent",
+				Selector: meshv1alpha1.PlatformSelector{Products: []string{"EKS"}, Platforms: []string{"AWS"}, MinKubeVersion: "v1.30"},
+				Operator: meshv1alpha1.OperatorConfig{Name: "sailoperator-eks"},
+			},
+			{
+				Name:     "recent",
+				Selector: meshv1alpha1.PlatformSelector{MinKubeVersion: "1.29"},
+				Operator: meshv1alpha1.OperatorConfig{Name: "sailoperator"},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name            string
+		cluster         *clusterv1.ManagedCluster
+		expectedName    string
+		expectedProfile string
+	}{
+		{
+			name:            "matches a product",
+			cluster:         cluster(ClusterClaimProduct, "ROSA", ClusterClaimKubeVersion, "v1.31.0"),
+			expectedName:    "servicemeshoperator3",
+			expectedProfile: "openshift",
+		},
+		{
+			name:            "matches every criterion",
+			cluster:         cluster(ClusterClaimProduct, "EKS", ClusterClaimPlatform, "AWS", ClusterClaimKubeVersion, "v1.30.4-eks-a737599"),
+			expectedName:    "sailoperator-eks",
+			expectedProfile: "eks-recent",
+		},
+		{
+			name:            "falls through to the next profile",
+			cluster:         cluster(ClusterClaimProduct, "EKS", ClusterClaimPlatform, "AWS", ClusterClaimKubeVersion, "v1.29.8"),
+			expectedName:    "sailoperator",
+			expectedProfile: "recent",
+		},
+		{
+			name:         "uses spec.operator when no profile matches",
+			cluster:      cluster(ClusterClaimProduct, "Other", ClusterClaimKubeVersion, "v1.28.0"),
+			expectedName: "default",
+		},
+		{
+			name:         "uses spec.operator without claims",
+			cluster:      cluster(),
+			expectedName: "default",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config, profile := clusterOperatorConfig(mesh, tc.cluster)
+			if config.Name != tc.expectedName {
+				t.Errorf("clusterOperatorConfig() config name = %s, want %s", config.Name, tc.expectedName)
+			}
+			if profile != tc.expectedProfile {
+				t.Errorf("clusterOperatorConfig() profile = %s, want %s", profile, tc.expectedProfile)
+			}
+		})
+	}
+}
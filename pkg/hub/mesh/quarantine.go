@@ -0,0 +1,344 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	msav1beta1 "open-cluster-management.io/managed-serviceaccount/apis/authentication/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+)
+
+// AnnotationQuarantine quarantines a ManagedCluster: every mesh revokes the credentials that let the cluster take part in
+// the mesh, while the cluster stays in the ClusterSet and keeps the resources already applied to it.
+const AnnotationQuarantine = "mesh.open-cluster-management.io/quarantine"
+
+// ManifestWorkNamePurgePrefix prefixes the name of the ManifestWork clearing the remote secrets that the quarantine of a
+// cluster left on a cluster, followed by the name of the quarantined cluster.
+const ManifestWorkNamePurgePrefix = "multicluster-mesh-purge-"
+
+// quarantined reports whether a ManagedCluster is quarantined.
+func quarantined(cluster *clusterv1.ManagedCluster) bool {
+	return cluster.Annotations[AnnotationQuarantine] == "true"
+}
+
+// quarantinedClusterNames returns the names of the quarantined clusters.
+func quarantinedClusterNames(clusters []clusterv1.ManagedCluster) []string {
+	var names []string
+	for _, cluster := range clusters {
+		if quarantined(&cluster) {
+			names = append(names, cluster.Name)
+		}
+	}
+	return names
+}
+
+// quarantineClusters revokes the credentials of the quarantined clusters and records each confirmed revocation in their
+// status. It runs even while the mesh is suspended, since it only removes trust: the revocation list is also delivered
+// to the clusters whose changes are held.
+func (r *Reconciler) quarantineClusters(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster, revocation *revocationList) error {
+	maintenance, err := r.clustersInMaintenance(ctx)
+	if err != nil {
+		return err
+	}
+	if revocation.problem == "" {
+		for _, cluster := range clusters {
+			if quarantined(&cluster) || !mesh.Spec.Suspend && !maintenance[cluster.Name] {
+				continue
+			}
+			if err := r.distributeRevocationList(ctx, cluster.Name, revocation.pem); err != nil {
+				return err
+			}
+		}
+	}
+
+	excluded := quarantinedClusterNames(clusters)
+	if len(excluded) > 0 {
+		// The Placements of the peers stop selecting the quarantined clusters, so their remote secrets are withdrawn.
+		placementList := &clusterv1beta1.PlacementList{}
+		if err := r.List(ctx, placementList, client.InNamespace(mesh.Namespace),
+			client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}); err != nil {
+			return fmt.Errorf("failed to list Placements: %w", err)
+		}
+		for _, placement := range placementList.Items {
+			source := placement.Labels[ClusterNameLabel]
+			if source == "" || slices.Contains(excluded, source) {
+				continue
+			}
+			// Clusters already excluded, such as those not past the readiness gate, stay excluded.
+			placementExcluded := slices.Clone(excluded)
+			for _, predicate := range placement.Spec.Predicates {
+				for _, requirement := range predicate.RequiredClusterSelector.LabelSelector.MatchExpressions {
+					if requirement.Key == clusterv1.ClusterNameLabelKey && requirement.Operator == metav1.LabelSelectorOpNotIn {
+						placementExcluded = append(placementExcluded, slices.DeleteFunc(slices.Clone(requirement.Values), func(name string) bool {
+							return name == source
+						})...)
+					}
+				}
+			}
+			slices.Sort(placementExcluded)
+			if err := r.ensureRemoteSecretPlacement(ctx, mesh, source, slices.Compact(placementExcluded)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, cluster := range clusters {
+		if !quarantined(&cluster) {
+			mesh.SetClusterQuarantine(cluster.Name, nil)
+			continue
+		}
+		var peers, trusting []string
+		for _, peer := range clusters {
+			if peer.Name == cluster.Name {
+				continue
+			}
+			peers = append(peers, peer.Name)
+			if !quarantined(&peer) {
+				trusting = append(trusting, peer.Name)
+			}
+		}
+		quarantine, err := r.quarantineCluster(ctx, mesh, cluster.Name, peers, trusting, revocation, mesh.GetClusterQuarantine(cluster.Name))
+		if err != nil {
+			return fmt.Errorf("failed to quarantine cluster %s: %w", cluster.Name, err)
+		}
+		mesh.SetClusterQuarantine(cluster.Name, quarantine)
+	}
+	return r.cleanupPurgeManifestWorks(ctx, mesh, excluded)
+}
+
+// quarantineCluster revokes the remote secret of the cluster from its peers, the remote secrets of the peers from the
+// cluster, its ManagedServiceAccount, its intermediate CA Certificate, and the trust of the peers that are not
+// quarantined in its intermediate CA. It returns the previous quarantine status updated with the time of the
+// revocations confirmed since.
+//
+// The remote secrets are removed regardless of the deletion policy: when their ManifestWorks orphan them, they are
+// only revoked once purged by purgeRemoteSecrets.
+func (r *Reconciler) quarantineCluster(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string, peers, trusting []string, revocation *revocationList, previous *meshv1alpha1.ClusterQuarantineStatus) (*meshv1alpha1.ClusterQuarantineStatus, error) {
+	now := metav1.Now()
+	quarantine := &meshv1alpha1.ClusterQuarantineStatus{Since: now}
+	if previous != nil {
+		quarantine = previous.DeepCopy()
+	} else {
+		klog.Infof("Quarantining cluster %s in MultiClusterMesh %s/%s", clusterName, mesh.Namespace, mesh.Name)
+	}
+	record := func(revoked **metav1.Time, waiting []string) {
+		switch {
+		case len(waiting) > 0:
+			*revoked = nil
+		case *revoked == nil:
+			*revoked = &now
+		}
+	}
+
+	orphaned := deletionPolicy(mesh, mesh.Spec.DeletionPolicy.RemoteSecrets) == meshv1alpha1.DeletionPolicyOrphan
+
+	// The remote secret of a quarantined cluster is revoked from the peers in maintenance as well.
+	waiting, err := r.revokeRemoteSecret(ctx, mesh, clusterName, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(waiting) == 0 && orphaned {
+		for _, peer := range peers {
+			purging, err := r.purgeRemoteSecrets(ctx, mesh, clusterName, peer, []string{clusterName}, quarantine.RemoteSecretRevokedTime != nil)
+			if err != nil {
+				return nil, err
+			}
+			waiting = append(waiting, purging...)
+		}
+	}
+	record(&quarantine.RemoteSecretRevokedTime, waiting)
+
+	// removePeerSecrets also deletes the ManagedServiceAccount, whose removal is confirmed below.
+	if waiting, err = r.removePeerSecrets(ctx, mesh, clusterName); err != nil {
+		return nil, err
+	}
+	if len(waiting) == 0 && orphaned {
+		if waiting, err = r.purgeRemoteSecrets(ctx, mesh, clusterName, clusterName, peers, quarantine.PeerSecretsRemovedTime != nil); err != nil {
+			return nil, err
+		}
+	}
+	record(&quarantine.PeerSecretsRemovedTime, waiting)
+
+	waiting = nil
+	if err := r.Get(ctx, key.Of(msaName(mesh), clusterName), &msav1beta1.ManagedServiceAccount{}); err == nil {
+		waiting = append(waiting, "ManagedServiceAccount "+clusterName+"/"+msaName(mesh))
+	} else if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get ManagedServiceAccount %s/%s: %w", clusterName, msaName(mesh), err)
+	}
+	record(&quarantine.ServiceAccountRevokedTime, waiting)
+
+	if waiting, err = r.revokeCertificate(ctx, mesh, clusterName); err != nil {
+		return nil, err
+	}
+	record(&quarantine.CertificateRevokedTime, waiting)
+
+	if waiting, err = r.revokeTrust(ctx, mesh, clusterName, trusting, revocation); err != nil {
+		return nil, err
+	}
+	record(&quarantine.TrustRevokedTime, waiting)
+
+	return quarantine, nil
+}
+
+// revokeCertificate deletes the intermediate CA Certificate of the cluster and the secret holding its key, so that it is
+// neither renewed nor distributed again. The intermediate CA is first recorded in the revoked certificates of the mesh,
+// and only deleted once the record is written. The ManifestWork delivering the cacerts secret to the cluster is kept, as
+// the cluster already holds the key.
+func (r *Reconciler) revokeCertificate(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string) ([]string, error) {
+	name := getCacertsName(clusterName)
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, key.Of(name, mesh.Namespace), secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", mesh.Namespace, name, err)
+		}
+		secret = nil
+	} else if !recordRevokedCertificate(mesh, clusterName, secret) {
+		return []string{"record of the intermediate CA"}, nil
+	}
+
+	var waiting []string
+	cert := &certmanagerv1.Certificate{}
+	if err := r.Get(ctx, key.Of(name, mesh.Namespace), cert); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get Certificate %s/%s: %w", mesh.Namespace, name, err)
+		}
+	} else {
+		waiting = append(waiting, "Certificate "+name)
+		if cert.DeletionTimestamp.IsZero() {
+			klog.Infof("Revoking Certificate %s/%s of quarantined cluster %s", mesh.Namespace, name, clusterName)
+			if err := client.IgnoreNotFound(r.Delete(ctx, cert)); err != nil {
+				return nil, fmt.Errorf("failed to delete Certificate %s/%s: %w", mesh.Namespace, name, err)
+			}
+		}
+	}
+
+	if secret != nil {
+		waiting = append(waiting, "secret "+name)
+		if secret.DeletionTimestamp.IsZero() {
+			klog.Infof("Deleting secret %s/%s of quarantined cluster %s", mesh.Namespace, name, clusterName)
+			if err := client.IgnoreNotFound(r.Delete(ctx, secret)); err != nil {
+				return nil, fmt.Errorf("failed to delete secret %s/%s: %w", mesh.Namespace, name, err)
+			}
+		}
+	}
+	return waiting, nil
+}
+
+// purgeRemoteSecrets clears the remote secrets of the sources that the quarantine of a cluster orphaned on a receiver,
+// by applying them empty with a ManifestWork that deletes them along with it. The work agent overwrites the secrets,
+// dropping their credentials, and the ManifestWork is deleted once the purge is recorded.
+func (r *Reconciler) purgeRemoteSecrets(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName, receiver string, sources []string, purged bool) ([]string, error) {
+	name := ManifestWorkNamePurgePrefix + clusterName
+	if purged {
+		work := &workv1.ManifestWork{}
+		if err := r.Get(ctx, key.Of(name, receiver), work); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get ManifestWork %s/%s: %w", receiver, name, err)
+		}
+		return nil, r.deleteManifestWork(ctx, work)
+	}
+
+	work := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: receiver, Labels: meshOwnedLabels(mesh, receiver)},
+	}
+	for _, source := range sources {
+		work.Spec.Workload.Manifests = append(work.Spec.Workload.Manifests, workv1.Manifest{RawExtension: runtime.RawExtension{Object: &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: remoteSecretName(source), Namespace: mesh.Spec.ControlPlane.Namespace},
+		}}})
+	}
+	work, err := r.workApplier.Apply(ctx, work)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply ManifestWork %s/%s: %w", receiver, name, err)
+	}
+	if reason, _ := manifestWorkState(work); reason != meshv1alpha1.ReasonApplied {
+		return []string{"purge of the remote secrets on cluster " + receiver}, nil
+	}
+	return nil, nil
+}
+
+// cleanupPurgeManifestWorks deletes the ManifestWorks purging the remote secrets of clusters no longer quarantined,
+// which would otherwise clear the remote secrets distributed to them again.
+func (r *Reconciler) cleanupPurgeManifestWorks(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, quarantined []string) error {
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}); err != nil {
+		return fmt.Errorf("failed to list mesh-owned ManifestWorks: %w", err)
+	}
+	for _, work := range workList.Items {
+		if clusterName, ok := strings.CutPrefix(work.Name, ManifestWorkNamePurgePrefix); ok && !slices.Contains(quarantined, clusterName) {
+			if err := r.deleteManifestWork(ctx, &work); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setQuarantinedConditions reports on the quarantined clusters which revocations are still pending, and why the
+// revocation list cannot be signed if it is what they wait for.
+func setQuarantinedConditions(mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster, crl *revocationList) {
+	for _, cluster := range clusters {
+		quarantine := mesh.GetClusterQuarantine(cluster.Name)
+		if quarantine == nil {
+			continue
+		}
+
+		trust := "trust in its intermediate CA"
+		if crl != nil && crl.problem != "" {
+			trust += " (" + crl.problem + ")"
+		}
+		var pending []string
+		for _, revocation := range []struct {
+			name    string
+			revoked *metav1.Time
+		}{
+			{"remote secret", quarantine.RemoteSecretRevokedTime},
+			{"peer remote secrets", quarantine.PeerSecretsRemovedTime},
+			{"ManagedServiceAccount", quarantine.ServiceAccountRevokedTime},
+			{"intermediate CA Certificate", quarantine.CertificateRevokedTime},
+			{trust, quarantine.TrustRevokedTime},
+		} {
+			if revocation.revoked == nil {
+				pending = append(pending, revocation.name)
+			}
+		}
+
+		if len(pending) > 0 {
+			mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionQuarantined, metav1.ConditionTrue, meshv1alpha1.ReasonQuarantineInProgress,
+				"Waiting for the revocation of %s", strings.Join(pending, ", "))
+		} else {
+			mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionQuarantined, metav1.ConditionTrue, meshv1alpha1.ReasonQuarantineComplete,
+				"All credentials are revoked")
+		}
+	}
+}
+
+// revocationRequeueAfter returns when the first revoked intermediate CA expires, so that it is pruned from the
+// revocation list then, or zero if none is revoked.
+func revocationRequeueAfter(mesh *meshv1alpha1.MultiClusterMesh) time.Duration {
+	var requeueAfter time.Duration
+	for _, revoked := range mesh.Status.RevokedCertificates {
+		if remaining := time.Until(revoked.ExpirationTime.Time); requeueAfter == 0 || remaining < requeueAfter {
+			requeueAfter = max(remaining, time.Second)
+		}
+	}
+	return requeueAfter
+}
@@ -0,0 +1,368 @@
+package mesh
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	workfake "open-cluster-management.io/api/client/work/clientset/versioned/fake"
+	workv1lister "open-cluster-management.io/api/client/work/listers/work/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+	msav1beta1 "open-cluster-management.io/managed-serviceaccount/apis/authentication/v1beta1"
+	"open-cluster-management.io/sdk-go/pkg/apis/work/v1/applier"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestQuarantineCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workv1.Install(scheme)
+	_ = workv1alpha1.Install(scheme)
+	_ = msav1beta1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+	_ = clusterv1beta1.Install(scheme)
+
+	mesh := &meshv1alpha1.MultiClusterMesh{
+		ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: "mesh-ns"},
+		Spec: meshv1alpha1.MultiClusterMeshSpec{
+			ClusterSet: "set",
+			Security: meshv1alpha1.SecurityConfig{Trust: meshv1alpha1.TrustConfig{CertManager: meshv1alpha1.CertManagerConfig{
+				IssuerRef: meshv1alpha1.IssuerReference{Name: "root", Kind: "Issuer"},
+			}}},
+		},
+	}
+	labels := meshOwnedLabels(mesh, "compromised")
+	earlier := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	root := newTestCA(t, nil, nil)
+	intermediate := newTestCA(t, root.cert, root.key)
+	revokedCA := meshv1alpha1.RevokedCertificate{
+		ClusterName: "compromised", SerialNumber: intermediate.cert.SerialNumber.Text(16),
+		RevocationTime: earlier, ExpirationTime: metav1.NewTime(intermediate.cert.NotAfter),
+	}
+	crl := &revocationList{pem: []byte("crl"), serials: map[string]bool{revokedCA.SerialNumber: true}}
+	peerCacerts := func(crl []byte) *workv1.ManifestWork {
+		work := buildMeshOwnedManifestWork(mesh, "peer", ManifestWorkNameCacerts, meshv1alpha1.DeletionPolicyDelete, &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			Data:     withRevocationList(nil, crl),
+		})
+		work.Status.Conditions = []metav1.Condition{
+			{Type: workv1.WorkApplied, Status: metav1.ConditionTrue},
+			{Type: workv1.WorkAvailable, Status: metav1.ConditionTrue},
+		}
+		return work
+	}
+
+	remoteSecret := &workv1alpha1.ManifestWorkReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: RemoteSecretDistributionName(mesh, "compromised"), Namespace: mesh.Namespace, Labels: labels,
+	}}
+	peerSecret := &workv1alpha1.ManifestWorkReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: RemoteSecretDistributionName(mesh, "peer"), Namespace: mesh.Namespace, Labels: meshOwnedLabels(mesh, "peer"),
+	}}
+	msa := &msav1beta1.ManagedServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: msaName(mesh), Namespace: "compromised", Labels: labels}}
+	cert := &certmanagerv1.Certificate{ObjectMeta: metav1.ObjectMeta{Name: "cacerts-compromised", Namespace: mesh.Namespace, Labels: labels}}
+	certSecret := intermediate.secret("cacerts-compromised", mesh.Namespace, labels)
+	cacertsWork := &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: ManifestWorkNameCacerts, Namespace: "compromised", Labels: labels}}
+	// The ManifestWork is already being deleted, and remains until the work agent releases it.
+	terminating := func(name, namespace, mwrset string) *workv1.ManifestWork {
+		return &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: namespace,
+			Labels:            map[string]string{workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey: mesh.Namespace + "." + mwrset},
+			DeletionTimestamp: &metav1.Time{Time: metav1.Now().Time}, Finalizers: []string{"test"},
+		}}
+	}
+
+	tests := []struct {
+		name             string
+		objects          []client.Object
+		revoked          []meshv1alpha1.RevokedCertificate
+		crl              *revocationList
+		previous         *meshv1alpha1.ClusterQuarantineStatus
+		expectedRevoked  []string
+		expectedRecorded bool
+		expectedDeleted  []client.Object
+		expectedKept     []client.Object
+	}{
+		{
+			name:             "records the intermediate CA before deleting it",
+			objects:          []client.Object{remoteSecret, msa, cert, certSecret, cacertsWork, peerCacerts(nil)},
+			crl:              &revocationList{},
+			expectedRecorded: true,
+			expectedRevoked:  []string{"peerSecrets", "serviceAccount"},
+			expectedDeleted:  []client.Object{remoteSecret, msa},
+			expectedKept:     []client.Object{cert, certSecret, cacertsWork},
+		},
+		{
+			name:             "revokes all credentials and keeps the cacerts on the cluster",
+			objects:          []client.Object{remoteSecret, msa, cert, certSecret, cacertsWork, peerCacerts(crl.pem)},
+			revoked:          []meshv1alpha1.RevokedCertificate{revokedCA},
+			crl:              crl,
+			expectedRecorded: true,
+			expectedRevoked:  []string{"peerSecrets", "serviceAccount", "trust"},
+			expectedDeleted:  []client.Object{remoteSecret, msa, cert, certSecret},
+			expectedKept:     []client.Object{cacertsWork},
+		},
+		{
+			name:             "waits for the peers to apply the revocation list",
+			objects:          []client.Object{peerCacerts(nil)},
+			revoked:          []meshv1alpha1.RevokedCertificate{revokedCA},
+			crl:              crl,
+			previous:         &meshv1alpha1.ClusterQuarantineStatus{Since: earlier},
+			expectedRecorded: true,
+			expectedRevoked:  []string{"remoteSecret", "peerSecrets", "serviceAccount", "certificate"},
+		},
+		{
+			name:             "waits for the revocation list to be signed",
+			objects:          []client.Object{peerCacerts(nil)},
+			revoked:          []meshv1alpha1.RevokedCertificate{revokedCA},
+			crl:              &revocationList{problem: "Issuer root is not a CA issuer"},
+			previous:         &meshv1alpha1.ClusterQuarantineStatus{Since: earlier},
+			expectedRecorded: true,
+			expectedRevoked:  []string{"remoteSecret", "peerSecrets", "serviceAccount", "certificate"},
+		},
+		{
+			name: "waits for the remote secret to be gone from the peers and the peer secrets from the cluster",
+			objects: []client.Object{peerSecret,
+				terminating("compromised-secret", "peer", remoteSecret.Name), terminating("peer-secret", "compromised", peerSecret.Name)},
+			crl:             &revocationList{},
+			previous:        &meshv1alpha1.ClusterQuarantineStatus{Since: earlier},
+			expectedRevoked: []string{"serviceAccount", "certificate", "trust"},
+			expectedKept:    []client.Object{peerSecret},
+		},
+		{
+			name:            "keeps the time of earlier revocations",
+			crl:             &revocationList{},
+			previous:        &meshv1alpha1.ClusterQuarantineStatus{Since: earlier, RemoteSecretRevokedTime: &earlier},
+			expectedRevoked: []string{"remoteSecret", "peerSecrets", "serviceAccount", "certificate", "trust"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := make([]client.Object, 0, len(tc.objects))
+			for _, obj := range tc.objects {
+				objects = append(objects, obj.DeepCopyObject().(client.Object))
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			r := &Reconciler{Client: c, Scheme: scheme}
+			mesh := mesh.DeepCopy()
+			mesh.Status.RevokedCertificates = slices.Clone(tc.revoked)
+
+			quarantine, err := r.quarantineCluster(context.Background(), mesh, "compromised", []string{"peer"}, []string{"peer"}, tc.crl, tc.previous.DeepCopy())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.previous != nil && !quarantine.Since.Equal(&tc.previous.Since) {
+				t.Errorf("since = %v, want %v", quarantine.Since, tc.previous.Since)
+			}
+
+			revoked := map[string]*metav1.Time{
+				"remoteSecret":   quarantine.RemoteSecretRevokedTime,
+				"peerSecrets":    quarantine.PeerSecretsRemovedTime,
+				"serviceAccount": quarantine.ServiceAccountRevokedTime,
+				"certificate":    quarantine.CertificateRevokedTime,
+				"trust":          quarantine.TrustRevokedTime,
+			}
+			expected := map[string]bool{}
+			for _, name := range tc.expectedRevoked {
+				expected[name] = true
+			}
+			for name, revokedTime := range revoked {
+				if expected[name] != (revokedTime != nil) {
+					t.Errorf("%s revoked at %v, want revoked: %v", name, revokedTime, expected[name])
+				}
+			}
+			if tc.previous != nil && tc.previous.RemoteSecretRevokedTime != nil &&
+				!quarantine.RemoteSecretRevokedTime.Equal(tc.previous.RemoteSecretRevokedTime) {
+				t.Errorf("remote secret revoked at %v, want the earlier %v", quarantine.RemoteSecretRevokedTime, tc.previous.RemoteSecretRevokedTime)
+			}
+			if recorded := len(mesh.Status.RevokedCertificates) == 1 && mesh.Status.RevokedCertificates[0].SerialNumber == revokedCA.SerialNumber; recorded != tc.expectedRecorded {
+				t.Errorf("revoked certificates = %+v, want the intermediate CA %s recorded: %v", mesh.Status.RevokedCertificates, revokedCA.SerialNumber, tc.expectedRecorded)
+			}
+
+			for _, obj := range tc.expectedDeleted {
+				if err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj.DeepCopyObject().(client.Object)); !apierrors.IsNotFound(err) {
+					t.Errorf("%T %s was not deleted", obj, obj.GetName())
+				}
+			}
+			for _, obj := range tc.expectedKept {
+				if err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj.DeepCopyObject().(client.Object)); err != nil {
+					t.Errorf("%T %s was deleted: %v", obj, obj.GetName(), err)
+				}
+			}
+		})
+	}
+}
+
+func TestSetQuarantinedConditions(t *testing.T) {
+	now := metav1.Now()
+	past := metav1.NewTime(now.Add(-time.Hour))
+	revoked := &meshv1alpha1.ClusterQuarantineStatus{
+		Since: past, RemoteSecretRevokedTime: &now, PeerSecretsRemovedTime: &now, ServiceAccountRevokedTime: &now,
+		CertificateRevokedTime: &now, TrustRevokedTime: &now,
+	}
+	untrusted := revoked.DeepCopy()
+	untrusted.TrustRevokedTime = nil
+
+	tests := []struct {
+		name            string
+		quarantine      *meshv1alpha1.ClusterQuarantineStatus
+		crl             *revocationList
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:            "revocations pending",
+			quarantine:      &meshv1alpha1.ClusterQuarantineStatus{Since: past, RemoteSecretRevokedTime: &now},
+			expectedReason:  meshv1alpha1.ReasonQuarantineInProgress,
+			expectedMessage: "Waiting for the revocation of peer remote secrets, ManagedServiceAccount, intermediate CA Certificate, trust in its intermediate CA",
+		},
+		{
+			name:            "revocation list not signed",
+			quarantine:      untrusted,
+			crl:             &revocationList{problem: "Issuer root is not a CA issuer"},
+			expectedReason:  meshv1alpha1.ReasonQuarantineInProgress,
+			expectedMessage: "Waiting for the revocation of trust in its intermediate CA (Issuer root is not a CA issuer)",
+		},
+		{
+			name:            "all revoked",
+			quarantine:      revoked,
+			expectedReason:  meshv1alpha1.ReasonQuarantineComplete,
+			expectedMessage: "All credentials are revoked",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mesh := &meshv1alpha1.MultiClusterMesh{}
+			mesh.SetClusterQuarantine("compromised", tc.quarantine)
+			clusters := []clusterv1.ManagedCluster{{ObjectMeta: metav1.ObjectMeta{Name: "compromised"}}}
+
+			setQuarantinedConditions(mesh, clusters, tc.crl)
+			condition := meta.FindStatusCondition(mesh.Status.ClusterStatus[0].Conditions, meshv1alpha1.ConditionQuarantined)
+			if condition == nil || condition.Reason != tc.expectedReason || condition.Message != tc.expectedMessage {
+				t.Errorf("Quarantined condition = %+v, want reason %s and message %q", condition, tc.expectedReason, tc.expectedMessage)
+			}
+		})
+	}
+}
+
+func TestQuarantineClusterPurgesOrphanedRemoteSecrets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workv1.Install(scheme)
+	_ = workv1alpha1.Install(scheme)
+	_ = msav1beta1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+	_ = clusterv1beta1.Install(scheme)
+
+	mesh := &meshv1alpha1.MultiClusterMesh{
+		ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: "mesh-ns"},
+		Spec: meshv1alpha1.MultiClusterMeshSpec{
+			ClusterSet:     "set",
+			DeletionPolicy: meshv1alpha1.DeletionPolicy{RemoteSecrets: meshv1alpha1.DeletionPolicyOrphan},
+		},
+	}
+	earlier := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	purge := func(receiver string, applied bool) *workv1.ManifestWork {
+		work := &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{
+			Name: ManifestWorkNamePurgePrefix + "compromised", Namespace: receiver, Labels: meshOwnedLabels(mesh, receiver),
+		}}
+		if applied {
+			work.Status.Conditions = []metav1.Condition{
+				{Type: workv1.WorkApplied, Status: metav1.ConditionTrue},
+				{Type: workv1.WorkAvailable, Status: metav1.ConditionTrue},
+			}
+		}
+		return work
+	}
+
+	tests := []struct {
+		name            string
+		works           []*workv1.ManifestWork
+		previous        *meshv1alpha1.ClusterQuarantineStatus
+		expectedRevoked bool
+		expectedWorks   []string
+		expectedDeleted []string
+	}{
+		{
+			name:          "clears the orphaned remote secrets",
+			previous:      &meshv1alpha1.ClusterQuarantineStatus{Since: earlier},
+			expectedWorks: []string{"peer", "compromised"},
+		},
+		{
+			name:            "records the revocations once the remote secrets are cleared",
+			works:           []*workv1.ManifestWork{purge("peer", true), purge("compromised", true)},
+			previous:        &meshv1alpha1.ClusterQuarantineStatus{Since: earlier},
+			expectedRevoked: true,
+			expectedWorks:   []string{"peer", "compromised"},
+		},
+		{
+			name:  "deletes the cleared remote secrets",
+			works: []*workv1.ManifestWork{purge("peer", true), purge("compromised", true)},
+			previous: &meshv1alpha1.ClusterQuarantineStatus{
+				Since: earlier, RemoteSecretRevokedTime: &earlier, PeerSecretsRemovedTime: &earlier,
+			},
+			expectedRevoked: true,
+			expectedDeleted: []string{"peer", "compromised"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var objects []client.Object
+			var works []runtime.Object
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, work := range tc.works {
+				objects = append(objects, work.DeepCopy())
+				works = append(works, work.DeepCopy())
+				_ = indexer.Add(work)
+			}
+			workClient := workfake.NewSimpleClientset(works...)
+			r := &Reconciler{
+				Client:      fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+				Scheme:      scheme,
+				workApplier: applier.NewWorkApplierWithTypedClient(workClient, workv1lister.NewManifestWorkLister(indexer)),
+			}
+
+			quarantine, err := r.quarantineCluster(ctx, mesh, "compromised", []string{"peer"}, []string{"peer"}, &revocationList{}, tc.previous.DeepCopy())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for name, revokedTime := range map[string]*metav1.Time{
+				"remoteSecret": quarantine.RemoteSecretRevokedTime,
+				"peerSecrets":  quarantine.PeerSecretsRemovedTime,
+			} {
+				if (revokedTime != nil) != tc.expectedRevoked {
+					t.Errorf("%s revoked at %v, want revoked: %v", name, revokedTime, tc.expectedRevoked)
+				}
+			}
+
+			for _, receiver := range tc.expectedWorks {
+				if _, err := workClient.WorkV1().ManifestWorks(receiver).Get(ctx, ManifestWorkNamePurgePrefix+"compromised", metav1.GetOptions{}); err != nil {
+					t.Errorf("purge ManifestWork on cluster %s not found: %v", receiver, err)
+				}
+			}
+			for _, receiver := range tc.expectedDeleted {
+				if _, err := workClient.WorkV1().ManifestWorks(receiver).Get(ctx, ManifestWorkNamePurgePrefix+"compromised", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+					t.Errorf("purge ManifestWork on cluster %s was not deleted", receiver)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+)
+
+// ManifestWorkNameIstiodProbePrefix prefixes the name of the read-only ManifestWork reporting the istiod Deployment of
+// a control plane namespace to the Istiod readiness gate.
+const ManifestWorkNameIstiodProbePrefix = "multicluster-mesh-istiod-probe-"
+
+// peerReadiness is the readiness gate state of the clusters of a mesh, by cluster name.
+type peerReadiness map[string]*clusterReadiness
+
+// clusterReadiness tells whether a cluster passed the readiness gate, or what it waits for.
+type clusterReadiness struct {
+	ready   bool
+	reason  string
+	message string
+}
+
+// gated returns the clusters that did not pass the readiness gate, which neither distribute their remote secret nor
+// receive the remote secrets of their peers.
+func (p peerReadiness) gated() []string {
+	var gated []string
+	for _, clusterName := range slices.Sorted(maps.Keys(p)) {
+		if !p[clusterName].ready {
+			gated = append(gated, clusterName)
+		}
+	}
+	return gated
+}
+
+// readinessGate returns the readiness gate of the mesh, None unless set, so that meshes created before the gate existed
+// keep distributing the remote secrets of clusters whose distribution predates the per-cluster ManifestWorkReplicaSets.
+func readinessGate(mesh *meshv1alpha1.MultiClusterMesh) meshv1alpha1.ReadinessGate {
+	if mesh.Spec.Security.Discovery.ReadinessGate == "" {
+		return meshv1alpha1.ReadinessGateNone
+	}
+	return mesh.Spec.Security.Discovery.ReadinessGate
+}
+
+// planPeerReadiness evaluates the readiness gate of the clusters of the mesh. Quarantined clusters are left out, as they
+// are never peers.
+func (r *Reconciler) planPeerReadiness(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster, rollout *operatorRollout) (peerReadiness, error) {
+	readiness := peerReadiness{}
+	for _, cluster := range clusters {
+		if quarantined(&cluster) {
+			continue
+		}
+		state, err := r.clusterPeerReadiness(ctx, mesh, cluster.Name, rollout.clusters[cluster.Name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate the readiness gate of cluster %s: %w", cluster.Name, err)
+		}
+		if !state.ready {
+			klog.V(4).Infof("Cluster %s is not a peer of MultiClusterMesh %s/%s yet: %s", cluster.Name, mesh.Namespace, mesh.Name, state.message)
+		}
+		readiness[cluster.Name] = state
+	}
+	return readiness, nil
+}
+
+// clusterPeerReadiness evaluates the readiness gate of a cluster. A cluster whose remote secret is already distributed
+// passed the gate before and stays a peer, so that an operator upgrade or an istiod restart does not cut it off from
+// its peers.
+func (r *Reconciler) clusterPeerReadiness(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string, operator *clusterOperator) (*clusterReadiness, error) {
+	gate := readinessGate(mesh)
+	if gate == meshv1alpha1.ReadinessGateNone {
+		return &clusterReadiness{ready: true, reason: meshv1alpha1.ReasonReadinessGatePassed, message: "No readiness gate is set"}, nil
+	}
+
+	mwrset := &workv1alpha1.ManifestWorkReplicaSet{}
+	name := RemoteSecretDistributionName(mesh, clusterName)
+	if err := r.Get(ctx, key.Of(name, mesh.Namespace), mwrset); err == nil && mwrset.DeletionTimestamp.IsZero() {
+		return &clusterReadiness{ready: true, reason: meshv1alpha1.ReasonReadinessGatePassed, message: "Cluster is a discoverable peer"}, nil
+	} else if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get ManifestWorkReplicaSet %s/%s: %w", mesh.Namespace, name, err)
+	}
+
+	installed, err := r.operatorInstalled(ctx, clusterName, operator)
+	if err != nil {
+		return nil, err
+	}
+	if !installed {
+		return &clusterReadiness{reason: meshv1alpha1.ReasonOperatorNotReady, message: "Waiting for the operator to be installed"}, nil
+	}
+
+	if mesh.Spec.Security.Trust.CertManager.IssuerRef.Name != "" {
+		work := &workv1.ManifestWork{}
+		if err := r.Get(ctx, key.Of(ManifestWorkNameCacerts, clusterName), work); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get cacerts ManifestWork for cluster %s: %w", clusterName, err)
+		} else if reason, _ := manifestWorkState(work); err != nil || reason != meshv1alpha1.ReasonApplied {
+			return &clusterReadiness{reason: meshv1alpha1.ReasonCacertsNotApplied, message: "Waiting for the cacerts secret to be applied"}, nil
+		}
+	}
+
+	if gate == meshv1alpha1.ReadinessGateIstiod {
+		cpNamespace := mesh.GetControlPlaneNamespace()
+		probe := &workv1.ManifestWork{}
+		if err := r.Get(ctx, key.Of(ManifestWorkNameIstiodProbePrefix+cpNamespace, clusterName), probe); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get istiod probe ManifestWork for cluster %s: %w", clusterName, err)
+		}
+		if ready, message := istiodReadiness(probe, cpNamespace); !ready {
+			return &clusterReadiness{reason: meshv1alpha1.ReasonIstiodNotReady, message: message}, nil
+		}
+	}
+
+	return &clusterReadiness{ready: true, reason: meshv1alpha1.ReasonReadinessGatePassed, message: fmt.Sprintf("Cluster passed the %s readiness gate", gate)}, nil
+}
+
+// operatorInstalled reports whether the operator of a cluster is installed, by the add-on or by a pre-existing
+// Subscription, as reported by the OperatorInstalled condition.
+func (r *Reconciler) operatorInstalled(ctx context.Context, clusterName string, operator *clusterOperator) (bool, error) {
+	var status metav1.ConditionStatus
+	switch operator.detection.ownership {
+	case operatorOwned:
+		work := &workv1.ManifestWork{}
+		if err := r.Get(ctx, key.Of(OperatorManifestWorkName, clusterName), work); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return false, fmt.Errorf("failed to get operator ManifestWork for cluster %s: %w", clusterName, err)
+			}
+			return false, nil
+		}
+		status, _, _ = operatorInstallStatus(operator.config, getManifestWorkFeedback(work))
+	case operatorAdopted:
+		status, _, _ = operatorInstallState(operator.detection.feedback, "")
+	default:
+		status, _, _ = existingOperatorState(operator.detection)
+	}
+	return status == metav1.ConditionTrue, nil
+}
+
+// istiodReadiness reports whether the istiod probe ManifestWork reports every replica of the istiod Deployment ready.
+func istiodReadiness(probe *workv1.ManifestWork, cpNamespace string) (bool, string) {
+	index := slices.IndexFunc(probe.Status.ResourceStatus.Manifests, func(m workv1.ManifestCondition) bool {
+		return m.ResourceMeta.Kind == "Deployment" && m.ResourceMeta.Namespace == cpNamespace && m.ResourceMeta.Name == IstiodDeploymentName
+	})
+	if index < 0 {
+		return false, fmt.Sprintf("Waiting for the istiod Deployment in namespace %s to be reported", cpNamespace)
+	}
+	status := probe.Status.ResourceStatus.Manifests[index]
+	if !meta.IsStatusConditionTrue(status.Conditions, workv1.ManifestAvailable) {
+		return false, fmt.Sprintf("Waiting for the istiod Deployment in namespace %s to be created", cpNamespace)
+	}
+
+	feedback := feedbackValues(status.StatusFeedbacks.Values)
+	replicas, _ := strconv.Atoi(feedback[FeedbackReplicas])
+	ready, _ := strconv.Atoi(feedback[FeedbackReadyReplicas])
+	if replicas == 0 || ready < replicas {
+		return false, fmt.Sprintf("Waiting for the istiod Deployment in namespace %s to be ready, %d of %d replicas ready", cpNamespace, ready, replicas)
+	}
+	return true, ""
+}
+
+// ensureIstiodProbe applies the istiod probe ManifestWork to a cluster with the Istiod readiness gate, and deletes it
+// otherwise.
+func (r *Reconciler) ensureIstiodProbe(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, cluster *clusterv1.ManagedCluster) error {
+	if readinessGate(mesh) == meshv1alpha1.ReadinessGateIstiod {
+		work, err := r.workApplier.Apply(ctx, buildIstiodProbeManifestWork(mesh, cluster.Name))
+		if err != nil {
+			return fmt.Errorf("failed to apply istiod probe ManifestWork: %w", err)
+		}
+		klog.V(4).Infof("Applied istiod probe ManifestWork %s/%s", work.Namespace, work.Name)
+		return nil
+	}
+
+	existing := &workv1.ManifestWork{}
+	if err := r.Get(ctx, key.Of(ManifestWorkNameIstiodProbePrefix+mesh.GetControlPlaneNamespace(), cluster.Name), existing); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get istiod probe ManifestWork: %w", err)
+	}
+	return r.deleteManifestWork(ctx, existing)
+}
+
+// buildIstiodProbeManifestWork builds a read-only ManifestWork that reports the istiod Deployment of the control plane
+// namespace.
+func buildIstiodProbeManifestWork(mesh *meshv1alpha1.MultiClusterMesh, clusterName string) *workv1.ManifestWork {
+	cpNamespace := mesh.GetControlPlaneNamespace()
+	manifest, config := istiodDeployment(cpNamespace).readOnlyProbe(workv1.FeedbackRule{Type: workv1.WellKnownStatusType})
+	return &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ManifestWorkNameIstiodProbePrefix + cpNamespace,
+			Namespace: clusterName,
+			Labels:    meshOwnedLabels(mesh, clusterName),
+		},
+		Spec: workv1.ManifestWorkSpec{
+			Workload: workv1.ManifestsTemplate{
+				Manifests: []workv1.Manifest{manifest},
+			},
+			ManifestConfigs: []workv1.ManifestConfigOption{config},
+		},
+	}
+}
+
+// setPeerReadyConditions reports the readiness gate state of the clusters with their PeerReady condition.
+func setPeerReadyConditions(mesh *meshv1alpha1.MultiClusterMesh, readiness peerReadiness) {
+	for _, clusterName := range slices.Sorted(maps.Keys(readiness)) {
+		state := readiness[clusterName]
+		status := metav1.ConditionFalse
+		if state.ready {
+			status = metav1.ConditionTrue
+		}
+		mesh.SetClusterCondition(clusterName, meshv1alpha1.ConditionPeerReady, status, state.reason, "%s", state.message)
+	}
+}
+
+// excludedPeers returns the sorted clusters whose remote secret is not distributed and which do not receive the remote
+// secrets of their peers: the clusters that did not pass the readiness gate and the quarantined ones.
+func excludedPeers(readiness peerReadiness, clusters []clusterv1.ManagedCluster) []string {
+	excluded := append(readiness.gated(), quarantinedClusterNames(clusters)...)
+	slices.Sort(excluded)
+	return slices.Compact(excluded)
+}
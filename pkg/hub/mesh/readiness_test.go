@@ -0,0 +1,228 @@
+package mesh
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestPlanPeerReadiness(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = workv1.Install(scheme)
+	_ = workv1alpha1.Install(scheme)
+
+	feedback := func(name string, value int64) workv1.FeedbackValue {
+		return workv1.FeedbackValue{Name: name, Value: workv1.FieldValue{Type: workv1.Integer, Integer: &value}}
+	}
+	installedCSV := "sailoperator.v1.0.0"
+	operatorWork := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{Name: OperatorManifestWorkName, Namespace: "cluster1"},
+		Status: workv1.ManifestWorkStatus{ResourceStatus: workv1.ManifestResourceStatus{Manifests: []workv1.ManifestCondition{{
+			StatusFeedbacks: workv1.StatusFeedbackResult{Values: []workv1.FeedbackValue{{
+				Name: FeedbackInstalledCSV, Value: workv1.FieldValue{Type: workv1.String, String: &installedCSV},
+			}}},
+		}}}},
+	}
+	cacertsWork := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{Name: ManifestWorkNameCacerts, Namespace: "cluster1"},
+		Status: workv1.ManifestWorkStatus{Conditions: []metav1.Condition{
+			{Type: workv1.WorkApplied, Status: metav1.ConditionTrue},
+			{Type: workv1.WorkAvailable, Status: metav1.ConditionTrue},
+		}},
+	}
+	istiodProbe := func(replicas, ready int64) *workv1.ManifestWork {
+		return &workv1.ManifestWork{
+			ObjectMeta: metav1.ObjectMeta{Name: ManifestWorkNameIstiodProbePrefix + "istio-system", Namespace: "cluster1"},
+			Status: workv1.ManifestWorkStatus{ResourceStatus: workv1.ManifestResourceStatus{Manifests: []workv1.ManifestCondition{{
+				ResourceMeta: workv1.ManifestResourceMeta{Kind: "Deployment", Name: IstiodDeploymentName, Namespace: "istio-system"},
+				Conditions:   []metav1.Condition{{Type: workv1.ManifestAvailable, Status: metav1.ConditionTrue}},
+				StatusFeedbacks: workv1.StatusFeedbackResult{Values: []workv1.FeedbackValue{
+					feedback(FeedbackReplicas, replicas), feedback(FeedbackReadyReplicas, ready),
+				}},
+			}}}},
+		}
+	}
+	distribution := func(terminating bool) *workv1alpha1.ManifestWorkReplicaSet {
+		mwrset := &workv1alpha1.ManifestWorkReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: RemoteSecretDistributionName(meshWith("mesh-ns", "mesh", metav1.Now()), "cluster1"), Namespace: "mesh-ns",
+		}}
+		if terminating {
+			mwrset.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+			mwrset.Finalizers = []string{"test"}
+		}
+		return mwrset
+	}
+
+	legacyDistribution := &workv1alpha1.ManifestWorkReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "mesh", Namespace: "mesh-ns", Labels: map[string]string{MeshNameLabel: "mesh", MeshNamespaceLabel: "mesh-ns"},
+	}}
+
+	tests := []struct {
+		name           string
+		gate           meshv1alpha1.ReadinessGate
+		issuer         string
+		quarantined    bool
+		objects        []client.Object
+		expectedReady  bool
+		expectedReason string
+	}{
+		{
+			name:           "waits for the operator",
+			gate:           meshv1alpha1.ReadinessGateOperator,
+			expectedReason: meshv1alpha1.ReasonOperatorNotReady,
+		},
+		{
+			name:           "passes without a readiness gate",
+			gate:           meshv1alpha1.ReadinessGateNone,
+			expectedReady:  true,
+			expectedReason: meshv1alpha1.ReasonReadinessGatePassed,
+		},
+		{
+			// Meshes created before the readiness gate have no gate set, and their clusters received the remote
+			// secrets of their peers through the mesh-wide ManifestWorkReplicaSet, with no per-cluster one yet.
+			name:           "passes by default, before the operator is installed",
+			objects:        []client.Object{legacyDistribution},
+			expectedReady:  true,
+			expectedReason: meshv1alpha1.ReasonReadinessGatePassed,
+		},
+		{
+			name:           "passes once the operator is installed",
+			gate:           meshv1alpha1.ReadinessGateOperator,
+			objects:        []client.Object{operatorWork},
+			expectedReady:  true,
+			expectedReason: meshv1alpha1.ReasonReadinessGatePassed,
+		},
+		{
+			name:           "waits for the cacerts secret when trust is configured",
+			gate:           meshv1alpha1.ReadinessGateOperator,
+			issuer:         "root-ca",
+			objects:        []client.Object{operatorWork},
+			expectedReason: meshv1alpha1.ReasonCacertsNotApplied,
+		},
+		{
+			name:           "passes once the cacerts secret is applied",
+			gate:           meshv1alpha1.ReadinessGateOperator,
+			issuer:         "root-ca",
+			objects:        []client.Object{operatorWork, cacertsWork},
+			expectedReady:  true,
+			expectedReason: meshv1alpha1.ReasonReadinessGatePassed,
+		},
+		{
+			name:           "waits for istiod to be reported",
+			gate:           meshv1alpha1.ReadinessGateIstiod,
+			objects:        []client.Object{operatorWork},
+			expectedReason: meshv1alpha1.ReasonIstiodNotReady,
+		},
+		{
+			name:           "waits for every istiod replica to be ready",
+			gate:           meshv1alpha1.ReadinessGateIstiod,
+			objects:        []client.Object{operatorWork, istiodProbe(2, 1)},
+			expectedReason: meshv1alpha1.ReasonIstiodNotReady,
+		},
+		{
+			name:           "passes once istiod is ready",
+			gate:           meshv1alpha1.ReadinessGateIstiod,
+			objects:        []client.Object{operatorWork, istiodProbe(2, 2)},
+			expectedReady:  true,
+			expectedReason: meshv1alpha1.ReasonReadinessGatePassed,
+		},
+		{
+			name:           "keeps a cluster whose remote secret is distributed",
+			gate:           meshv1alpha1.ReadinessGateIstiod,
+			objects:        []client.Object{distribution(false)},
+			expectedReady:  true,
+			expectedReason: meshv1alpha1.ReasonReadinessGatePassed,
+		},
+		{
+			name:           "evaluates again a cluster whose remote secret is being withdrawn",
+			gate:           meshv1alpha1.ReadinessGateOperator,
+			objects:        []client.Object{distribution(true)},
+			expectedReason: meshv1alpha1.ReasonOperatorNotReady,
+		},
+		{
+			name:        "leaves out quarantined clusters",
+			gate:        meshv1alpha1.ReadinessGateNone,
+			quarantined: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+			r := &Reconciler{Client: c, Scheme: scheme}
+
+			mesh := &meshv1alpha1.MultiClusterMesh{
+				ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: "mesh-ns"},
+				Spec: meshv1alpha1.MultiClusterMeshSpec{
+					ClusterSet:   "set",
+					ControlPlane: meshv1alpha1.ControlPlaneConfig{Namespace: "istio-system"},
+				},
+			}
+			mesh.Spec.Security.Discovery.ReadinessGate = tc.gate
+			mesh.Spec.Security.Trust.CertManager.IssuerRef.Name = tc.issuer
+			cluster := clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}}
+			if tc.quarantined {
+				cluster.Annotations = map[string]string{AnnotationQuarantine: "true"}
+			}
+			rollout := &operatorRollout{clusters: map[string]*clusterOperator{
+				"cluster1": {detection: operatorDetection{ownership: operatorOwned}},
+			}}
+
+			readiness, err := r.planPeerReadiness(context.Background(), mesh, []clusterv1.ManagedCluster{cluster}, rollout)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			state := readiness["cluster1"]
+			if tc.quarantined {
+				if state != nil {
+					t.Errorf("planPeerReadiness() = %+v for a quarantined cluster, want none", state)
+				}
+				return
+			}
+			if state.ready != tc.expectedReady || state.reason != tc.expectedReason {
+				t.Errorf("planPeerReadiness() = ready %v, reason %s, want ready %v, reason %s (%s)",
+					state.ready, state.reason, tc.expectedReady, tc.expectedReason, state.message)
+			}
+			if gated := len(readiness.gated()) > 0; gated == tc.expectedReady {
+				t.Errorf("gated() = %v, want gated: %v", readiness.gated(), !tc.expectedReady)
+			}
+		})
+	}
+}
+
+func TestBuildIstiodProbeManifestWork(t *testing.T) {
+	mesh := &meshv1alpha1.MultiClusterMesh{ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: "mesh-ns"}}
+	cpNamespace := mesh.GetControlPlaneNamespace()
+	probe := buildIstiodProbeManifestWork(mesh, "cluster1")
+	guard := buildDeletionGuardManifestWork(mesh, "cluster1", cpNamespace)
+
+	// The readiness gate and the deletion guard probe the istiod Deployment the same way.
+	config := probe.Spec.ManifestConfigs[0]
+	if config.UpdateStrategy == nil || config.UpdateStrategy.Type != workv1.UpdateStrategyTypeReadOnly {
+		t.Errorf("istiod probe update strategy = %v, want ReadOnly", config.UpdateStrategy)
+	}
+	index := slices.IndexFunc(guard.Spec.ManifestConfigs, func(c workv1.ManifestConfigOption) bool {
+		return c.ResourceIdentifier == config.ResourceIdentifier
+	})
+	if index < 0 {
+		t.Fatalf("deletion guard does not probe %+v", config.ResourceIdentifier)
+	}
+	if !equality.Semantic.DeepEqual(guard.Spec.ManifestConfigs[index], config) {
+		t.Errorf("deletion guard probes istiod with %+v, want %+v", guard.Spec.ManifestConfigs[index], config)
+	}
+	// The deletion guard applies its ClusterRole before the probed resources.
+	if !equality.Semantic.DeepEqual(guard.Spec.Workload.Manifests[index+1], probe.Spec.Workload.Manifests[0]) {
+		t.Errorf("deletion guard istiod manifest = %+v, want %+v", guard.Spec.Workload.Manifests[index+1], probe.Spec.Workload.Manifests[0])
+	}
+}
@@ -0,0 +1,141 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+)
+
+// clusterRemoval lists the clusters that left the ClusterSet of the mesh but whose resources are kept until the removal
+// grace period expires, and the progress of the clusters whose resources are being removed.
+type clusterRemoval struct {
+	pending      []clusterv1.ManagedCluster
+	since        map[string]metav1.Time
+	requeueAfter time.Duration
+	teardowns    map[string]*clusterTeardown
+}
+
+// retained returns the members of the mesh together with the clusters pending removal, whose resources are kept.
+func (c *clusterRemoval) retained(clusters []clusterv1.ManagedCluster) []clusterv1.ManagedCluster {
+	return append(append([]clusterv1.ManagedCluster{}, clusters...), c.pending...)
+}
+
+// peerSecretReceivers returns the clusters outside the ClusterSet that still receive the remote secrets of their peers:
+// the clusters pending removal, and the clusters being removed until their own remote secret is gone from the peers,
+// unless they are quarantined.
+func (c *clusterRemoval) peerSecretReceivers() []string {
+	var names []string
+	for _, cluster := range c.pending {
+		if !quarantined(&cluster) {
+			names = append(names, cluster.Name)
+		}
+	}
+	for _, clusterName := range slices.Sorted(maps.Keys(c.teardowns)) {
+		teardown := c.teardowns[clusterName]
+		if teardown.step == teardownRemoteSecret && teardown.cluster != nil && !quarantined(teardown.cluster) {
+			names = append(names, clusterName)
+		}
+	}
+	return names
+}
+
+// planClusterRemoval finds the clusters of the mesh status that are no longer members of the mesh and are still within
+// the removal grace period, counted from when the cluster was first found missing. Deleted ManagedClusters are removed
+// right away, since they are not coming back.
+func (r *Reconciler) planClusterRemoval(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster) (*clusterRemoval, error) {
+	removal := &clusterRemoval{since: map[string]metav1.Time{}}
+	gracePeriod := removalGracePeriod(mesh)
+	if gracePeriod <= 0 {
+		return removal, nil
+	}
+
+	members := clusterNameSet(clusters)
+	now := metav1.Now()
+	for _, cs := range mesh.Status.ClusterStatus {
+		if members[cs.ClusterName] || meta.IsStatusConditionTrue(cs.Conditions, meshv1alpha1.ConditionRemoving) {
+			continue
+		}
+
+		cluster := &clusterv1.ManagedCluster{}
+		if err := r.Get(ctx, key.Of(cs.ClusterName), cluster); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get ManagedCluster %s: %w", cs.ClusterName, err)
+		}
+
+		since := now
+		if c := meta.FindStatusCondition(cs.Conditions, meshv1alpha1.ConditionPendingRemoval); c != nil && c.Status == metav1.ConditionTrue {
+			since = c.LastTransitionTime
+		}
+		remaining := gracePeriod - now.Sub(since.Time)
+		if remaining <= 0 {
+			klog.Infof("Removal grace period of cluster %s expired, removing it from MultiClusterMesh %s/%s", cs.ClusterName, mesh.Namespace, mesh.Name)
+			continue
+		}
+
+		klog.Infof("Cluster %s left ClusterSet %s, keeping its resources for %s", cs.ClusterName, mesh.Spec.ClusterSet, remaining.Round(time.Second))
+		removal.pending = append(removal.pending, *cluster)
+		removal.since[cluster.Name] = since
+		if removal.requeueAfter == 0 || remaining < removal.requeueAfter {
+			removal.requeueAfter = remaining
+		}
+	}
+	return removal, nil
+}
+
+// setRemovalStatus adds the clusters pending removal to the status of the mesh, keeping the time they were first found
+// missing as the transition time of their PendingRemoval condition, and the clusters being removed.
+func setRemovalStatus(mesh *meshv1alpha1.MultiClusterMesh, removal *clusterRemoval) {
+	setTeardownStatus(mesh, removal.teardowns)
+	gracePeriod := removalGracePeriod(mesh)
+	for _, cluster := range removal.pending {
+		since := removal.since[cluster.Name]
+		mesh.Status.ClusterStatus = append(mesh.Status.ClusterStatus, meshv1alpha1.ClusterMeshStatus{
+			ClusterName: cluster.Name,
+			Conditions: []metav1.Condition{{
+				Type:               meshv1alpha1.ConditionPendingRemoval,
+				Status:             metav1.ConditionTrue,
+				Reason:             meshv1alpha1.ReasonRemovalGracePeriod,
+				ObservedGeneration: mesh.Generation,
+				LastTransitionTime: since,
+				Message: fmt.Sprintf("Cluster left ClusterSet %s, its resources are removed at %s unless it rejoins",
+					mesh.Spec.ClusterSet, since.Add(gracePeriod).UTC().Format(time.RFC3339)),
+			}},
+		})
+	}
+}
+
+// departingClusters returns the names of the clusters the status of the mesh reports as left but still using the
+// ManifestWorks shared by the meshes of the ClusterSet: pending removal, or removed up to the operator step.
+func departingClusters(mesh *meshv1alpha1.MultiClusterMesh) []string {
+	var names []string
+	for _, cs := range mesh.Status.ClusterStatus {
+		removing := meta.FindStatusCondition(cs.Conditions, meshv1alpha1.ConditionRemoving)
+		if meta.IsStatusConditionTrue(cs.Conditions, meshv1alpha1.ConditionPendingRemoval) ||
+			removing != nil && removing.Status == metav1.ConditionTrue && removing.Reason != meshv1alpha1.ReasonRemovingOperator {
+			names = append(names, cs.ClusterName)
+		}
+	}
+	return names
+}
+
+// removalGracePeriod returns how long the resources of a cluster that left the ClusterSet are kept, zero when they are
+// removed right away.
+func removalGracePeriod(mesh *meshv1alpha1.MultiClusterMesh) time.Duration {
+	if mesh.Spec.DeletionPolicy.RemovalGracePeriod != nil {
+		return mesh.Spec.DeletionPolicy.RemovalGracePeriod.Duration
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+package mesh
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestPlanClusterRemoval(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clusterv1.Install(scheme)
+
+	pendingSince := func(d time.Duration) meshv1alpha1.ClusterMeshStatus {
+		return meshv1alpha1.ClusterMeshStatus{ClusterName: "leaving", Conditions: []metav1.Condition{{
+			Type:               meshv1alpha1.ConditionPendingRemoval,
+			Status:             metav1.ConditionTrue,
+			Reason:             meshv1alpha1.ReasonRemovalGracePeriod,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-d)),
+		}}}
+	}
+	member := clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "member"}}
+	leaving := &clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "leaving"}}
+
+	tests := []struct {
+		name           string
+		gracePeriod    *metav1.Duration
+		status         meshv1alpha1.ClusterMeshStatus
+		clusterDeleted bool
+		expectPending  bool
+		maxRequeue     time.Duration
+	}{
+		{
+			name:   "no grace period",
+			status: meshv1alpha1.ClusterMeshStatus{ClusterName: "leaving"},
+		},
+		{
+			name:          "cluster just left",
+			gracePeriod:   &metav1.Duration{Duration: 10 * time.Minute},
+			status:        meshv1alpha1.ClusterMeshStatus{ClusterName: "leaving"},
+			expectPending: true,
+			maxRequeue:    10 * time.Minute,
+		},
+		{
+			name:          "grace period running",
+			gracePeriod:   &metav1.Duration{Duration: 10 * time.Minute},
+			status:        pendingSince(8 * time.Minute),
+			expectPending: true,
+			maxRequeue:    2 * time.Minute,
+		},
+		{
+			name:        "grace period expired",
+			gracePeriod: &metav1.Duration{Duration: 10 * time.Minute},
+			status:      pendingSince(11 * time.Minute),
+		},
+		{
+			name:           "cluster deleted",
+			gracePeriod:    &metav1.Duration{Duration: 10 * time.Minute},
+			status:         meshv1alpha1.ClusterMeshStatus{ClusterName: "leaving"},
+			clusterDeleted: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mesh := &meshv1alpha1.MultiClusterMesh{
+				Spec: meshv1alpha1.MultiClusterMeshSpec{DeletionPolicy: meshv1alpha1.DeletionPolicy{RemovalGracePeriod: tc.gracePeriod}},
+				Status: meshv1alpha1.MultiClusterMeshStatus{ClusterStatus: []meshv1alpha1.ClusterMeshStatus{
+					{ClusterName: member.Name}, tc.status,
+				}},
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(member.DeepCopy())
+			if !tc.clusterDeleted {
+				builder = builder.WithObjects(leaving.DeepCopy())
+			}
+			r := &Reconciler{Client: builder.Build(), Scheme: scheme}
+
+			removal, err := r.planClusterRemoval(context.Background(), mesh, []clusterv1.ManagedCluster{member})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pending := len(removal.pending) == 1; pending != tc.expectPending {
+				t.Fatalf("planClusterRemoval() pending = %v, want pending %v", removal.pending, tc.expectPending)
+			}
+			if len(removal.retained([]clusterv1.ManagedCluster{member})) != len(removal.pending)+1 {
+				t.Errorf("retained() does not include the members and the clusters pending removal")
+			}
+			if !tc.expectPending {
+				return
+			}
+			if removal.requeueAfter <= 0 || removal.requeueAfter > tc.maxRequeue {
+				t.Errorf("planClusterRemoval() requeueAfter = %s, want at most %s", removal.requeueAfter, tc.maxRequeue)
+			}
+
+			// The grace period keeps counting from when the cluster was first found missing.
+			mesh.Status.ClusterStatus = []meshv1alpha1.ClusterMeshStatus{{ClusterName: member.Name}}
+			setRemovalStatus(mesh, removal)
+			if names := departingClusters(mesh); len(names) != 1 || names[0] != "leaving" {
+				t.Errorf("departingClusters() = %v, want [leaving]", names)
+			}
+			if since, expected := mesh.Status.ClusterStatus[1].Conditions[0].LastTransitionTime, removal.since["leaving"]; !since.Equal(&expected) {
+				t.Errorf("PendingRemoval condition transition time = %s, want %s", since, expected)
+			}
+		})
+	}
+}
+
+func TestPeerSecretReceivers(t *testing.T) {
+	quarantinedCluster := func(name string) clusterv1.ManagedCluster {
+		return clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{AnnotationQuarantine: "true"}}}
+	}
+	removal := &clusterRemoval{
+		pending: []clusterv1.ManagedCluster{{ObjectMeta: metav1.ObjectMeta{Name: "pending"}}, quarantinedCluster("pending-quarantined")},
+		teardowns: map[string]*clusterTeardown{
+			"revoking":             {step: teardownRemoteSecret, cluster: &clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "revoking"}}},
+			"revoking-quarantined": {step: teardownRemoteSecret, cluster: ptr.To(quarantinedCluster("revoking-quarantined"))},
+			"deleted":              {step: teardownRemoteSecret},
+			"removing-peers":       {step: teardownPeerSecrets, cluster: &clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "removing-peers"}}},
+		},
+	}
+
+	expected := []string{"pending", "revoking"}
+	if got := removal.peerSecretReceivers(); !slices.Equal(got, expected) {
+		t.Errorf("peerSecretReceivers() = %v, want %v", got, expected)
+	}
+}
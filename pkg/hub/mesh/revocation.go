@@ -0,0 +1,415 @@
+package mesh
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	workv1 "open-cluster-management.io/api/work/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+)
+
+const (
+	// CacertsCRLKey is the key of the cacerts secret holding the certificate revocation lists, which istiod hands to
+	// the proxies to validate the certificates of their peers.
+	CacertsCRLKey = "ca-crl.pem"
+
+	// clusterIssuerSecretNamespace is the namespace of the secrets referenced by ClusterIssuers, the default cluster
+	// resource namespace of cert-manager.
+	clusterIssuerSecretNamespace = "cert-manager"
+
+	// revocationListHashAnnotation records the hash of the certificates a cached revocation list was signed for.
+	revocationListHashAnnotation = "mesh.open-cluster-management.io/revocation-list-hash"
+
+	// revocationListGracePeriod extends the revocation lists past the expiration of the last revoked intermediate CA, so
+	// that they are still valid until the entry is pruned.
+	revocationListGracePeriod = 7 * Day
+)
+
+// revocationList is the certificate revocation list distributed to the clusters of a mesh.
+type revocationList struct {
+	// pem is the content of the ca-crl.pem key of the cacerts secrets, nil while no intermediate CA is revoked or when
+	// the list cannot be signed.
+	pem []byte
+	// serials are the serial numbers of the intermediate CAs the list revokes.
+	serials map[string]bool
+	// problem explains why the list cannot be signed.
+	problem string
+}
+
+// revocationListSecretName returns the name of the secret caching the signed revocation list of a mesh.
+func revocationListSecretName(mesh *meshv1alpha1.MultiClusterMesh) string {
+	return mesh.Name + "-ca-crl"
+}
+
+// ensureRevocationList signs the certificate revocation list of the intermediate CAs revoked by quarantines, after
+// pruning the expired ones from the status of the mesh. Envoy only accepts a certificate chain once it holds a
+// revocation list for every CA in it, so the list is a bundle of the list of the root CA, revoking the intermediates,
+// and an empty list for each intermediate CA still in use. The bundle is cached in a secret, and only signed again when
+// the certificates change, so that the cacerts ManifestWorks are not rewritten on every reconcile.
+func (r *Reconciler) ensureRevocationList(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) (*revocationList, error) {
+	now := time.Now()
+	mesh.Status.RevokedCertificates = slices.DeleteFunc(mesh.Status.RevokedCertificates, func(revoked meshv1alpha1.RevokedCertificate) bool {
+		return !now.Before(revoked.ExpirationTime.Time)
+	})
+	list := &revocationList{serials: map[string]bool{}}
+	if len(mesh.Status.RevokedCertificates) == 0 || mesh.Spec.Security.Trust.CertManager.IssuerRef.Name == "" {
+		return list, nil
+	}
+
+	root, rootKey, problem, err := r.issuerCA(ctx, mesh)
+	if err != nil || problem != "" {
+		list.problem = problem
+		return list, err
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "root %s\n", root.SerialNumber.Text(16))
+	var entries []x509.RevocationListEntry
+	nextUpdate := now
+	for _, revoked := range mesh.Status.RevokedCertificates {
+		serial, ok := new(big.Int).SetString(revoked.SerialNumber, 16)
+		if !ok {
+			return nil, fmt.Errorf("invalid serial number %q of the revoked intermediate CA of cluster %s", revoked.SerialNumber, revoked.ClusterName)
+		}
+		list.serials[revoked.SerialNumber] = true
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: serial, RevocationTime: revoked.RevocationTime.Time})
+		if revoked.ExpirationTime.After(nextUpdate) {
+			nextUpdate = revoked.ExpirationTime.Time
+		}
+		fmt.Fprintf(hash, "revoked %s %s\n", revoked.SerialNumber, revoked.ExpirationTime.UTC().Format(time.RFC3339))
+	}
+	nextUpdate = nextUpdate.Add(revocationListGracePeriod)
+
+	intermediates, err := r.intermediateCAs(ctx, mesh, list.serials)
+	if err != nil {
+		return nil, err
+	}
+	for _, clusterName := range slices.Sorted(maps.Keys(intermediates)) {
+		fmt.Fprintf(hash, "intermediate %s\n", intermediates[clusterName].cert.SerialNumber.Text(16))
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+
+	name := revocationListSecretName(mesh)
+	cached := &corev1.Secret{}
+	if err := r.Get(ctx, key.Of(name, mesh.Namespace), cached); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", mesh.Namespace, name, err)
+		}
+		cached = nil
+	} else if cached.Annotations[revocationListHashAnnotation] == digest {
+		list.pem = cached.Data[CacertsCRLKey]
+		return list, nil
+	}
+
+	// The lists are backdated to tolerate clock skew between the hub and the clusters.
+	thisUpdate := now.Add(-time.Hour)
+	bundle, err := signRevocationList(root, rootKey, entries, thisUpdate, nextUpdate)
+	if err != nil {
+		list.problem = fmt.Sprintf("the issuer CA cannot sign certificate revocation lists: %v", err)
+		return list, nil
+	}
+	for _, clusterName := range slices.Sorted(maps.Keys(intermediates)) {
+		intermediate := intermediates[clusterName]
+		crl, err := signRevocationList(intermediate.cert, intermediate.key, nil, thisUpdate, nextUpdate)
+		if err != nil {
+			list.problem = fmt.Sprintf("the intermediate CA of cluster %s cannot sign certificate revocation lists: %v", clusterName, err)
+			return list, nil
+		}
+		bundle = append(bundle, crl...)
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: mesh.Namespace}}
+	if cached != nil {
+		secret = cached
+	}
+	secret.Labels = map[string]string{ManagedByLabel: ManagedByValue, MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}
+	secret.Annotations = map[string]string{revocationListHashAnnotation: digest}
+	secret.Data = map[string][]byte{CacertsCRLKey: bundle}
+	if err := controllerutil.SetControllerReference(mesh, secret, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set the owner of secret %s/%s: %w", mesh.Namespace, name, err)
+	}
+	if cached == nil {
+		err = r.Create(ctx, secret)
+	} else {
+		err = r.Update(ctx, secret)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write secret %s/%s: %w", mesh.Namespace, name, err)
+	}
+	klog.Infof("Signed the certificate revocation list of MultiClusterMesh %s/%s, revoking %d intermediate CAs", mesh.Namespace, mesh.Name, len(entries))
+
+	list.pem = bundle
+	return list, nil
+}
+
+// issuerCA returns the certificate and key of the CA issuer of the mesh, which signs the revocation list of the root
+// CA, or why the issuer cannot sign it.
+func (r *Reconciler) issuerCA(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh) (*x509.Certificate, crypto.Signer, string, error) {
+	issuerRef := mesh.Spec.Security.Trust.CertManager.IssuerRef
+	var spec certmanagerv1.IssuerSpec
+	namespace := mesh.Namespace
+	if issuerRef.Kind == certmanagerv1.ClusterIssuerKind {
+		issuer := &certmanagerv1.ClusterIssuer{}
+		if err := r.Get(ctx, key.Of(issuerRef.Name), issuer); err != nil {
+			return nil, nil, "", fmt.Errorf("failed to get ClusterIssuer %s: %w", issuerRef.Name, err)
+		}
+		spec, namespace = issuer.Spec, clusterIssuerSecretNamespace
+	} else {
+		issuer := &certmanagerv1.Issuer{}
+		if err := r.Get(ctx, key.Of(issuerRef.Name, mesh.Namespace), issuer); err != nil {
+			return nil, nil, "", fmt.Errorf("failed to get Issuer %s/%s: %w", mesh.Namespace, issuerRef.Name, err)
+		}
+		spec = issuer.Spec
+	}
+	if spec.CA == nil {
+		return nil, nil, fmt.Sprintf("%s %s is not a CA issuer, whose key the hub needs to sign certificate revocation lists", issuerRef.Kind, issuerRef.Name), nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, key.Of(spec.CA.SecretName, namespace), secret); err != nil {
+		return nil, nil, "", fmt.Errorf("failed to get the CA secret %s/%s of %s %s: %w", namespace, spec.CA.SecretName, issuerRef.Kind, issuerRef.Name, err)
+	}
+	cert, signer, err := parseKeyPair(secret)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("invalid CA secret %s/%s: %w", namespace, spec.CA.SecretName, err)
+	}
+	return cert, signer, "", nil
+}
+
+// intermediateCA is the certificate and key of the intermediate CA of a cluster.
+type intermediateCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+// intermediateCAs returns the intermediate CAs of the clusters of the mesh, except the revoked ones.
+func (r *Reconciler) intermediateCAs(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, revoked map[string]bool) (map[string]intermediateCA, error) {
+	secretList := &corev1.SecretList{}
+	if err := r.List(ctx, secretList, client.InNamespace(mesh.Namespace),
+		client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}, client.HasLabels{ClusterNameLabel}); err != nil {
+		return nil, fmt.Errorf("failed to list cacerts secrets: %w", err)
+	}
+	intermediates := map[string]intermediateCA{}
+	for _, secret := range secretList.Items {
+		clusterName := secret.Labels[ClusterNameLabel]
+		if secret.Name != getCacertsName(clusterName) {
+			continue
+		}
+		cert, signer, err := parseKeyPair(&secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cacerts secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		if !revoked[cert.SerialNumber.Text(16)] {
+			intermediates[clusterName] = intermediateCA{cert: cert, key: signer}
+		}
+	}
+	return intermediates, nil
+}
+
+// parseKeyPair parses the first certificate and the private key of a TLS secret.
+func parseKeyPair(secret *corev1.Secret) (*x509.Certificate, crypto.Signer, error) {
+	cert, err := parseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(secret.Data[corev1.TLSPrivateKeyKey])
+	if block == nil {
+		return nil, nil, fmt.Errorf("no PEM private key found in %s", corev1.TLSPrivateKeyKey)
+	}
+	var parsed any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", corev1.TLSPrivateKeyKey, err)
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+	return cert, signer, nil
+}
+
+// parseCertificate parses the first certificate of a PEM bundle.
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// signRevocationList signs a PEM encoded certificate revocation list of an issuer. The number of the list is its
+// issuing time, which only increases.
+func signRevocationList(issuer *x509.Certificate, signer crypto.Signer, entries []x509.RevocationListEntry, thisUpdate, nextUpdate time.Time) ([]byte, error) {
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(thisUpdate.Unix()),
+		ThisUpdate:                thisUpdate,
+		NextUpdate:                nextUpdate,
+		RevokedCertificateEntries: entries,
+	}, issuer, signer)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), nil
+}
+
+// recordRevokedCertificate adds the intermediate CA held by the cacerts secret of a cluster to the revoked certificates
+// of the mesh. It returns true if the certificate was already recorded, so the secret can be deleted without losing its
+// serial number: the status of the mesh is only written at the end of the reconcile.
+func recordRevokedCertificate(mesh *meshv1alpha1.MultiClusterMesh, clusterName string, secret *corev1.Secret) bool {
+	cert, err := parseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		klog.Warningf("Cannot revoke the intermediate CA of cluster %s from secret %s/%s: %v", clusterName, secret.Namespace, secret.Name, err)
+		return true
+	}
+	serial := cert.SerialNumber.Text(16)
+	if slices.ContainsFunc(mesh.Status.RevokedCertificates, func(revoked meshv1alpha1.RevokedCertificate) bool {
+		return revoked.SerialNumber == serial
+	}) {
+		return true
+	}
+	if !time.Now().Before(cert.NotAfter) {
+		return true
+	}
+	klog.Infof("Revoking intermediate CA %s of quarantined cluster %s", serial, clusterName)
+	mesh.Status.RevokedCertificates = append(mesh.Status.RevokedCertificates, meshv1alpha1.RevokedCertificate{
+		ClusterName:    clusterName,
+		SerialNumber:   serial,
+		RevocationTime: metav1.Now(),
+		ExpirationTime: metav1.NewTime(cert.NotAfter),
+	})
+	return false
+}
+
+// withRevocationList returns the data of a cacerts secret with the revocation list, or without any if nil.
+func withRevocationList(data map[string][]byte, crl []byte) map[string][]byte {
+	data = maps.Clone(data)
+	if data == nil {
+		data = map[string][]byte{}
+	}
+	delete(data, CacertsCRLKey)
+	if crl != nil {
+		data[CacertsCRLKey] = crl
+	}
+	return data
+}
+
+// cacertsSecretFromManifestWork decodes the cacerts secret delivered by a cacerts ManifestWork.
+func cacertsSecretFromManifestWork(work *workv1.ManifestWork) (*corev1.Secret, error) {
+	if len(work.Spec.Workload.Manifests) != 1 {
+		return nil, fmt.Errorf("expected a single manifest in ManifestWork %s/%s", work.Namespace, work.Name)
+	}
+	secret := &corev1.Secret{}
+	manifest := work.Spec.Workload.Manifests[0]
+	if manifest.Object != nil {
+		var ok bool
+		if secret, ok = manifest.Object.(*corev1.Secret); !ok {
+			return nil, fmt.Errorf("unexpected manifest %T in ManifestWork %s/%s", manifest.Object, work.Namespace, work.Name)
+		}
+		return secret, nil
+	}
+	if err := json.Unmarshal(manifest.Raw, secret); err != nil {
+		return nil, fmt.Errorf("failed to decode the manifest of ManifestWork %s/%s: %w", work.Namespace, work.Name, err)
+	}
+	return secret, nil
+}
+
+// distributeRevocationList updates the revocation list of the cacerts ManifestWork of a cluster whose changes are held,
+// leaving the rest of the ManifestWork as it is: the revocations of quarantined clusters are not held.
+func (r *Reconciler) distributeRevocationList(ctx context.Context, clusterName string, crl []byte) error {
+	work := &workv1.ManifestWork{}
+	if err := r.Get(ctx, key.Of(ManifestWorkNameCacerts, clusterName), work); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get cacerts ManifestWork for cluster %s: %w", clusterName, err)
+	}
+	secret, err := cacertsSecretFromManifestWork(work)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(secret.Data[CacertsCRLKey], crl) || !work.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	secret.Data = withRevocationList(secret.Data, crl)
+	updated := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{Name: work.Name, Namespace: work.Namespace, Labels: work.Labels},
+		Spec: workv1.ManifestWorkSpec{
+			Workload:     workv1.ManifestsTemplate{Manifests: []workv1.Manifest{{RawExtension: runtime.RawExtension{Object: secret}}}},
+			DeleteOption: work.Spec.DeleteOption,
+		},
+	}
+	klog.Infof("Updating the certificate revocation list of held cluster %s", clusterName)
+	if _, err := r.workApplier.Apply(ctx, updated); err != nil {
+		return fmt.Errorf("failed to apply cacerts ManifestWork on cluster %s: %w", clusterName, err)
+	}
+	return nil
+}
+
+// revokeTrust waits for the peers of a quarantined cluster to apply a revocation list revoking its intermediate CAs.
+// The peers without a cacerts ManifestWork do not trust the CA of the mesh. There is nothing to wait for when no
+// intermediate CA was ever issued to the cluster.
+func (r *Reconciler) revokeTrust(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string, peers []string, list *revocationList) ([]string, error) {
+	var revoking bool
+	for _, revoked := range mesh.Status.RevokedCertificates {
+		if revoked.ClusterName != clusterName {
+			continue
+		}
+		if !list.serials[revoked.SerialNumber] || list.pem == nil {
+			return []string{"certificate revocation list"}, nil
+		}
+		revoking = true
+	}
+	if !revoking {
+		return nil, nil
+	}
+
+	var waiting []string
+	for _, peer := range peers {
+		work := &workv1.ManifestWork{}
+		if err := r.Get(ctx, key.Of(ManifestWorkNameCacerts, peer), work); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get cacerts ManifestWork for cluster %s: %w", peer, err)
+		}
+		secret, err := cacertsSecretFromManifestWork(work)
+		if err != nil {
+			return nil, err
+		}
+		if reason, _ := manifestWorkState(work); reason != meshv1alpha1.ReasonApplied || !bytes.Equal(secret.Data[CacertsCRLKey], list.pem) {
+			waiting = append(waiting, "certificate revocation list on cluster "+peer)
+		}
+	}
+	return waiting, nil
+}
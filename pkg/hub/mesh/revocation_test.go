@@ -0,0 +1,216 @@
+package mesh
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+)
+
+// testCA is a CA certificate and its key.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCA issues a CA allowed to sign certificates and revocation lists, self-signed without a parent.
+func newTestCA(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) *testCA {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		t.Fatalf("failed to generate serial number: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "CA " + serial.Text(16)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(30 * Day),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	if parent == nil {
+		parent, parentKey = template, caKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &caKey.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return &testCA{cert: cert, key: caKey}
+}
+
+// secret returns a TLS secret holding the CA.
+func (ca *testCA) secret(name, namespace string, labels map[string]string) *corev1.Secret {
+	keyDER, _ := x509.MarshalECPrivateKey(ca.key)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}),
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		},
+	}
+}
+
+func TestEnsureRevocationList(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+	_ = meshv1alpha1.Install(scheme)
+
+	mesh := &meshv1alpha1.MultiClusterMesh{
+		ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: "mesh-ns", UID: "mesh"},
+		Spec: meshv1alpha1.MultiClusterMeshSpec{
+			ClusterSet: "set",
+			Security: meshv1alpha1.SecurityConfig{Trust: meshv1alpha1.TrustConfig{CertManager: meshv1alpha1.CertManagerConfig{
+				IssuerRef: meshv1alpha1.IssuerReference{Name: "root", Kind: "Issuer"},
+			}}},
+		},
+	}
+	root := newTestCA(t, nil, nil)
+	compromised := newTestCA(t, root.cert, root.key)
+	trusted := newTestCA(t, root.cert, root.key)
+	caIssuer := &certmanagerv1.Issuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "root", Namespace: mesh.Namespace},
+		Spec: certmanagerv1.IssuerSpec{IssuerConfig: certmanagerv1.IssuerConfig{
+			CA: &certmanagerv1.CAIssuer{SecretName: "root-ca"},
+		}},
+	}
+	selfSignedIssuer := &certmanagerv1.Issuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "root", Namespace: mesh.Namespace},
+		Spec: certmanagerv1.IssuerSpec{IssuerConfig: certmanagerv1.IssuerConfig{
+			SelfSigned: &certmanagerv1.SelfSignedIssuer{},
+		}},
+	}
+	revoked := []meshv1alpha1.RevokedCertificate{
+		{
+			ClusterName: "compromised", SerialNumber: compromised.cert.SerialNumber.Text(16),
+			RevocationTime: metav1.Now(), ExpirationTime: metav1.NewTime(compromised.cert.NotAfter),
+		},
+		{
+			ClusterName: "expired", SerialNumber: "1",
+			RevocationTime: metav1.NewTime(time.Now().Add(-2 * Day)), ExpirationTime: metav1.NewTime(time.Now().Add(-Day)),
+		},
+	}
+	objects := func(issuer *certmanagerv1.Issuer) []client.Object {
+		return []client.Object{
+			issuer,
+			root.secret("root-ca", mesh.Namespace, nil),
+			compromised.secret(getCacertsName("compromised"), mesh.Namespace, meshOwnedLabels(mesh, "compromised")),
+			trusted.secret(getCacertsName("trusted"), mesh.Namespace, meshOwnedLabels(mesh, "trusted")),
+		}
+	}
+
+	t.Run("signs the lists of the root and the trusted intermediate CAs", func(t *testing.T) {
+		ctx := context.Background()
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects(caIssuer)...).Build()
+		r := &Reconciler{Client: c, Scheme: scheme}
+		mesh := mesh.DeepCopy()
+		mesh.Status.RevokedCertificates = revoked
+
+		list, err := r.ensureRevocationList(ctx, mesh)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if list.problem != "" || len(list.serials) != 1 || !list.serials[revoked[0].SerialNumber] {
+			t.Fatalf("ensureRevocationList() = %+v, want the compromised intermediate CA revoked", list)
+		}
+		if len(mesh.Status.RevokedCertificates) != 1 {
+			t.Errorf("revoked certificates = %+v, want the expired one pruned", mesh.Status.RevokedCertificates)
+		}
+
+		var crls []*x509.RevocationList
+		for rest := list.pem; ; {
+			var block *pem.Block
+			if block, rest = pem.Decode(rest); block == nil {
+				break
+			}
+			crl, err := x509.ParseRevocationList(block.Bytes)
+			if err != nil {
+				t.Fatalf("failed to parse revocation list: %v", err)
+			}
+			crls = append(crls, crl)
+		}
+		if len(crls) != 2 {
+			t.Fatalf("got %d revocation lists, want the lists of the root and the trusted intermediate CA", len(crls))
+		}
+		if err := crls[0].CheckSignatureFrom(root.cert); err != nil {
+			t.Errorf("first list not signed by the root CA: %v", err)
+		}
+		if entries := crls[0].RevokedCertificateEntries; len(entries) != 1 || entries[0].SerialNumber.Cmp(compromised.cert.SerialNumber) != 0 {
+			t.Errorf("root CA revokes %+v, want the compromised intermediate CA", entries)
+		}
+		if err := crls[1].CheckSignatureFrom(trusted.cert); err != nil || len(crls[1].RevokedCertificateEntries) != 0 {
+			t.Errorf("second list is not an empty list of the trusted intermediate CA: %v", err)
+		}
+
+		again, err := r.ensureRevocationList(ctx, mesh)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(again.pem) != string(list.pem) {
+			t.Errorf("the revocation list was signed again for the same certificates")
+		}
+		cached := &corev1.Secret{}
+		if err := c.Get(ctx, key.Of(revocationListSecretName(mesh), mesh.Namespace), cached); err != nil {
+			t.Errorf("revocation list not cached: %v", err)
+		}
+	})
+
+	t.Run("reports issuers that cannot sign the list", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects(selfSignedIssuer)...).Build()
+		r := &Reconciler{Client: c, Scheme: scheme}
+		mesh := mesh.DeepCopy()
+		mesh.Status.RevokedCertificates = revoked[:1]
+
+		list, err := r.ensureRevocationList(context.Background(), mesh)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if list.pem != nil || list.problem == "" {
+			t.Errorf("ensureRevocationList() = %+v, want no list and a problem", list)
+		}
+	})
+
+	t.Run("distributes no list without revoked certificates", func(t *testing.T) {
+		r := &Reconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build(), Scheme: scheme}
+		mesh := mesh.DeepCopy()
+		mesh.Status.RevokedCertificates = revoked[1:]
+
+		list, err := r.ensureRevocationList(context.Background(), mesh)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if list.pem != nil || list.problem != "" || len(mesh.Status.RevokedCertificates) != 0 {
+			t.Errorf("ensureRevocationList() = %+v, want no list", list)
+		}
+		if requeueAfter := revocationRequeueAfter(mesh); requeueAfter != 0 {
+			t.Errorf("revocationRequeueAfter() = %v, want no requeue", requeueAfter)
+		}
+	})
+}
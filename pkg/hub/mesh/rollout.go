@@ -0,0 +1,317 @@
+package mesh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+)
+
+// AnnotationOperatorRevision records the revision of the operator configuration on the operator ManifestWork.
+const AnnotationOperatorRevision = "mesh.open-cluster-management.io/operator-revision"
+
+// operatorRollout is the outcome of planning an operator rollout for one reconciliation.
+type operatorRollout struct {
+	// revision identifies the desired operator configurations of all clusters
+	revision string
+	// clusters contains the desired operator of every cluster
+	clusters map[string]*clusterOperator
+	// held contains the clusters whose operator ManifestWork must be left at its current revision
+	held map[string]bool
+	// requeueAfter is the time until the next soak time ends, or zero
+	requeueAfter time.Duration
+}
+
+// clusterOperator is the desired operator installation of a cluster.
+type clusterOperator struct {
+	// profile is the name of the operator profile chosen for the cluster, empty if it uses spec.operator
+	profile   string
+	config    meshv1alpha1.OperatorConfig
+	manifests []workv1.Manifest
+	// revision identifies the operator configuration and manifests
+	revision string
+	// detection tells whether the add-on installs the operator or a pre-existing Subscription is found
+	detection operatorDetection
+}
+
+// clusterRolloutState is the rollout state of a cluster used to select the clusters to update.
+type clusterRolloutState struct {
+	name      string
+	canary    bool
+	updated   bool
+	failed    bool
+	succeeded bool
+}
+
+// operatorRevision returns a short hash identifying an operator configuration and its manifests.
+func operatorRevision(config meshv1alpha1.OperatorConfig, manifests []workv1.Manifest) string {
+	data, _ := json.Marshal(struct {
+		Config    meshv1alpha1.OperatorConfig `json:"config"`
+		Manifests []workv1.Manifest           `json:"manifests"`
+	}{config, manifests})
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:10]
+}
+
+// meshRevision identifies the operator revisions of the clusters of a mesh, which is the revision itself
+// when every cluster uses the same operator configuration.
+func meshRevision(revisions []string) string {
+	slices.Sort(revisions)
+	revisions = slices.Compact(revisions)
+	if len(revisions) == 1 {
+		return revisions[0]
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(revisions, ","))))[:10]
+}
+
+// planOperatorRollout decides which clusters receive the desired operator configuration in this reconciliation.
+// Without a rollout strategy, every cluster is updated at once. Otherwise, canary clusters are updated first and
+// the remaining clusters are updated at most maxConcurrency at a time once the canaries have succeeded,
+// until more than maxFailures clusters fail. Clusters without an operator ManifestWork are never held back, and clusters
+// with a pre-existing operator Subscription are left out of the rollout.
+func (r *Reconciler) planOperatorRollout(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster) (*operatorRollout, error) {
+	rollout := &operatorRollout{clusters: map[string]*clusterOperator{}, held: map[string]bool{}}
+	// The manifests are built once per operator configuration, keyed by its JSON encoding.
+	manifests := map[string][]workv1.Manifest{}
+	revisions := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		config, profile := clusterOperatorConfig(mesh, &cluster)
+		data, err := json.Marshal(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode operator config: %w", err)
+		}
+		configKey := string(data)
+		if _, ok := manifests[configKey]; !ok {
+			desired, err := r.desiredOperatorManifests(ctx, mesh, config)
+			if err != nil {
+				return nil, err
+			}
+			manifests[configKey] = desired
+		}
+		detection, err := r.detectExistingOperator(ctx, cluster.Name, config)
+		if err != nil {
+			return nil, err
+		}
+		operator := &clusterOperator{
+			profile:   profile,
+			config:    config,
+			manifests: manifests[configKey],
+			revision:  operatorRevision(config, manifests[configKey]),
+			detection: detection,
+		}
+		rollout.clusters[cluster.Name] = operator
+		revisions = append(revisions, operator.revision)
+	}
+	rollout.revision = meshRevision(revisions)
+
+	strategy := mesh.Spec.Rollout
+	if strategy == nil {
+		for _, cs := range mesh.Status.ClusterStatus {
+			mesh.SetClusterOperatorRollout(cs.ClusterName, nil)
+		}
+		mesh.Status.OperatorRollout = nil
+		meta.RemoveStatusCondition(&mesh.Status.Conditions, meshv1alpha1.ConditionOperatorRolledOut)
+		return rollout, nil
+	}
+
+	now := metav1.Now()
+	states := make([]clusterRolloutState, 0, len(clusters))
+	for _, cluster := range clusters {
+		operator := rollout.clusters[cluster.Name]
+		if !operator.detection.managedByAddon() {
+			// The operator of a cluster with a pre-existing Subscription is not installed by the add-on.
+			mesh.SetClusterOperatorRollout(cluster.Name, nil)
+			continue
+		}
+		state := clusterRolloutState{name: cluster.Name, canary: slices.Contains(strategy.CanaryClusters, cluster.Name)}
+		clusterStatus := &meshv1alpha1.ClusterOperatorRolloutStatus{Revision: operator.revision, State: meshv1alpha1.OperatorRolloutProgressing}
+
+		work := &workv1.ManifestWork{}
+		if err := r.Get(ctx, key.Of(OperatorManifestWorkName, cluster.Name), work); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get operator ManifestWork for cluster %s: %w", cluster.Name, err)
+			}
+			state.updated = true
+		} else if work.Annotations[AnnotationOperatorRevision] == operator.revision {
+			state.updated = true
+			healthy, failed := operatorHealth(operator.config, work)
+			previous := mesh.GetClusterOperatorRollout(cluster.Name)
+			switch {
+			case failed:
+				state.failed = true
+				clusterStatus.State = meshv1alpha1.OperatorRolloutFailed
+			case healthy:
+				clusterStatus.HealthyTime = &now
+				if previous != nil && previous.Revision == operator.revision && previous.HealthyTime != nil {
+					clusterStatus.HealthyTime = previous.HealthyTime
+				}
+				if remaining := strategy.SoakTime.Duration - now.Sub(clusterStatus.HealthyTime.Time); remaining > 0 {
+					if rollout.requeueAfter == 0 || remaining < rollout.requeueAfter {
+						rollout.requeueAfter = remaining
+					}
+				} else {
+					state.succeeded = true
+					clusterStatus.State = meshv1alpha1.OperatorRolloutSucceeded
+				}
+			}
+		} else {
+			clusterStatus.Revision = work.Annotations[AnnotationOperatorRevision]
+			clusterStatus.State = meshv1alpha1.OperatorRolloutPending
+		}
+
+		mesh.SetClusterOperatorRollout(cluster.Name, clusterStatus)
+		states = append(states, state)
+	}
+
+	selected, halted := selectRolloutClusters(strategy, states)
+	status := &meshv1alpha1.OperatorRolloutStatus{Revision: rollout.revision, Total: int32(len(states))}
+	var failed, pendingCanaries []string
+	for _, state := range states {
+		switch {
+		case selected[state.name]:
+			revision := rollout.clusters[state.name].revision
+			klog.Infof("Rolling out operator revision %s to cluster %s", revision, state.name)
+			mesh.SetClusterOperatorRollout(state.name, &meshv1alpha1.ClusterOperatorRolloutStatus{
+				Revision: revision, State: meshv1alpha1.OperatorRolloutProgressing,
+			})
+			status.Updated++
+		case !state.updated:
+			rollout.held[state.name] = true
+		default:
+			status.Updated++
+		}
+		if state.succeeded {
+			status.Succeeded++
+		}
+		if state.failed {
+			status.Failed++
+			failed = append(failed, state.name)
+		}
+		if state.canary && !state.succeeded {
+			pendingCanaries = append(pendingCanaries, state.name)
+		}
+	}
+	mesh.Status.OperatorRollout = status
+
+	switch {
+	case halted:
+		mesh.SetCondition(meshv1alpha1.ConditionOperatorRolledOut, metav1.ConditionFalse, meshv1alpha1.ReasonRolloutHalted,
+			"Rollout of operator revision %s is halted, it failed on clusters %s", rollout.revision, strings.Join(failed, ", "))
+	case status.Succeeded == status.Total:
+		mesh.SetCondition(meshv1alpha1.ConditionOperatorRolledOut, metav1.ConditionTrue, meshv1alpha1.ReasonRolloutComplete,
+			"Operator revision %s is rolled out to all %d clusters", rollout.revision, status.Total)
+	case len(pendingCanaries) > 0:
+		mesh.SetCondition(meshv1alpha1.ConditionOperatorRolledOut, metav1.ConditionFalse, meshv1alpha1.ReasonRolloutProgressing,
+			"Rolling out operator revision %s to canary clusters %s", rollout.revision, strings.Join(pendingCanaries, ", "))
+	default:
+		mesh.SetCondition(meshv1alpha1.ConditionOperatorRolledOut, metav1.ConditionFalse, meshv1alpha1.ReasonRolloutProgressing,
+			"Operator revision %s is rolled out to %d of %d clusters", rollout.revision, status.Succeeded, status.Total)
+	}
+
+	return rollout, nil
+}
+
+// selectRolloutClusters returns the clusters to update to the desired revision, and whether the rollout is halted.
+// Canary clusters are all updated together. The other clusters are only updated once every canary succeeded,
+// and only while fewer than maxConcurrency updated clusters are still progressing.
+func selectRolloutClusters(strategy *meshv1alpha1.RolloutStrategy, states []clusterRolloutState) (selected map[string]bool, halted bool) {
+	total := len(states)
+	maxConcurrency := total
+	if strategy.MaxConcurrency != nil {
+		maxConcurrency = max(scaledValue(strategy.MaxConcurrency, total, true), 1)
+	}
+	maxFailures := 0
+	if strategy.MaxFailures != nil {
+		maxFailures = scaledValue(strategy.MaxFailures, total, false)
+	}
+
+	canariesSucceeded := true
+	failed, progressing := 0, 0
+	for _, state := range states {
+		if state.updated && state.failed {
+			if state.canary {
+				return nil, true
+			}
+			failed++
+		}
+		if state.canary && !state.succeeded {
+			canariesSucceeded = false
+		}
+		if state.updated && !state.succeeded && !state.failed {
+			progressing++
+		}
+	}
+	if failed > maxFailures {
+		return nil, true
+	}
+
+	selected = map[string]bool{}
+	for _, state := range states {
+		if state.updated {
+			continue
+		}
+		if state.canary {
+			selected[state.name] = true
+		} else if canariesSucceeded && progressing < maxConcurrency {
+			selected[state.name] = true
+			progressing++
+		}
+	}
+	return selected, false
+}
+
+// scaledValue resolves an absolute number or a percentage of total, validated by the CRD schema.
+func scaledValue(value *intstr.IntOrString, total int, roundUp bool) int {
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(value, total, roundUp)
+	if err != nil {
+		klog.Warningf("Invalid rollout value %s: %v", value.String(), err)
+		return 0
+	}
+	return scaled
+}
+
+// operatorHealth reports whether the operator ManifestWork of an updated cluster is healthy or failed.
+// A cluster is healthy once the work agent applied the ManifestWork and OLM installed the latest CSV of the Subscription,
+// or the approved CSV if the InstallPlan approval is Manual, or every replica of the operator Deployment is updated and
+// ready in the Manifests install mode.
+// It failed if the work agent failed to apply the ManifestWork or OLM reports an installation failure.
+// Until the work agent observed the current generation of the ManifestWork, its feedback still describes the previous
+// revision, so the cluster is neither healthy nor failed.
+func operatorHealth(config meshv1alpha1.OperatorConfig, work *workv1.ManifestWork) (healthy, failed bool) {
+	if applied := meta.FindStatusCondition(work.Status.Conditions, workv1.WorkApplied); applied == nil || applied.ObservedGeneration < work.Generation {
+		return false, false
+	}
+
+	workReason, _ := manifestWorkState(work)
+	feedback := getManifestWorkFeedback(work)
+	status, installReason, _ := operatorInstallStatus(config, feedback)
+
+	switch installReason {
+	case meshv1alpha1.ReasonResolutionFailed, meshv1alpha1.ReasonCatalogUnhealthy,
+		meshv1alpha1.ReasonInstallPlanFailed, meshv1alpha1.ReasonUpgradeFailed:
+		return false, true
+	}
+	if workReason == meshv1alpha1.ReasonApplyFailed || workReason == meshv1alpha1.ReasonDegraded {
+		return false, true
+	}
+
+	upToDate := feedback[FeedbackCurrentCSV] == "" || feedback[FeedbackCurrentCSV] == feedback[FeedbackInstalledCSV]
+	if approvedCSV := config.GetApprovedCSV(); approvedCSV != "" && config.InstallMode != meshv1alpha1.OperatorInstallModeManifests {
+		// Newer CSVs of the channel are left pending, so the Subscription is up to date once the approved CSV is installed.
+		upToDate = feedback[FeedbackInstalledCSV] == approvedCSV
+	}
+	return workReason == meshv1alpha1.ReasonApplied && status == metav1.ConditionTrue && upToDate, false
+}
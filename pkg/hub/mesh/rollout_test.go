@@ -0,0 +1,184 @@
+package mesh
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestSelectRolloutClusters(t *testing.T) {
+	intOrString := func(value intstr.IntOrString) *intstr.IntOrString { return &value }
+	pending := func(name string) clusterRolloutState { return clusterRolloutState{name: name} }
+	progressing := func(name string) clusterRolloutState { return clusterRolloutState{name: name, updated: true} }
+	succeeded := func(name string) clusterRolloutState {
+		return clusterRolloutState{name: name, updated: true, succeeded: true}
+	}
+	failed := func(name string) clusterRolloutState {
+		return clusterRolloutState{name: name, updated: true, failed: true}
+	}
+	canary := func(state clusterRolloutState) clusterRolloutState {
+		state.canary = true
+		return state
+	}
+
+	tests := []struct {
+		name             string
+		strategy         meshv1alpha1.RolloutStrategy
+		states           []clusterRolloutState
+		expectedSelected []string
+		expectedHalted   bool
+	}{
+		{
+			name:             "updates all clusters without limits",
+			states:           []clusterRolloutState{pending("a"), pending("b"), pending("c")},
+			expectedSelected: []string{"a", "b", "c"},
+		},
+		{
+			name:             "updates only canaries first",
+			strategy:         meshv1alpha1.RolloutStrategy{CanaryClusters: []string{"b"}},
+			states:           []clusterRolloutState{pending("a"), canary(pending("b")), pending("c")},
+			expectedSelected: []string{"b"},
+		},
+		{
+			name:     "holds back clusters while canaries progress",
+			strategy: meshv1alpha1.RolloutStrategy{CanaryClusters: []string{"b"}},
+			states:   []clusterRolloutState{pending("a"), canary(progressing("b")), pending("c")},
+		},
+		{
+			name:             "continues once canaries succeeded",
+			strategy:         meshv1alpha1.RolloutStrategy{CanaryClusters: []string{"b"}, MaxConcurrency: intOrString(intstr.FromInt32(1))},
+			states:           []clusterRolloutState{pending("a"), canary(succeeded("b")), pending("c")},
+			expectedSelected: []string{"a"},
+		},
+		{
+			name:     "respects max concurrency",
+			strategy: meshv1alpha1.RolloutStrategy{MaxConcurrency: intOrString(intstr.FromInt32(2))},
+			states:   []clusterRolloutState{progressing("a"), progressing("b"), pending("c")},
+		},
+		{
+			name:             "resolves max concurrency percentages",
+			strategy:         meshv1alpha1.RolloutStrategy{MaxConcurrency: intOrString(intstr.FromString("50%"))},
+			states:           []clusterRolloutState{succeeded("a"), pending("b"), pending("c"), pending("d")},
+			expectedSelected: []string{"b", "c"},
+		},
+		{
+			name:           "halts on a failed canary",
+			strategy:       meshv1alpha1.RolloutStrategy{CanaryClusters: []string{"a"}, MaxFailures: intOrString(intstr.FromInt32(1))},
+			states:         []clusterRolloutState{canary(failed("a")), pending("b")},
+			expectedHalted: true,
+		},
+		{
+			name:           "halts on the first failure by default",
+			states:         []clusterRolloutState{failed("a"), pending("b")},
+			expectedHalted: true,
+		},
+		{
+			name:             "tolerates failures up to max failures",
+			strategy:         meshv1alpha1.RolloutStrategy{MaxFailures: intOrString(intstr.FromInt32(1)), MaxConcurrency: intOrString(intstr.FromInt32(1))},
+			states:           []clusterRolloutState{failed("a"), pending("b"), pending("c")},
+			expectedSelected: []string{"b"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			selected, halted := selectRolloutClusters(&tc.strategy, tc.states)
+			if halted != tc.expectedHalted {
+				t.Errorf("selectRolloutClusters() halted = %v, want %v", halted, tc.expectedHalted)
+			}
+			if got := slices.Sorted(maps.Keys(selected)); !slices.Equal(got, tc.expectedSelected) {
+				t.Errorf("selectRolloutClusters() selected = %v, want %v", got, tc.expectedSelected)
+			}
+		})
+	}
+}
+
+func TestOperatorHealth(t *testing.T) {
+	installed := func(installedCSV, currentCSV string) map[string]string {
+		return map[string]string{FeedbackInstalledCSV: installedCSV, FeedbackCurrentCSV: currentCSV}
+	}
+	manual := meshv1alpha1.OperatorConfig{InstallPlanApproval: operatorsv1alpha1.ApprovalManual, StartingCSV: "sailoperator.v1.1.0"}
+
+	tests := []struct {
+		name               string
+		config             meshv1alpha1.OperatorConfig
+		observedGeneration int64
+		feedback           map[string]string
+		expectedHealthy    bool
+		expectedFailed     bool
+	}{
+		{
+			name:               "healthy at the latest CSV",
+			observedGeneration: 2,
+			feedback:           installed("sailoperator.v1.0.0", "sailoperator.v1.0.0"),
+			expectedHealthy:    true,
+		},
+		{
+			name:               "upgrading to the latest CSV",
+			observedGeneration: 2,
+			feedback:           installed("sailoperator.v1.0.0", "sailoperator.v1.1.0"),
+		},
+		{
+			name:               "stale feedback of the previous revision",
+			observedGeneration: 1,
+			feedback:           installed("sailoperator.v1.0.0", "sailoperator.v1.0.0"),
+		},
+		{
+			name:               "stale failure of the previous revision",
+			observedGeneration: 1,
+			feedback:           map[string]string{FeedbackResolutionFailed: "True"},
+		},
+		{
+			name:               "failed resolution",
+			observedGeneration: 2,
+			feedback:           map[string]string{FeedbackResolutionFailed: "True"},
+			expectedFailed:     true,
+		},
+		{
+			name:               "approved CSV not installed yet",
+			config:             manual,
+			observedGeneration: 2,
+			feedback:           installed("sailoperator.v1.0.0", "sailoperator.v1.0.0"),
+		},
+		{
+			name:               "approved CSV installed while newer CSVs are pending",
+			config:             manual,
+			observedGeneration: 2,
+			feedback:           installed("sailoperator.v1.1.0", "sailoperator.v1.2.0"),
+			expectedHealthy:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var values []workv1.FeedbackValue
+			for name, value := range tc.feedback {
+				values = append(values, workv1.FeedbackValue{Name: name, Value: workv1.FieldValue{Type: workv1.String, String: &value}})
+			}
+			work := &workv1.ManifestWork{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Status: workv1.ManifestWorkStatus{
+					Conditions: []metav1.Condition{
+						{Type: workv1.WorkApplied, Status: metav1.ConditionTrue, ObservedGeneration: tc.observedGeneration},
+						{Type: workv1.WorkAvailable, Status: metav1.ConditionTrue, ObservedGeneration: tc.observedGeneration},
+					},
+					ResourceStatus: workv1.ManifestResourceStatus{Manifests: []workv1.ManifestCondition{{
+						StatusFeedbacks: workv1.StatusFeedbackResult{Values: values},
+					}}},
+				},
+			}
+
+			healthy, failed := operatorHealth(tc.config, work)
+			if healthy != tc.expectedHealthy || failed != tc.expectedFailed {
+				t.Errorf("operatorHealth() = healthy %v, failed %v, want healthy %v, failed %v", healthy, failed, tc.expectedHealthy, tc.expectedFailed)
+			}
+		})
+	}
+}
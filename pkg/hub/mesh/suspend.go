@@ -0,0 +1,69 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+// AnnotationMaintenance puts a ManagedCluster in maintenance mode: the add-on holds all changes to the cluster, neither
+// applying nor deleting its ManifestWorks nor reconciling its ManagedServiceAccount and Certificate, until the
+// annotation is removed. The remote secrets of the peers are held in direct ManifestWorks, since the
+// ManifestWorkReplicaSets of the peers cannot hold a single cluster. Only the revocations of quarantined clusters
+// still reach the cluster.
+const AnnotationMaintenance = "mesh.open-cluster-management.io/maintenance"
+
+// inMaintenance reports whether a ManagedCluster is in maintenance mode.
+func inMaintenance(cluster *clusterv1.ManagedCluster) bool {
+	return cluster.Annotations[AnnotationMaintenance] == "true"
+}
+
+// clustersInMaintenance returns the names of the ManagedClusters in maintenance mode, including the ones that left the
+// ClusterSet of the mesh, whose ManifestWorks are then kept as well.
+func (r *Reconciler) clustersInMaintenance(ctx context.Context) (map[string]bool, error) {
+	clusterList := &clusterv1.ManagedClusterList{}
+	if err := r.List(ctx, clusterList); err != nil {
+		return nil, fmt.Errorf("failed to list ManagedClusters: %w", err)
+	}
+	maintenance := map[string]bool{}
+	for _, cluster := range clusterList.Items {
+		if inMaintenance(&cluster) {
+			maintenance[cluster.Name] = true
+		}
+	}
+	return maintenance, nil
+}
+
+// setSuspendedConditions reports on the mesh and on its clusters whether changes to their ManifestWorks are held,
+// because the mesh is suspended or the clusters are in maintenance mode.
+func setSuspendedConditions(mesh *meshv1alpha1.MultiClusterMesh, clusters []clusterv1.ManagedCluster) {
+	var held []string
+	for _, cluster := range clusters {
+		switch {
+		case mesh.Spec.Suspend:
+			mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionSuspended, metav1.ConditionTrue, meshv1alpha1.ReasonMeshSuspended,
+				"Changes are held while the mesh is suspended, except the revocations of quarantined clusters")
+		case inMaintenance(&cluster):
+			held = append(held, cluster.Name)
+			mesh.SetClusterCondition(cluster.Name, meshv1alpha1.ConditionSuspended, metav1.ConditionTrue, meshv1alpha1.ReasonClusterMaintenance,
+				"Changes are held while the cluster is in maintenance, except the revocations of quarantined clusters")
+		}
+	}
+
+	switch {
+	case mesh.Spec.Suspend:
+		mesh.SetCondition(meshv1alpha1.ConditionSuspended, metav1.ConditionTrue, meshv1alpha1.ReasonMeshSuspended,
+			"Changes are held on all clusters while spec.suspend is set, except the revocations of quarantined clusters")
+	case len(held) > 0:
+		mesh.SetCondition(meshv1alpha1.ConditionSuspended, metav1.ConditionTrue, meshv1alpha1.ReasonClusterMaintenance,
+			"Changes are held on clusters in maintenance, except the revocations of quarantined clusters: %s", strings.Join(held, ", "))
+	default:
+		mesh.SetCondition(meshv1alpha1.ConditionSuspended, metav1.ConditionFalse, meshv1alpha1.ReasonNotSuspended,
+			"Changes are applied to all clusters")
+	}
+}
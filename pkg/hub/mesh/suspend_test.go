@@ -0,0 +1,89 @@
+package mesh
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestSetSuspendedConditions(t *testing.T) {
+	cluster := func(name string, maintenance bool) clusterv1.ManagedCluster {
+		c := clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if maintenance {
+			c.Annotations = map[string]string{AnnotationMaintenance: "true"}
+		}
+		return c
+	}
+
+	tests := []struct {
+		name            string
+		suspend         bool
+		clusters        []clusterv1.ManagedCluster
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedHeld    []string
+		expectedApplied []string
+	}{
+		{
+			name:            "not suspended",
+			clusters:        []clusterv1.ManagedCluster{cluster("east", false), cluster("west", false)},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  meshv1alpha1.ReasonNotSuspended,
+			expectedApplied: []string{"east", "west"},
+		},
+		{
+			name:           "mesh suspended",
+			suspend:        true,
+			clusters:       []clusterv1.ManagedCluster{cluster("east", false), cluster("west", true)},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: meshv1alpha1.ReasonMeshSuspended,
+			expectedHeld:   []string{"east", "west"},
+		},
+		{
+			name:            "cluster in maintenance",
+			clusters:        []clusterv1.ManagedCluster{cluster("east", false), cluster("west", true)},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  meshv1alpha1.ReasonClusterMaintenance,
+			expectedHeld:    []string{"west"},
+			expectedApplied: []string{"east"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mesh := &meshv1alpha1.MultiClusterMesh{Spec: meshv1alpha1.MultiClusterMeshSpec{Suspend: tc.suspend}}
+			for _, c := range tc.clusters {
+				mesh.Status.ClusterStatus = append(mesh.Status.ClusterStatus, meshv1alpha1.ClusterMeshStatus{ClusterName: c.Name})
+			}
+			setSuspendedConditions(mesh, tc.clusters)
+			clusterConditions := func(name string) []metav1.Condition {
+				for _, cs := range mesh.Status.ClusterStatus {
+					if cs.ClusterName == name {
+						return cs.Conditions
+					}
+				}
+				return nil
+			}
+
+			condition := meta.FindStatusCondition(mesh.Status.Conditions, meshv1alpha1.ConditionSuspended)
+			if condition == nil || condition.Status != tc.expectedStatus || condition.Reason != tc.expectedReason {
+				t.Errorf("setSuspendedConditions() condition = %v, want %s/%s", condition, tc.expectedStatus, tc.expectedReason)
+			}
+			for _, name := range tc.expectedHeld {
+				clusterCondition := meta.FindStatusCondition(clusterConditions(name), meshv1alpha1.ConditionSuspended)
+				if clusterCondition == nil || clusterCondition.Status != metav1.ConditionTrue {
+					t.Errorf("setSuspendedConditions() cluster %s condition = %v, want True", name, clusterCondition)
+				}
+			}
+			for _, name := range tc.expectedApplied {
+				if clusterCondition := meta.FindStatusCondition(clusterConditions(name), meshv1alpha1.ConditionSuspended); clusterCondition != nil {
+					t.Errorf("setSuspendedConditions() cluster %s condition = %v, want none", name, clusterCondition)
+				}
+			}
+		})
+	}
+}
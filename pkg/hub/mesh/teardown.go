@@ -0,0 +1,394 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+	msav1beta1 "open-cluster-management.io/managed-serviceaccount/apis/authentication/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+)
+
+// teardownRequeueInterval is how often a mesh checks the progress of the clusters it tears down. The ManifestWorks
+// of a cluster that left the ClusterSet no longer map to the mesh, so their deletion does not trigger a reconcile.
+const teardownRequeueInterval = 15 * time.Second
+
+// teardownStep is a step of the removal of a cluster that left the mesh. Each step only starts once the resources of
+// the previous one are gone from the clusters, so that traffic drains before the control plane is removed.
+type teardownStep int
+
+const (
+	// teardownRemoteSecret revokes the remote secret of the cluster from its peers
+	teardownRemoteSecret teardownStep = iota
+	// teardownPeerSecrets removes the remote secrets of the peers from the cluster
+	teardownPeerSecrets
+	// teardownCacerts removes the cacerts secret
+	teardownCacerts
+	// teardownControlPlaneNamespace removes the control plane namespace
+	teardownControlPlaneNamespace
+	// teardownOperator removes the operator, once no mesh of the ClusterSet needs the cluster anymore
+	teardownOperator
+)
+
+var teardownReasons = []string{
+	teardownRemoteSecret:          meshv1alpha1.ReasonRevokingRemoteSecret,
+	teardownPeerSecrets:           meshv1alpha1.ReasonRemovingPeerSecrets,
+	teardownCacerts:               meshv1alpha1.ReasonRemovingCacerts,
+	teardownControlPlaneNamespace: meshv1alpha1.ReasonRemovingControlPlaneNamespace,
+	teardownOperator:              meshv1alpha1.ReasonRemovingOperator,
+}
+
+// clusterTeardown is the progress of the removal of a cluster: the current step, what it waits for, and since when.
+// The ManagedCluster is nil once deleted.
+type clusterTeardown struct {
+	step    teardownStep
+	waiting []string
+	since   metav1.Time
+	cluster *clusterv1.ManagedCluster
+}
+
+// teardownRemovedClusters removes the resources of the clusters that left the mesh step by step, and returns the
+// progress of the clusters whose removal is not complete. The held clusters are left untouched. The resources of deleted
+// ManagedClusters are removed at once, since no traffic is left to drain.
+func (r *Reconciler) teardownRemovedClusters(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, retained []clusterv1.ManagedCluster, held map[string]bool) (map[string]*clusterTeardown, error) {
+	leaving, err := r.leavingClusters(ctx, mesh, retained)
+	if err != nil {
+		return nil, err
+	}
+
+	teardowns := map[string]*clusterTeardown{}
+	for _, clusterName := range leaving {
+		if held[clusterName] {
+			continue
+		}
+
+		cluster := &clusterv1.ManagedCluster{}
+		if err := r.Get(ctx, key.Of(clusterName), cluster); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get ManagedCluster %s: %w", clusterName, err)
+			}
+			cluster = nil
+		}
+
+		teardown, err := r.teardownCluster(ctx, mesh, clusterName, cluster != nil, held)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove cluster %s from the mesh: %w", clusterName, err)
+		}
+		if teardown == nil {
+			klog.Infof("Removed cluster %s from MultiClusterMesh %s/%s", clusterName, mesh.Namespace, mesh.Name)
+			continue
+		}
+
+		teardown.cluster = cluster
+		teardown.since = metav1.Now()
+		for _, cs := range mesh.Status.ClusterStatus {
+			if c := meta.FindStatusCondition(cs.Conditions, meshv1alpha1.ConditionRemoving); cs.ClusterName == clusterName &&
+				c != nil && c.Reason == teardownReasons[teardown.step] {
+				teardown.since = c.LastTransitionTime
+			}
+		}
+		klog.V(4).Infof("Removing cluster %s from MultiClusterMesh %s/%s: %s, waiting for %s", clusterName, mesh.Namespace, mesh.Name,
+			teardownReasons[teardown.step], strings.Join(teardown.waiting, ", "))
+		teardowns[clusterName] = teardown
+	}
+	return teardowns, nil
+}
+
+// leavingClusters returns the clusters that are not retained by the mesh but still have resources of the mesh, or are
+// still reported in its status while the shared ManifestWorks are removed.
+func (r *Reconciler) leavingClusters(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, retained []clusterv1.ManagedCluster) ([]string, error) {
+	members := clusterNameSet(retained)
+	leaving := map[string]bool{}
+	add := func(clusterName string) {
+		if clusterName != "" && !members[clusterName] {
+			leaving[clusterName] = true
+		}
+	}
+
+	selector := client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, selector); err != nil {
+		return nil, fmt.Errorf("failed to list mesh-owned ManifestWorks: %w", err)
+	}
+	for _, work := range workList.Items {
+		add(work.Namespace)
+	}
+	msaList := &msav1beta1.ManagedServiceAccountList{}
+	if err := r.List(ctx, msaList, selector); err != nil {
+		return nil, fmt.Errorf("failed to list ManagedServiceAccounts: %w", err)
+	}
+	for _, msa := range msaList.Items {
+		add(msa.Namespace)
+	}
+	mwrsetList := &workv1alpha1.ManifestWorkReplicaSetList{}
+	if err := r.List(ctx, mwrsetList, client.InNamespace(mesh.Namespace), selector); err != nil {
+		return nil, fmt.Errorf("failed to list ManifestWorkReplicaSets: %w", err)
+	}
+	for _, mwrset := range mwrsetList.Items {
+		add(mwrset.Labels[ClusterNameLabel])
+	}
+	certList := &certmanagerv1.CertificateList{}
+	if err := r.List(ctx, certList, client.InNamespace(mesh.Namespace), selector); err != nil {
+		return nil, fmt.Errorf("failed to list Certificates: %w", err)
+	}
+	for _, cert := range certList.Items {
+		add(cert.Labels[ClusterNameLabel])
+	}
+	for _, cs := range mesh.Status.ClusterStatus {
+		if meta.IsStatusConditionTrue(cs.Conditions, meshv1alpha1.ConditionRemoving) {
+			add(cs.ClusterName)
+		}
+	}
+
+	return slices.Sorted(maps.Keys(leaving)), nil
+}
+
+// teardownCluster runs the removal steps of a cluster in order, deleting the resources of each step, and stops at the
+// first step whose resources are not gone yet unless told not to drain. It returns nil once the removal is complete.
+func (r *Reconciler) teardownCluster(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string, drain bool, held map[string]bool) (*clusterTeardown, error) {
+	steps := []func(context.Context, *meshv1alpha1.MultiClusterMesh, string) ([]string, error){
+		teardownRemoteSecret: func(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string) ([]string, error) {
+			return r.revokeRemoteSecret(ctx, mesh, clusterName, held)
+		},
+		teardownPeerSecrets:           r.removePeerSecrets,
+		teardownCacerts:               r.removeCacerts,
+		teardownControlPlaneNamespace: r.removeControlPlaneNamespace,
+		teardownOperator:              r.awaitOperatorRemoval,
+	}
+
+	var current *clusterTeardown
+	for step, run := range steps {
+		waiting, err := run(ctx, mesh, clusterName)
+		if err != nil {
+			return nil, err
+		}
+		if len(waiting) == 0 || current != nil {
+			continue
+		}
+		current = &clusterTeardown{step: teardownStep(step), waiting: waiting}
+		if drain {
+			break
+		}
+	}
+	return current, nil
+}
+
+// revokeRemoteSecret deletes the ManifestWorkReplicaSet distributing the remote secret of the cluster along with the
+// ManifestWorks delivering it to the peers, generated or applied directly, and waits for them to be gone: the work
+// agents of the peers only release the ManifestWorks once they deleted the secret. The direct ManifestWorks of the held
+// peers are left to ensureDirectRemoteSecrets, which deletes them once the peers are released.
+func (r *Reconciler) revokeRemoteSecret(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string, held map[string]bool) ([]string, error) {
+	name := RemoteSecretDistributionName(mesh, clusterName)
+	var waiting []string
+
+	mwrset := &workv1alpha1.ManifestWorkReplicaSet{}
+	if err := r.Get(ctx, key.Of(name, mesh.Namespace), mwrset); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get ManifestWorkReplicaSet %s/%s: %w", mesh.Namespace, name, err)
+		}
+	} else {
+		waiting = append(waiting, fmt.Sprintf("ManifestWorkReplicaSet %s/%s", mesh.Namespace, name))
+		if mwrset.DeletionTimestamp.IsZero() {
+			klog.Infof("Revoking the remote secret of cluster %s from its peers", clusterName)
+			if err := client.IgnoreNotFound(r.Delete(ctx, mwrset)); err != nil {
+				return nil, fmt.Errorf("failed to delete ManifestWorkReplicaSet %s/%s: %w", mesh.Namespace, name, err)
+			}
+		}
+	}
+
+	placement := &clusterv1beta1.Placement{}
+	placement.Name, placement.Namespace = name, mesh.Namespace
+	if err := client.IgnoreNotFound(r.Delete(ctx, placement)); err != nil {
+		return nil, fmt.Errorf("failed to delete Placement %s/%s: %w", mesh.Namespace, name, err)
+	}
+
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, client.MatchingLabels{workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey: mesh.Namespace + "." + name}); err != nil {
+		return nil, fmt.Errorf("failed to list ManifestWorks of ManifestWorkReplicaSet %s/%s: %w", mesh.Namespace, name, err)
+	}
+	for _, work := range workList.Items {
+		waiting = append(waiting, "remote secret on cluster "+work.Namespace)
+		if err := r.deleteManifestWork(ctx, &work); err != nil {
+			return nil, err
+		}
+	}
+
+	directList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, directList, client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}); err != nil {
+		return nil, fmt.Errorf("failed to list mesh-owned ManifestWorks: %w", err)
+	}
+	for _, work := range directList.Items {
+		if work.Name != ManifestWorkNamePeerSecretPrefix+clusterName || held[work.Namespace] {
+			continue
+		}
+		waiting = append(waiting, "remote secret on cluster "+work.Namespace)
+		if err := r.deleteManifestWork(ctx, &work); err != nil {
+			return nil, err
+		}
+	}
+	return waiting, nil
+}
+
+// removePeerSecrets deletes the ManagedServiceAccount of the cluster, no longer distributed to any peer, and the
+// ManifestWorks delivering the remote secrets of the peers to the cluster, generated from their ManifestWorkReplicaSets
+// or applied directly while the cluster was pending removal or revoking its own remote secret.
+func (r *Reconciler) removePeerSecrets(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string) ([]string, error) {
+	selector := client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}
+
+	msaList := &msav1beta1.ManagedServiceAccountList{}
+	if err := r.List(ctx, msaList, client.InNamespace(clusterName), selector); err != nil {
+		return nil, fmt.Errorf("failed to list ManagedServiceAccounts: %w", err)
+	}
+	for _, msa := range msaList.Items {
+		if !msa.DeletionTimestamp.IsZero() {
+			continue
+		}
+		klog.Infof("Deleting ManagedServiceAccount %s/%s of cluster %s", msa.Namespace, msa.Name, clusterName)
+		if err := client.IgnoreNotFound(r.Delete(ctx, &msa)); err != nil {
+			return nil, fmt.Errorf("failed to delete ManagedServiceAccount %s/%s: %w", msa.Namespace, msa.Name, err)
+		}
+	}
+
+	// The ManifestWorks generated from the ManifestWorkReplicaSets of the mesh carry their "<namespace>.<name>".
+	mwrsetList := &workv1alpha1.ManifestWorkReplicaSetList{}
+	if err := r.List(ctx, mwrsetList, client.InNamespace(mesh.Namespace), selector); err != nil {
+		return nil, fmt.Errorf("failed to list ManifestWorkReplicaSets: %w", err)
+	}
+	distributions := map[string]string{}
+	for _, mwrset := range mwrsetList.Items {
+		distributions[mwrset.Namespace+"."+mwrset.Name] = mwrset.Labels[ClusterNameLabel]
+	}
+
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, client.InNamespace(clusterName), client.HasLabels{workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey}); err != nil {
+		return nil, fmt.Errorf("failed to list ManifestWorks for cluster %s: %w", clusterName, err)
+	}
+	var waiting []string
+	for _, work := range workList.Items {
+		peer, ok := distributions[work.Labels[workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey]]
+		if !ok {
+			continue
+		}
+		waiting = append(waiting, "remote secret of cluster "+peer)
+		if err := r.deleteManifestWork(ctx, &work); err != nil {
+			return nil, err
+		}
+	}
+
+	direct, err := r.removeMeshOwnedManifestWorks(ctx, mesh, clusterName, func(name string) bool {
+		return strings.HasPrefix(name, ManifestWorkNamePeerSecretPrefix)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(waiting, direct...), nil
+}
+
+// removeCacerts deletes the Certificate of the cluster and the ManifestWork delivering the cacerts secret to it.
+func (r *Reconciler) removeCacerts(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string) ([]string, error) {
+	certList := &certmanagerv1.CertificateList{}
+	if err := r.List(ctx, certList, client.InNamespace(mesh.Namespace),
+		client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace, ClusterNameLabel: clusterName}); err != nil {
+		return nil, fmt.Errorf("failed to list Certificates: %w", err)
+	}
+	for _, cert := range certList.Items {
+		klog.Infof("Deleting Certificate %s/%s (cluster %s left the mesh)", cert.Namespace, cert.Name, clusterName)
+		if err := client.IgnoreNotFound(r.Delete(ctx, &cert)); err != nil {
+			return nil, fmt.Errorf("failed to delete Certificate %s/%s: %w", cert.Namespace, cert.Name, err)
+		}
+	}
+
+	return r.removeMeshOwnedManifestWorks(ctx, mesh, clusterName, func(name string) bool {
+		return name == ManifestWorkNameCacerts
+	})
+}
+
+// removeControlPlaneNamespace deletes the remaining mesh-owned ManifestWorks of the cluster, among which the control
+// plane namespace.
+func (r *Reconciler) removeControlPlaneNamespace(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string) ([]string, error) {
+	return r.removeMeshOwnedManifestWorks(ctx, mesh, clusterName, func(string) bool { return true })
+}
+
+// awaitOperatorRemoval waits for the ManifestWorks shared by the meshes of the ClusterSet, which cleanupManifestWorks
+// deletes once no mesh needs the cluster anymore.
+func (r *Reconciler) awaitOperatorRemoval(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string) ([]string, error) {
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, client.InNamespace(clusterName),
+		client.MatchingLabels{ManagedByLabel: ManagedByValue, ClusterSetLabel: mesh.Spec.ClusterSet}); err != nil {
+		return nil, fmt.Errorf("failed to list ManifestWorks for cluster %s: %w", clusterName, err)
+	}
+	var waiting []string
+	for _, work := range workList.Items {
+		if work.Labels[MeshNameLabel] == "" {
+			waiting = append(waiting, "ManifestWork "+work.Name)
+		}
+	}
+	return waiting, nil
+}
+
+// removeMeshOwnedManifestWorks deletes the mesh-owned ManifestWorks of the cluster matching the name filter, and returns
+// the ones not gone yet.
+func (r *Reconciler) removeMeshOwnedManifestWorks(ctx context.Context, mesh *meshv1alpha1.MultiClusterMesh, clusterName string, match func(string) bool) ([]string, error) {
+	workList := &workv1.ManifestWorkList{}
+	if err := r.List(ctx, workList, client.InNamespace(clusterName),
+		client.MatchingLabels{MeshNameLabel: mesh.Name, MeshNamespaceLabel: mesh.Namespace}); err != nil {
+		return nil, fmt.Errorf("failed to list mesh-owned ManifestWorks for cluster %s: %w", clusterName, err)
+	}
+	var waiting []string
+	for _, work := range workList.Items {
+		if !match(work.Name) {
+			continue
+		}
+		waiting = append(waiting, "ManifestWork "+work.Name)
+		if err := r.deleteManifestWork(ctx, &work); err != nil {
+			return nil, err
+		}
+	}
+	return waiting, nil
+}
+
+// deleteManifestWork deletes a ManifestWork unless it is already being deleted.
+func (r *Reconciler) deleteManifestWork(ctx context.Context, work *workv1.ManifestWork) error {
+	if !work.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	klog.Infof("Deleting ManifestWork %s/%s", work.Namespace, work.Name)
+	if err := r.workApplier.Delete(ctx, work.Namespace, work.Name); err != nil {
+		return fmt.Errorf("failed to delete ManifestWork %s/%s: %w", work.Namespace, work.Name, err)
+	}
+	return nil
+}
+
+// setTeardownStatus adds the clusters being removed to the status of the mesh, with the current step as the reason of
+// their Removing condition.
+func setTeardownStatus(mesh *meshv1alpha1.MultiClusterMesh, teardowns map[string]*clusterTeardown) {
+	for _, clusterName := range slices.Sorted(maps.Keys(teardowns)) {
+		teardown := teardowns[clusterName]
+		mesh.Status.ClusterStatus = append(mesh.Status.ClusterStatus, meshv1alpha1.ClusterMeshStatus{
+			ClusterName: clusterName,
+			Conditions: []metav1.Condition{{
+				Type:               meshv1alpha1.ConditionRemoving,
+				Status:             metav1.ConditionTrue,
+				Reason:             teardownReasons[teardown.step],
+				ObservedGeneration: mesh.Generation,
+				LastTransitionTime: teardown.since,
+				Message:            "Waiting for the removal of " + strings.Join(teardown.waiting, ", "),
+			}},
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package mesh
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+	msav1beta1 "open-cluster-management.io/managed-serviceaccount/apis/authentication/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
+)
+
+func TestTeardownCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = workv1.Install(scheme)
+	_ = workv1alpha1.Install(scheme)
+	_ = msav1beta1.AddToScheme(scheme)
+	_ = certmanagerv1.AddToScheme(scheme)
+	_ = clusterv1beta1.Install(scheme)
+
+	mesh := &meshv1alpha1.MultiClusterMesh{
+		ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: "mesh-ns"},
+		Spec:       meshv1alpha1.MultiClusterMeshSpec{ClusterSet: "set"},
+	}
+	labels := meshOwnedLabels(mesh, "leaving")
+	// The ManifestWorks are already being deleted, and remain until the work agent releases them.
+	terminating := func(name, namespace string, labels map[string]string) *workv1.ManifestWork {
+		return &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: namespace, Labels: labels,
+			DeletionTimestamp: &metav1.Time{Time: metav1.Now().Time}, Finalizers: []string{"test"},
+		}}
+	}
+	remoteSecret := &workv1alpha1.ManifestWorkReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: RemoteSecretDistributionName(mesh, "leaving"), Namespace: mesh.Namespace, Labels: labels,
+	}}
+	peerSecret := &workv1alpha1.ManifestWorkReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: RemoteSecretDistributionName(mesh, "peer"), Namespace: mesh.Namespace, Labels: meshOwnedLabels(mesh, "peer"),
+	}}
+	msa := &msav1beta1.ManagedServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: msaName(mesh), Namespace: "leaving", Labels: labels}}
+	cert := &certmanagerv1.Certificate{ObjectMeta: metav1.ObjectMeta{Name: "cacerts-leaving", Namespace: mesh.Namespace, Labels: labels}}
+	directPeerSecret := &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{
+		Name: ManifestWorkNamePeerSecretPrefix + "peer", Namespace: "leaving", Labels: labels,
+	}}
+
+	tests := []struct {
+		name            string
+		objects         []client.Object
+		drain           bool
+		held            map[string]bool
+		expectedStep    *teardownStep
+		expectedWaiting []string
+		expectedDeleted []client.Object
+		expectedKept    []client.Object
+	}{
+		{
+			name:            "revokes the remote secret first",
+			objects:         []client.Object{remoteSecret, msa, cert, directPeerSecret, terminating(ManifestWorkNameCacerts, "leaving", labels)},
+			drain:           true,
+			expectedStep:    ptr.To(teardownRemoteSecret),
+			expectedDeleted: []client.Object{remoteSecret},
+			expectedKept:    []client.Object{msa, cert, directPeerSecret},
+		},
+		{
+			name: "waits for the remote secret to be gone from the peers",
+			objects: []client.Object{msa, cert, terminating("peer-secret", "peer", map[string]string{
+				workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey: mesh.Namespace + "." + RemoteSecretDistributionName(mesh, "leaving"),
+			})},
+			drain:        true,
+			expectedStep: ptr.To(teardownRemoteSecret),
+			expectedKept: []client.Object{msa, cert},
+		},
+		{
+			name: "waits for the remote secret delivered directly except to the held peers",
+			objects: []client.Object{
+				msa, cert,
+				terminating(ManifestWorkNamePeerSecretPrefix+"leaving", "pending", meshOwnedLabels(mesh, "pending")),
+				&workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: ManifestWorkNamePeerSecretPrefix + "leaving", Namespace: "paused", Labels: meshOwnedLabels(mesh, "paused")}},
+			},
+			drain:           true,
+			held:            map[string]bool{"paused": true},
+			expectedStep:    ptr.To(teardownRemoteSecret),
+			expectedWaiting: []string{"remote secret on cluster pending"},
+			expectedKept:    []client.Object{msa, cert},
+		},
+		{
+			name: "removes the secrets of the peers before the cacerts",
+			objects: []client.Object{peerSecret, msa, cert, terminating("peer-secret", "leaving", map[string]string{
+				workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey: mesh.Namespace + "." + peerSecret.Name,
+			})},
+			drain:           true,
+			expectedStep:    ptr.To(teardownPeerSecrets),
+			expectedDeleted: []client.Object{msa},
+			expectedKept:    []client.Object{cert, peerSecret},
+		},
+		{
+			name:            "waits for the secrets of the peers delivered directly",
+			objects:         []client.Object{msa, cert, terminating(ManifestWorkNamePeerSecretPrefix+"peer", "leaving", labels)},
+			drain:           true,
+			expectedStep:    ptr.To(teardownPeerSecrets),
+			expectedDeleted: []client.Object{msa},
+			expectedKept:    []client.Object{cert},
+		},
+		{
+			name:            "removes the cacerts before the control plane namespace",
+			objects:         []client.Object{cert, terminating(ManifestWorkNameCacerts, "leaving", labels), terminating(ManifestWorkNameCPNSPrefix+"istio-system", "leaving", labels)},
+			drain:           true,
+			expectedStep:    ptr.To(teardownCacerts),
+			expectedDeleted: []client.Object{cert},
+		},
+		{
+			name: "waits for the operator to be removed",
+			objects: []client.Object{terminating(OperatorManifestWorkName, "leaving", map[string]string{
+				ManagedByLabel: ManagedByValue, ClusterSetLabel: "set",
+			})},
+			drain:        true,
+			expectedStep: ptr.To(teardownOperator),
+		},
+		{
+			name: "removes everything at once without draining",
+			objects: []client.Object{remoteSecret, msa, cert, terminating("peer-secret", "peer", map[string]string{
+				workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey: mesh.Namespace + "." + RemoteSecretDistributionName(mesh, "leaving"),
+			})},
+			expectedStep:    ptr.To(teardownRemoteSecret),
+			expectedDeleted: []client.Object{remoteSecret, msa, cert},
+		},
+		{
+			name: "complete",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := make([]client.Object, 0, len(tc.objects))
+			for _, obj := range tc.objects {
+				objects = append(objects, obj.DeepCopyObject().(client.Object))
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			r := &Reconciler{Client: c, Scheme: scheme}
+
+			teardown, err := r.teardownCluster(context.Background(), mesh, "leaving", tc.drain, tc.held)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			switch {
+			case tc.expectedStep == nil && teardown != nil:
+				t.Errorf("teardownCluster() = step %d waiting for %v, want complete", teardown.step, teardown.waiting)
+			case tc.expectedStep != nil && (teardown == nil || teardown.step != *tc.expectedStep):
+				t.Errorf("teardownCluster() = %+v, want step %d", teardown, *tc.expectedStep)
+			case tc.expectedWaiting != nil && !slices.Equal(teardown.waiting, tc.expectedWaiting):
+				t.Errorf("teardownCluster() waiting for %v, want %v", teardown.waiting, tc.expectedWaiting)
+			}
+
+			for _, obj := range tc.expectedDeleted {
+				if err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj.DeepCopyObject().(client.Object)); !apierrors.IsNotFound(err) {
+					t.Errorf("%T %s was not deleted", obj, obj.GetName())
+				}
+			}
+			for _, obj := range tc.expectedKept {
+				if err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj.DeepCopyObject().(client.Object)); err != nil {
+					t.Errorf("%T %s was deleted: %v", obj, obj.GetName(), err)
+				}
+			}
+		})
+	}
+}
@@ -141,6 +141,16 @@ var _ = Describe("MultiClusterMesh lifecycle", Ordered, func() {
 			Expect(cs).NotTo(BeNil(), "missing cluster status for %s", cluster)
 			Expect(meta.IsStatusConditionTrue(cs.Conditions, meshv1alpha1.ConditionOperatorInstalled)).To(BeTrue(),
 				"expected OperatorInstalled=True for %s", cluster)
+			Expect(meta.IsStatusConditionTrue(cs.Conditions, meshv1alpha1.ConditionDiscoveryReady)).To(BeTrue(),
+				"expected DiscoveryReady=True for %s", cluster)
+			for _, conditionType := range []string{
+				meshv1alpha1.ConditionControlPlaneNamespaceApplied,
+				meshv1alpha1.ConditionOperatorApplied,
+				meshv1alpha1.ConditionCacertsApplied,
+			} {
+				Expect(meta.IsStatusConditionTrue(cs.Conditions, conditionType)).To(BeTrue(),
+					"expected %s=True for %s", conditionType, cluster)
+			}
 
 			Step("Verifying control plane namespace exists on %s", cluster)
 			cpns := &corev1.Namespace{}
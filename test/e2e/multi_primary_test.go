@@ -195,6 +195,8 @@ var _ = Describe("Multi-primary data plane", Ordered, Serial, func() {
 			for source := range spokeClients {
 				for target, targetClient := range spokeClients {
 					if source == target {
+						err := targetClient.Get(ctx, key.Of("istio-remote-secret-"+source, cpNamespace), &corev1.Secret{})
+						Expect(apierrors.IsNotFound(err)).To(BeTrue(), "%s must not receive its own remote secret", source)
 						continue
 					}
 					secret := &corev1.Secret{}
@@ -17,9 +17,13 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -135,9 +139,47 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 					Expect(work.Spec.ManifestConfigs[0].FeedbackRules).To(HaveLen(1))
 					Expect(work.Spec.ManifestConfigs[0].FeedbackRules[0].Type).To(Equal(workv1.JSONPathsType))
 					Expect(work.Spec.ManifestConfigs[0].FeedbackRules[0].JsonPaths[0].Path).To(Equal(".status.installedCSV"))
+					Expect(work.Spec.ManifestConfigs[0].FeedbackRules[0].JsonPaths).To(ContainElements(
+						workv1.JsonPath{Name: meshcontroller.FeedbackSubscriptionState, Path: ".status.state"},
+						workv1.JsonPath{Name: meshcontroller.FeedbackCurrentCSV, Path: ".status.currentCSV"},
+						workv1.JsonPath{Name: meshcontroller.FeedbackResolutionFailed, Path: `.status.conditions[?(@.type=="ResolutionFailed")].status`},
+						workv1.JsonPath{Name: meshcontroller.FeedbackInstallPlanPhase, Path: `.status.conditions[?(@.type=="InstallPlanPending")].reason`},
+					))
 				}
 			})
 
+			It("should report OLM failure reasons while the operator is not installed", func() {
+				By("reporting a failed resolution")
+				util.SetManifestWorkFeedbackValues(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName, map[string]string{
+					meshcontroller.FeedbackResolutionFailed:        "True",
+					meshcontroller.FeedbackResolutionFailedMessage: "no operators found in channel stable",
+				})
+				expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+					cond := meta.FindStatusCondition(cs.Conditions, meshv1alpha1.ConditionOperatorInstalled)
+					g.Expect(cond).NotTo(BeNil())
+					g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					g.Expect(cond.Reason).To(Equal(meshv1alpha1.ReasonResolutionFailed))
+					g.Expect(cond.Message).To(ContainSubstring("no operators found in channel stable"))
+				})
+
+				By("reporting an unhealthy catalog")
+				util.SetManifestWorkFeedbackValues(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName, map[string]string{
+					meshcontroller.FeedbackCatalogSourcesUnhealthy:        "True",
+					meshcontroller.FeedbackCatalogSourcesUnhealthyMessage: "targeted catalogsource openshift-marketplace/redhat-operators missing",
+				})
+				expectClusterOperatorConditionReason(meshName, testNs, clusterName, meshv1alpha1.ReasonCatalogUnhealthy)
+
+				By("reporting an InstallPlan awaiting approval")
+				util.SetManifestWorkFeedbackValues(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName, map[string]string{
+					meshcontroller.FeedbackCurrentCSV:         "sailoperator.v1.0.0",
+					meshcontroller.FeedbackInstallPlanName:    "install-abcde",
+					meshcontroller.FeedbackInstallPlanPending: "True",
+					meshcontroller.FeedbackInstallPlanPhase:   "RequiresApproval",
+				})
+				expectClusterOperatorConditionReason(meshName, testNs, clusterName, meshv1alpha1.ReasonAwaitingApproval)
+				expectMeshNotReady(meshName, testNs)
+			})
+
 			It("should become ready after all clusters confirm operator installation", func() {
 				expectMeshNotReady(meshName, testNs)
 
@@ -150,15 +192,255 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 				expectClusterOperatorConditionReason(meshName, testNs, cluster2Name, meshv1alpha1.ReasonInstallationPending)
 				expectMeshNotReady(meshName, testNs)
 
-				By("setting feedback on all clusters, mesh should become ready")
+				By("setting feedback on all clusters, mesh should stay not-ready until discovery tokens exist")
 				util.SetManifestWorkFeedback(ctx, k8sClient,
 					meshcontroller.OperatorManifestWorkName, cluster2Name,
 					meshcontroller.FeedbackInstalledCSV, "servicemeshoperator3.v3.0.0")
 
 				expectClusterOperatorConditionReason(meshName, testNs, clusterName, meshv1alpha1.ReasonOperatorInstalled)
 				expectClusterOperatorConditionReason(meshName, testNs, cluster2Name, meshv1alpha1.ReasonOperatorInstalled)
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionDiscoveryReady, meshv1alpha1.ReasonTokenPending)
+				expectMeshNotReady(meshName, testNs)
+
+				By("issuing discovery tokens, mesh should stay not-ready until the remote secrets are applied")
+				setupMsaTokenSecret(testNs, meshName, clusterName)
+				setupMsaTokenSecret(testNs, meshName, cluster2Name)
+
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionDiscoveryReady, meshv1alpha1.ReasonDistributionPending)
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionRemoteSecretsApplied, meshv1alpha1.ReasonApplyPending)
+				expectMeshNotReady(meshName, testNs)
+
+				By("applying the remote secrets on all peers, mesh should stay not-ready until all ManifestWorks are applied")
+				simulateRemoteSecretDistribution(meshName, testNs, clusterName, cluster2Name)
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionControlPlaneNamespaceApplied, meshv1alpha1.ReasonApplyPending)
+				expectMeshNotReady(meshName, testNs)
+
+				By("applying all ManifestWorks, mesh should become ready")
+				simulateMeshManifestWorksApplied(clusterName, "istio-system")
+				simulateMeshManifestWorksApplied(cluster2Name, "istio-system")
+
+				for _, cluster := range []string{clusterName, cluster2Name} {
+					expectClusterConditionReason(meshName, testNs, cluster, meshv1alpha1.ConditionControlPlaneNamespaceApplied, meshv1alpha1.ReasonApplied)
+					expectClusterConditionReason(meshName, testNs, cluster, meshv1alpha1.ConditionOperatorApplied, meshv1alpha1.ReasonApplied)
+					expectClusterConditionReason(meshName, testNs, cluster, meshv1alpha1.ConditionDiscoveryReady, meshv1alpha1.ReasonDistributed)
+					expectClusterConditionReason(meshName, testNs, cluster, meshv1alpha1.ConditionRemoteSecretsApplied, meshv1alpha1.ReasonApplied)
+				}
 				expectMeshReady(meshName, testNs)
 			})
+
+			It("should report operator version skew between clusters", func() {
+				util.SetManifestWorkFeedback(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName,
+					meshcontroller.FeedbackInstalledCSV, "servicemeshoperator3.v3.1.0")
+				util.SetManifestWorkFeedback(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, cluster2Name,
+					meshcontroller.FeedbackInstalledCSV, "servicemeshoperator3.v3.0.0")
+
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionVersionConsistent, meshv1alpha1.ReasonVersionSkew)
+				expectClusterStatus(meshName, testNs, cluster2Name, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+					g.Expect(cs.OperatorVersion).To(Equal("3.0.0"))
+				})
+
+				By("allowing one minor version of skew")
+				updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+					mesh.Spec.VersionSkew = &meshv1alpha1.VersionSkewPolicy{MaxMinorVersions: 1}
+				})
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionVersionConsistent, meshv1alpha1.ReasonVersionsConsistent)
+			})
+
+			It("should report the mesh as degraded when a ManifestWork fails on a single cluster", func() {
+				expectControlPlaneNamespaceManifestWork(clusterName, "istio-system")
+				util.SetManifestWorkConditions(ctx, k8sClient, meshcontroller.ManifestWorkNameCPNSPrefix+"istio-system", clusterName, metav1.Condition{
+					Type:    workv1.WorkApplied,
+					Status:  metav1.ConditionFalse,
+					Reason:  "AppliedManifestWorkFailed",
+					Message: "namespaces is forbidden: admission webhook denied the request",
+				})
+
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionControlPlaneNamespaceApplied, meshv1alpha1.ReasonApplyFailed)
+				expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+					c := findCondition(g, cs.Conditions, meshv1alpha1.ConditionControlPlaneNamespaceApplied)
+					g.Expect(c.Message).To(ContainSubstring("admission webhook denied the request"))
+				})
+				expectClusterConditionReason(meshName, testNs, cluster2Name, meshv1alpha1.ConditionControlPlaneNamespaceApplied, meshv1alpha1.ReasonApplyPending)
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionReady, meshv1alpha1.ReasonClustersDegraded)
+			})
+
+			It("should report remote secrets the work agent failed to apply", func() {
+				setupMsaTokenSecret(testNs, meshName, clusterName)
+				setupMsaTokenSecret(testNs, meshName, cluster2Name)
+				simulateOperatorInstalled(clusterName)
+				simulateOperatorInstalled(cluster2Name)
+				expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, clusterName)
+				expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, cluster2Name)
+
+				util.CreateManifestWorkReplicaSetWork(ctx, k8sClient, remoteSecretDistributionName(meshName, testNs, clusterName), testNs, cluster2Name, metav1.Condition{
+					Type:    workv1.WorkApplied,
+					Status:  metav1.ConditionFalse,
+					Reason:  "AppliedManifestWorkFailed",
+					Message: "secrets is forbidden: exceeded quota",
+				})
+				util.SetManifestWorkReplicaSetSummary(ctx, k8sClient, remoteSecretDistributionName(meshName, testNs, clusterName), testNs, 1, 0)
+
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionDiscoveryReady, meshv1alpha1.ReasonDistributionPending)
+				expectClusterConditionReason(meshName, testNs, cluster2Name, meshv1alpha1.ConditionRemoteSecretsApplied, meshv1alpha1.ReasonApplyFailed)
+				expectClusterStatus(meshName, testNs, cluster2Name, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+					c := findCondition(g, cs.Conditions, meshv1alpha1.ConditionRemoteSecretsApplied)
+					g.Expect(c.Message).To(ContainSubstring("exceeded quota"))
+				})
+				expectMeshNotReady(meshName, testNs)
+			})
+		})
+
+		When("the mesh orphans its resources", func() {
+			BeforeEach(func() {
+				util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+				util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+					DeletionPolicy: meshv1alpha1.DeletionPolicy{
+						Default:  meshv1alpha1.DeletionPolicyOrphan,
+						Operator: meshv1alpha1.DeletionPolicyDelete,
+					},
+				})
+			})
+
+			It("should orphan the resources of each type on deletion according to its policy", func() {
+				cpNsWork, _ := expectControlPlaneNamespaceManifestWork(clusterName, "istio-system")
+				Expect(cpNsWork.Spec.DeleteOption).NotTo(BeNil())
+				Expect(cpNsWork.Spec.DeleteOption.PropagationPolicy).To(Equal(workv1.DeletePropagationPolicyTypeOrphan))
+				Expect(expectOperatorManifestWork(clusterName).Spec.DeleteOption).To(BeNil())
+			})
+
+			It("should delete the resources again when the policy changes", func() {
+				expectControlPlaneNamespaceManifestWork(clusterName, "istio-system")
+
+				updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+					mesh.Spec.DeletionPolicy.Default = meshv1alpha1.DeletionPolicyDelete
+				})
+
+				Eventually(func(g Gomega) {
+					work := &workv1.ManifestWork{}
+					g.Expect(k8sClient.Get(ctx, key.Of(meshcontroller.ManifestWorkNameCPNSPrefix+"istio-system", clusterName), work)).To(Succeed())
+					g.Expect(work.Spec.DeleteOption).To(BeNil())
+				}).Should(Succeed())
+			})
+		})
+
+		When("the operator catalog is defined in the mesh", func() {
+			BeforeEach(func() {
+				util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+				util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+					Operator: meshv1alpha1.OperatorConfig{
+						Source: "mirrored-operators",
+						Catalog: &meshv1alpha1.OperatorCatalog{
+							Image:        "mirror.example.com/redhat/redhat-operator-index:v4.18",
+							DisplayName:  "Mirrored Operators",
+							PullSecrets:  []string{"mirror-pull-secret"},
+							PollInterval: &metav1.Duration{Duration: 30 * time.Minute},
+						},
+					},
+				})
+			})
+
+			It("should ship the CatalogSource in the operator ManifestWork", func() {
+				work := expectOperatorManifestWork(clusterName)
+
+				Expect(work.Spec.Workload.Manifests).To(HaveLen(5))
+				catalog := &operatorsv1alpha1.CatalogSource{}
+				Expect(unmarshalManifest(work.Spec.Workload.Manifests[4], catalog)).To(Succeed())
+				Expect(catalog.Name).To(Equal("mirrored-operators"))
+				Expect(catalog.Namespace).To(Equal("openshift-marketplace"))
+				Expect(catalog.Spec.SourceType).To(Equal(operatorsv1alpha1.SourceTypeGrpc))
+				Expect(catalog.Spec.Image).To(Equal("mirror.example.com/redhat/redhat-operator-index:v4.18"))
+				Expect(catalog.Spec.DisplayName).To(Equal("Mirrored Operators"))
+				Expect(catalog.Spec.Secrets).To(ConsistOf("mirror-pull-secret"))
+				Expect(catalog.Spec.UpdateStrategy.RegistryPoll.RawInterval).To(Equal("30m0s"))
+
+				Expect(work.Spec.ManifestConfigs).To(ContainElement(HaveField("ResourceIdentifier", workv1.ResourceIdentifier{
+					Group: "operators.coreos.com", Resource: "catalogsources", Name: "mirrored-operators", Namespace: "openshift-marketplace",
+				})))
+			})
+
+			It("should report the CatalogSource connection state", func() {
+				expectOperatorManifestWork(clusterName)
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionCatalogSourceReady, meshv1alpha1.ReasonCatalogConnecting)
+
+				util.SetManifestWorkFeedbackValues(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName, map[string]string{
+					meshcontroller.FeedbackCatalogSourceState: "TRANSIENT_FAILURE",
+				})
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionCatalogSourceReady, meshv1alpha1.ReasonCatalogUnhealthy)
+
+				util.SetManifestWorkFeedbackValues(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName, map[string]string{
+					meshcontroller.FeedbackCatalogSourceState: "READY",
+				})
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionCatalogSourceReady, meshv1alpha1.ReasonCatalogReady)
+			})
+		})
+
+		When("the operator is installed from manifests", func() {
+			BeforeEach(func() {
+				Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "sail-operator-manifests", Namespace: testNs},
+					Data: map[string]string{
+						"1-rbac.yaml": "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: sail-operator\n  namespace: sail-operator\n",
+						"2-deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: sail-operator\n  namespace: sail-operator\n" +
+							"spec:\n  template:\n    spec:\n      containers:\n      - name: sail-operator\n        image: quay.io/sail-dev/sail-operator:1.27.0\n",
+					},
+				})).To(Succeed())
+
+				util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+				util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+					Operator: meshv1alpha1.OperatorConfig{
+						InstallMode: meshv1alpha1.OperatorInstallModeManifests,
+						Namespace:   "sail-operator",
+						Manifests:   meshv1alpha1.OperatorManifests{ConfigMapName: "sail-operator-manifests"},
+					},
+				})
+			})
+
+			It("should apply the manifests instead of an OLM Subscription", func() {
+				work := expectOperatorManifestWork(clusterName)
+
+				Expect(work.Spec.Workload.Manifests).To(HaveLen(3))
+				expectNamespace(work, 0, "sail-operator")
+				Expect(string(work.Spec.Workload.Manifests[1].Raw)).To(ContainSubstring(`"kind":"ServiceAccount"`))
+				Expect(string(work.Spec.Workload.Manifests[2].Raw)).To(ContainSubstring(`"kind":"Deployment"`))
+
+				Expect(work.Spec.ManifestConfigs).To(HaveLen(1))
+				Expect(work.Spec.ManifestConfigs[0].ResourceIdentifier).To(Equal(workv1.ResourceIdentifier{
+					Group: "apps", Resource: "deployments", Name: "sail-operator", Namespace: "sail-operator",
+				}))
+			})
+
+			It("should report the operator as installed once the Deployment is ready", func() {
+				expectOperatorManifestWork(clusterName)
+				util.SetManifestWorkIntegerFeedbackValues(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName, map[string]int64{
+					meshcontroller.FeedbackReplicas:      1,
+					meshcontroller.FeedbackReadyReplicas: 0,
+				})
+				expectClusterOperatorConditionReason(meshName, testNs, clusterName, meshv1alpha1.ReasonInstallationPending)
+
+				util.SetManifestWorkIntegerFeedbackValues(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName, map[string]int64{
+					meshcontroller.FeedbackReplicas:        1,
+					meshcontroller.FeedbackReadyReplicas:   1,
+					meshcontroller.FeedbackUpdatedReplicas: 1,
+				})
+				expectClusterOperatorConditionReason(meshName, testNs, clusterName, meshv1alpha1.ReasonOperatorInstalled)
+			})
+
+			It("should update the ManifestWork when the manifests change", func() {
+				work := expectOperatorManifestWork(clusterName)
+				revision := work.Annotations[meshcontroller.AnnotationOperatorRevision]
+
+				configMap := &corev1.ConfigMap{}
+				Expect(k8sClient.Get(ctx, key.Of("sail-operator-manifests", testNs), configMap)).To(Succeed())
+				configMap.Data["3-service.yaml"] = "apiVersion: v1\nkind: Service\nmetadata:\n  name: sail-operator\n  namespace: sail-operator\n"
+				Expect(k8sClient.Update(ctx, configMap)).To(Succeed())
+
+				Eventually(func(g Gomega) {
+					work := &workv1.ManifestWork{}
+					g.Expect(k8sClient.Get(ctx, key.Of(meshcontroller.OperatorManifestWorkName, clusterName), work)).To(Succeed())
+					g.Expect(work.Spec.Workload.Manifests).To(HaveLen(4))
+					g.Expect(work.Annotations[meshcontroller.AnnotationOperatorRevision]).NotTo(Equal(revision))
+				}).Should(Succeed())
+			})
 		})
 
 		It("should use custom operator configuration when specified", func() {
@@ -170,6 +452,21 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 				SourceNamespace:     "custom-catalog-ns",
 				StartingCSV:         "sailoperator.v1.23.0",
 				InstallPlanApproval: operatorsv1alpha1.ApprovalManual,
+				Config: &meshv1alpha1.SubscriptionConfig{
+					NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+					Tolerations: []corev1.Toleration{{
+						Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule,
+					}},
+					Resources: &corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+					},
+					Env: []corev1.EnvVar{{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"}},
+					Volumes: []corev1.Volume{{
+						Name:         "trusted-ca",
+						VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "trusted-ca"}}},
+					}},
+					VolumeMounts: []corev1.VolumeMount{{Name: "trusted-ca", MountPath: "/etc/pki/ca-trust/extracted/pem"}},
+				},
 			}
 
 			util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
@@ -184,6 +481,77 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 			expectSubscription(work, 3, customConfig)
 		})
 
+		When("the operator uses Manual InstallPlan approval", func() {
+			BeforeEach(func() {
+				util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+				util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+					Operator: meshv1alpha1.OperatorConfig{
+						InstallPlanApproval: operatorsv1alpha1.ApprovalManual,
+						StartingCSV:         "servicemeshoperator3.v3.0.0",
+					},
+				})
+				expectOperatorManifestWork(clusterName)
+			})
+
+			awaitApproval := func(installPlan, csv string) {
+				util.SetManifestWorkFeedbackValues(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName, map[string]string{
+					meshcontroller.FeedbackCurrentCSV:         csv,
+					meshcontroller.FeedbackInstallPlanName:    installPlan,
+					meshcontroller.FeedbackInstallPlanPending: "True",
+					meshcontroller.FeedbackInstallPlanPhase:   "RequiresApproval",
+				})
+			}
+
+			It("should approve the InstallPlan of the starting CSV", func() {
+				awaitApproval("install-abcde", "servicemeshoperator3.v3.0.0")
+
+				work := expectManifestWork(meshcontroller.ManifestWorkNameInstallPlanApproval, clusterName)
+				Expect(work.Spec.DeleteOption).NotTo(BeNil())
+				Expect(work.Spec.DeleteOption.PropagationPolicy).To(Equal(workv1.DeletePropagationPolicyTypeOrphan))
+				Expect(work.Spec.ManifestConfigs).To(HaveLen(1))
+				Expect(work.Spec.ManifestConfigs[0].UpdateStrategy.Type).To(Equal(workv1.UpdateStrategyTypeServerSideApply))
+				Expect(work.Spec.Workload.Manifests).To(HaveLen(1))
+				installPlan := &operatorsv1alpha1.InstallPlan{}
+				Expect(unmarshalManifest(work.Spec.Workload.Manifests[0], installPlan)).To(Succeed())
+				Expect(installPlan.Name).To(Equal("install-abcde"))
+				Expect(installPlan.Namespace).To(Equal("multicluster-mesh-operator"))
+				Expect(installPlan.Spec.Approved).To(BeTrue())
+				expectClusterOperatorConditionReason(meshName, testNs, clusterName, meshv1alpha1.ReasonInstallationPending)
+
+				By("removing the approval once the approved CSV is installed")
+				util.SetManifestWorkFeedback(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName,
+					meshcontroller.FeedbackInstalledCSV, "servicemeshoperator3.v3.0.0")
+				expectNoInstallPlanApprovalManifestWork(clusterName)
+			})
+
+			It("should leave InstallPlans for other versions pending", func() {
+				awaitApproval("install-fghij", "servicemeshoperator3.v3.1.0")
+
+				expectClusterOperatorConditionReason(meshName, testNs, clusterName, meshv1alpha1.ReasonAwaitingApproval)
+				expectNoInstallPlanApprovalManifestWork(clusterName)
+			})
+
+			It("should approve an upgrade once its CSV is approved in the mesh spec", func() {
+				util.SetManifestWorkFeedbackValues(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName, map[string]string{
+					meshcontroller.FeedbackInstalledCSV:       "servicemeshoperator3.v3.0.0",
+					meshcontroller.FeedbackCurrentCSV:         "servicemeshoperator3.v3.1.0",
+					meshcontroller.FeedbackInstallPlanName:    "install-fghij",
+					meshcontroller.FeedbackInstallPlanPending: "True",
+					meshcontroller.FeedbackInstallPlanPhase:   "RequiresApproval",
+				})
+				expectNoInstallPlanApprovalManifestWork(clusterName)
+
+				updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+					mesh.Spec.Operator.ApprovedCSV = "servicemeshoperator3.v3.1.0"
+				})
+
+				work := expectManifestWork(meshcontroller.ManifestWorkNameInstallPlanApproval, clusterName)
+				installPlan := &operatorsv1alpha1.InstallPlan{}
+				Expect(unmarshalManifest(work.Spec.Workload.Manifests[0], installPlan)).To(Succeed())
+				Expect(installPlan.Name).To(Equal("install-fghij"))
+			})
+		})
+
 		When("referencing a non-existent ClusterSet", func() {
 			var otherClusterSet string
 
@@ -372,59 +740,685 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 				}).ShouldNot(Equal(originalUID))
 			})
 
-			When("moving the cluster between sets", func() {
-				var otherClusterSet string
+			When("moving the cluster between sets", func() {
+				var otherClusterSet string
+
+				BeforeEach(func() {
+					otherClusterSet = util.UniqueName("other-set")
+					util.CreateManagedClusterSet(ctx, k8sClient, otherClusterSet)
+				})
+
+				It("should cleanup ManifestWork when no mesh targets the new set", func() {
+					updateClusterSetLabel(clusterName, otherClusterSet)
+					expectAllManifestWorksDeleted()
+					expectNoClusterStatus(meshName, testNs, clusterName)
+				})
+
+				It("should recreate ManifestWork for the new mesh when cluster moves to a new set", func() {
+					originalUID := work.UID
+
+					otherMesh := util.UniqueName("other-mesh")
+					util.CreateMultiClusterMesh(ctx, k8sClient, otherMesh, testNs, otherClusterSet)
+					expectMeshNotReady(otherMesh, testNs)
+					updateClusterSetLabel(clusterName, otherClusterSet)
+
+					Eventually(func(g Gomega) {
+						w := expectOperatorManifestWork(clusterName)
+						g.Expect(w.Labels[meshcontroller.ClusterSetLabel]).To(Equal(otherClusterSet))
+						g.Expect(w.UID).NotTo(Equal(originalUID))
+					}).Should(Succeed())
+				})
+			})
+
+			When("two meshes target the same cluster", func() {
+				var otherNs, otherMesh string
+
+				BeforeEach(func() {
+					otherNs = util.UniqueName("other-ns")
+					otherMesh = util.UniqueName("other-mesh")
+					util.CreateNamespace(ctx, k8sClient, otherNs)
+					util.CreateMultiClusterMesh(ctx, k8sClient, otherMesh, otherNs, testClusterSet)
+				})
+
+				It("should keep the ManifestWork when one mesh is deleted", func() {
+					expectMeshNotReady(otherMesh, otherNs)
+					util.DeleteResource(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, otherMesh, otherNs)
+					expectOperatorManifestWork(clusterName)
+				})
+
+				It("should delete the ManifestWork when both meshes are deleted", func() {
+					expectMeshNotReady(otherMesh, otherNs)
+					util.DeleteResource(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, meshName, testNs)
+					util.DeleteResource(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, otherMesh, otherNs)
+					expectAllManifestWorksDeleted()
+				})
+			})
+		})
+	})
+
+	Context("Operator profiles", func() {
+		var openshiftName string
+
+		sailConfig := meshv1alpha1.OperatorConfig{
+			Name:            "sailoperator",
+			Namespace:       "sail-operator",
+			Source:          "operatorhubio-catalog",
+			SourceNamespace: "olm",
+		}
+		profiles := []meshv1alpha1.OperatorProfile{
+			{Name: "openshift", Selector: meshv1alpha1.PlatformSelector{Products: []string{"OpenShift"}}},
+			{Name: "kubernetes", Operator: sailConfig},
+		}
+
+		subscription := func(clusterName string) *operatorsv1alpha1.Subscription {
+			work := expectOperatorManifestWork(clusterName)
+			sub := &operatorsv1alpha1.Subscription{}
+			Expect(unmarshalManifest(work.Spec.Workload.Manifests[3], sub)).To(Succeed())
+			return sub
+		}
+
+		expectClusterProfile := func(meshName, clusterName, profile string) {
+			expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+				g.Expect(cs.OperatorProfile).To(Equal(profile))
+			})
+		}
+
+		BeforeEach(func() {
+			openshiftName = util.UniqueName("openshift")
+			util.CreateManagedCluster(ctx, k8sClient, openshiftName, testClusterSet)
+			util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+			util.SetManagedClusterClaims(ctx, k8sClient, openshiftName, map[string]string{
+				meshcontroller.ClusterClaimProduct:     "OpenShift",
+				meshcontroller.ClusterClaimKubeVersion: "v1.31.6",
+			})
+			util.SetManagedClusterClaims(ctx, k8sClient, clusterName, map[string]string{
+				meshcontroller.ClusterClaimProduct:     "Other",
+				meshcontroller.ClusterClaimKubeVersion: "v1.32.2",
+			})
+			util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+				OperatorProfiles: profiles,
+			})
+		})
+
+		It("should install the operator of the profile matching each cluster's platform", func() {
+			expectOperatorManifestWork(openshiftName)
+			expectOperatorManifestWork(clusterName)
+
+			Expect(subscription(openshiftName).Spec.Package).To(Equal("servicemeshoperator3"))
+			Expect(subscription(openshiftName).Spec.CatalogSource).To(Equal("redhat-operators"))
+			sub := subscription(clusterName)
+			Expect(sub.Spec.Package).To(Equal("sailoperator"))
+			Expect(sub.Namespace).To(Equal("sail-operator"))
+			Expect(sub.Spec.CatalogSource).To(Equal("operatorhubio-catalog"))
+			Expect(sub.Spec.CatalogSourceNamespace).To(Equal("olm"))
+
+			expectClusterProfile(meshName, openshiftName, "openshift")
+			expectClusterProfile(meshName, clusterName, "kubernetes")
+		})
+
+		It("should switch the operator when the cluster's platform changes", func() {
+			Eventually(func() string { return subscription(clusterName).Spec.Package }).Should(Equal("sailoperator"))
+
+			util.SetManagedClusterClaims(ctx, k8sClient, clusterName, map[string]string{meshcontroller.ClusterClaimProduct: "OpenShift"})
+
+			Eventually(func() string { return subscription(clusterName).Spec.Package }).Should(Equal("servicemeshoperator3"))
+			expectClusterProfile(meshName, clusterName, "openshift")
+		})
+
+		It("should block a newer mesh whose profiles install a different operator on a cluster", func() {
+			otherMesh := meshName + "-2"
+			util.CreateMultiClusterMesh(ctx, k8sClient, otherMesh, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+				ControlPlane: meshv1alpha1.ControlPlaneConfig{Namespace: "istio-system-2"},
+			})
+
+			expectMeshConditionReason(otherMesh, testNs, meshv1alpha1.ConditionReady, meshv1alpha1.ReasonOperatorConfigConflict)
+			Eventually(func(g Gomega) {
+				mesh := &meshv1alpha1.MultiClusterMesh{}
+				g.Expect(k8sClient.Get(ctx, key.Of(otherMesh, testNs), mesh)).To(Succeed())
+				g.Expect(meta.FindStatusCondition(mesh.Status.Conditions, meshv1alpha1.ConditionReady).Message).To(ContainSubstring(clusterName))
+			}).Should(Succeed())
+		})
+
+		It("should allow a newer mesh with the same operator on every cluster", func() {
+			otherMesh := meshName + "-2"
+			util.CreateMultiClusterMesh(ctx, k8sClient, otherMesh, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+				ControlPlane:     meshv1alpha1.ControlPlaneConfig{Namespace: "istio-system-2"},
+				OperatorProfiles: []meshv1alpha1.OperatorProfile{{Name: "sail", Selector: meshv1alpha1.PlatformSelector{Products: []string{"Other"}}, Operator: sailConfig}},
+			})
+
+			expectClusterOperatorConditionReason(otherMesh, testNs, clusterName, meshv1alpha1.ReasonInstallationPending)
+			expectClusterProfile(otherMesh, clusterName, "sail")
+			expectClusterProfile(otherMesh, openshiftName, "")
+		})
+	})
+
+	Context("Pre-existing operator", func() {
+		subscription := func(channel string) map[string]string {
+			return map[string]string{
+				meshcontroller.FeedbackSubscriptionPackage:         "servicemeshoperator3",
+				meshcontroller.FeedbackSubscriptionChannel:         channel,
+				meshcontroller.FeedbackSubscriptionSource:          "redhat-operators",
+				meshcontroller.FeedbackSubscriptionSourceNamespace: "openshift-marketplace",
+				meshcontroller.FeedbackInstalledCSV:                "servicemeshoperator3.v3.0.0",
+			}
+		}
+
+		expectNoOperatorManifestWork := func() {
+			Consistently(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, key.Of(meshcontroller.OperatorManifestWorkName, clusterName), &workv1.ManifestWork{}))
+			}).Should(BeTrue())
+		}
+
+		BeforeEach(func() {
+			util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+			updateClusterAnnotations(clusterName, map[string]string{util.AnnotationManualOperatorProbe: "true"})
+			util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet)
+		})
+
+		It("should probe the Subscription read-only before installing the operator", func() {
+			probe := expectManifestWork(meshcontroller.ManifestWorkNameOperatorProbe, clusterName)
+			Expect(probe.Spec.ManifestConfigs).To(HaveLen(3))
+			for _, config := range probe.Spec.ManifestConfigs {
+				Expect(config.ResourceIdentifier.Resource).To(Equal("subscriptions"))
+				Expect(config.UpdateStrategy.Type).To(Equal(workv1.UpdateStrategyTypeReadOnly))
+			}
+			expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionOperatorApplied, meshv1alpha1.ReasonDetectionPending)
+			expectNoOperatorManifestWork()
+		})
+
+		It("should install the operator when no Subscription exists", func() {
+			expectManifestWork(meshcontroller.ManifestWorkNameOperatorProbe, clusterName)
+			Expect(util.ReportOperatorProbe(ctx, k8sClient, clusterName, nil)).To(Succeed())
+
+			expectOperatorManifestWork(clusterName)
+			util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, meshcontroller.ManifestWorkNameOperatorProbe, clusterName)
+		})
+
+		It("should adopt a compatible Subscription without taking it over", func() {
+			expectManifestWork(meshcontroller.ManifestWorkNameOperatorProbe, clusterName)
+			Expect(util.ReportOperatorProbe(ctx, k8sClient, clusterName, map[string]map[string]string{
+				"openshift-operators": subscription("stable"),
+			})).To(Succeed())
+
+			expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionOperatorApplied, meshv1alpha1.ReasonOperatorAdopted)
+			expectClusterOperatorConditionReason(meshName, testNs, clusterName, meshv1alpha1.ReasonOperatorInstalled)
+			expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+				g.Expect(cs.OperatorVersion).To(Equal("3.0.0"))
+			})
+			expectNoOperatorManifestWork()
+		})
+
+		It("should report a conflict with an incompatible Subscription", func() {
+			expectManifestWork(meshcontroller.ManifestWorkNameOperatorProbe, clusterName)
+			Expect(util.ReportOperatorProbe(ctx, k8sClient, clusterName, map[string]map[string]string{
+				"openshift-operators": subscription("candidates"),
+			})).To(Succeed())
+
+			expectClusterOperatorConditionReason(meshName, testNs, clusterName, meshv1alpha1.ReasonConfigurationConflict)
+			expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+				c := findCondition(g, cs.Conditions, meshv1alpha1.ConditionOperatorApplied)
+				g.Expect(c.Reason).To(Equal(meshv1alpha1.ReasonConfigurationConflict))
+				g.Expect(c.Message).To(ContainSubstring("openshift-operators/servicemeshoperator3"))
+				g.Expect(c.Message).To(ContainSubstring(`channel is "candidates" instead of "stable"`))
+			})
+			expectMeshNotReady(meshName, testNs)
+			expectNoOperatorManifestWork()
+		})
+	})
+
+	Context("Operator rollout", func() {
+		var canaryName string
+
+		simulateOperatorInstalled := func(clusterName string) {
+			util.SetManifestWorkConditions(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName, util.WorkAppliedConditions()...)
+			util.SetManifestWorkFeedback(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName,
+				meshcontroller.FeedbackInstalledCSV, "servicemeshoperator3.v3.0.0")
+		}
+
+		subscriptionChannel := func(clusterName string) string {
+			work := expectOperatorManifestWork(clusterName)
+			sub := &operatorsv1alpha1.Subscription{}
+			Expect(unmarshalManifest(work.Spec.Workload.Manifests[3], sub)).To(Succeed())
+			return sub.Spec.Channel
+		}
+
+		BeforeEach(func() {
+			canaryName = util.UniqueName("canary")
+			util.CreateManagedCluster(ctx, k8sClient, canaryName, testClusterSet)
+			util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+			util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+				Rollout: &meshv1alpha1.RolloutStrategy{CanaryClusters: []string{canaryName}},
+			})
+
+			By("installing the initial operator revision on all clusters at once")
+			expectOperatorManifestWork(canaryName)
+			expectOperatorManifestWork(clusterName)
+			simulateOperatorInstalled(canaryName)
+			simulateOperatorInstalled(clusterName)
+			expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionOperatorRolledOut, meshv1alpha1.ReasonRolloutComplete)
+		})
+
+		It("should hold back other clusters until the canary cluster is healthy", func() {
+			updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+				mesh.Spec.Operator.Channel = "tech-preview"
+			})
+
+			Eventually(func() string { return subscriptionChannel(canaryName) }).Should(Equal("tech-preview"))
+			Consistently(func() string { return subscriptionChannel(clusterName) }).Should(Equal("stable"))
+			expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionOperatorRolledOut, meshv1alpha1.ReasonRolloutProgressing)
+			expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+				g.Expect(cs.OperatorRollout).NotTo(BeNil())
+				g.Expect(cs.OperatorRollout.State).To(Equal(meshv1alpha1.OperatorRolloutPending))
+			})
+
+			By("reporting the canary cluster as healthy")
+			simulateOperatorInstalled(canaryName)
+			Eventually(func() string { return subscriptionChannel(clusterName) }).Should(Equal("tech-preview"))
+
+			simulateOperatorInstalled(clusterName)
+			expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionOperatorRolledOut, meshv1alpha1.ReasonRolloutComplete)
+			Eventually(func(g Gomega) {
+				mesh := &meshv1alpha1.MultiClusterMesh{}
+				g.Expect(k8sClient.Get(ctx, key.Of(meshName, testNs), mesh)).To(Succeed())
+				g.Expect(mesh.Status.OperatorRollout).NotTo(BeNil())
+				g.Expect(mesh.Status.OperatorRollout.Total).To(BeEquivalentTo(2))
+				g.Expect(mesh.Status.OperatorRollout.Succeeded).To(BeEquivalentTo(2))
+			}).Should(Succeed())
+		})
+
+		It("should halt the rollout when the canary cluster fails", func() {
+			updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+				mesh.Spec.Operator.Channel = "tech-preview"
+			})
+			Eventually(func() string { return subscriptionChannel(canaryName) }).Should(Equal("tech-preview"))
+
+			util.SetManifestWorkFeedbackValues(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, canaryName, map[string]string{
+				meshcontroller.FeedbackResolutionFailed:        "True",
+				meshcontroller.FeedbackResolutionFailedMessage: "no operators found in channel tech-preview",
+			})
+
+			expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionOperatorRolledOut, meshv1alpha1.ReasonRolloutHalted)
+			Consistently(func() string { return subscriptionChannel(clusterName) }).Should(Equal("stable"))
+		})
+	})
+
+	Context("Suspension", func() {
+		cpNsMWName := meshcontroller.ManifestWorkNameCPNSPrefix + "istio-system"
+		subscriptionChannel := func(clusterName string) string {
+			work := expectOperatorManifestWork(clusterName)
+			sub := &operatorsv1alpha1.Subscription{}
+			Expect(unmarshalManifest(work.Spec.Workload.Manifests[3], sub)).To(Succeed())
+			return sub.Spec.Channel
+		}
+
+		When("the mesh is suspended", func() {
+			BeforeEach(func() {
+				util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+				util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet)
+				Eventually(func() string { return subscriptionChannel(clusterName) }).Should(Equal("stable"))
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionSuspended, meshv1alpha1.ReasonNotSuspended)
+
+				updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+					mesh.Spec.Suspend = true
+					mesh.Spec.Operator.Channel = "tech-preview"
+				})
+			})
+
+			It("should hold changes while still reporting status", func() {
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionSuspended, meshv1alpha1.ReasonMeshSuspended)
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionSuspended, meshv1alpha1.ReasonMeshSuspended)
+				Consistently(func() string { return subscriptionChannel(clusterName) }).Should(Equal("stable"))
+
+				simulateMeshManifestWorksApplied(clusterName, "istio-system")
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionControlPlaneNamespaceApplied, meshv1alpha1.ReasonApplied)
+			})
+
+			It("should keep the ManifestWorks of clusters leaving the mesh", func() {
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionSuspended, meshv1alpha1.ReasonMeshSuspended)
+				updateClusterSetLabel(clusterName, "")
+
+				Consistently(func() error {
+					return k8sClient.Get(ctx, key.Of(cpNsMWName, clusterName), &workv1.ManifestWork{})
+				}).Should(Succeed())
+			})
+
+			It("should apply the held changes once resumed", func() {
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionSuspended, meshv1alpha1.ReasonMeshSuspended)
+				updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+					mesh.Spec.Suspend = false
+				})
+
+				Eventually(func() string { return subscriptionChannel(clusterName) }).Should(Equal("tech-preview"))
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionSuspended, meshv1alpha1.ReasonNotSuspended)
+			})
+
+			It("should still clean up the clusters when deleted", func() {
+				util.DeleteResource(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, meshName, testNs)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, cpNsMWName, clusterName)
+			})
+		})
+
+		When("a cluster is in maintenance", func() {
+			var maintainedName string
+
+			BeforeEach(func() {
+				maintainedName = util.UniqueName("maintained")
+				util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+				util.CreateManagedCluster(ctx, k8sClient, maintainedName, testClusterSet)
+				util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet)
+				Eventually(func() string { return subscriptionChannel(maintainedName) }).Should(Equal("stable"))
+
+				updateClusterAnnotations(maintainedName, map[string]string{meshcontroller.AnnotationMaintenance: "true"})
+				expectClusterConditionReason(meshName, testNs, maintainedName, meshv1alpha1.ConditionSuspended, meshv1alpha1.ReasonClusterMaintenance)
+			})
+
+			It("should only hold changes to that cluster", func() {
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionSuspended, meshv1alpha1.ReasonClusterMaintenance)
+				updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+					mesh.Spec.Operator.Channel = "tech-preview"
+				})
+
+				Eventually(func() string { return subscriptionChannel(clusterName) }).Should(Equal("tech-preview"))
+				Consistently(func() string { return subscriptionChannel(maintainedName) }).Should(Equal("stable"))
+				expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+					g.Expect(meta.FindStatusCondition(cs.Conditions, meshv1alpha1.ConditionSuspended)).To(BeNil())
+				})
+			})
+
+			It("should keep its ManifestWorks when it leaves the mesh", func() {
+				updateClusterSetLabel(maintainedName, "")
+
+				expectNoClusterStatus(meshName, testNs, maintainedName)
+				Consistently(func() error {
+					return k8sClient.Get(ctx, key.Of(cpNsMWName, maintainedName), &workv1.ManifestWork{})
+				}).Should(Succeed())
+			})
+
+			It("should apply the held changes once the maintenance ends", func() {
+				updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+					mesh.Spec.Operator.Channel = "tech-preview"
+				})
+				Eventually(func() string { return subscriptionChannel(clusterName) }).Should(Equal("tech-preview"))
+
+				updateClusterAnnotations(maintainedName, map[string]string{meshcontroller.AnnotationMaintenance: "false"})
+				Eventually(func() string { return subscriptionChannel(maintainedName) }).Should(Equal("tech-preview"))
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionSuspended, meshv1alpha1.ReasonNotSuspended)
+			})
+		})
+	})
+
+	Context("Cluster removal", func() {
+		cpNsMWName := meshcontroller.ManifestWorkNameCPNSPrefix + "istio-system"
+
+		createMeshWithGracePeriod := func(gracePeriod time.Duration) {
+			util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+			util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+				DeletionPolicy: meshv1alpha1.DeletionPolicy{RemovalGracePeriod: &metav1.Duration{Duration: gracePeriod}},
+			})
+			expectControlPlaneNamespaceManifestWork(clusterName, "istio-system")
+			expectManagedServiceAccount(testNs, meshName, clusterName)
+		}
+
+		When("a cluster leaves the ClusterSet within the removal grace period", func() {
+			BeforeEach(func() {
+				createMeshWithGracePeriod(time.Hour)
+				updateClusterSetLabel(clusterName, "")
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionPendingRemoval, meshv1alpha1.ReasonRemovalGracePeriod)
+			})
+
+			It("should keep its resources", func() {
+				Consistently(func(g Gomega) {
+					g.Expect(k8sClient.Get(ctx, key.Of(cpNsMWName, clusterName), &workv1.ManifestWork{})).To(Succeed())
+					g.Expect(k8sClient.Get(ctx, key.Of(meshcontroller.OperatorManifestWorkName, clusterName), &workv1.ManifestWork{})).To(Succeed())
+					g.Expect(getManagedServiceAccount(g, testNs, meshName, clusterName)).NotTo(BeNil())
+				}).Should(Succeed())
+			})
+
+			It("should clear the pending removal when the cluster rejoins", func() {
+				updateClusterSetLabel(clusterName, testClusterSet)
+
+				expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+					g.Expect(meta.FindStatusCondition(cs.Conditions, meshv1alpha1.ConditionPendingRemoval)).To(BeNil())
+					g.Expect(meta.FindStatusCondition(cs.Conditions, meshv1alpha1.ConditionControlPlaneNamespaceApplied)).NotTo(BeNil())
+				})
+				Consistently(func() error {
+					return k8sClient.Get(ctx, key.Of(cpNsMWName, clusterName), &workv1.ManifestWork{})
+				}).Should(Succeed())
+			})
+		})
+
+		When("a cluster leaves the mesh", func() {
+			It("should revoke its remote secret before removing its control plane", func() {
+				peerName := util.UniqueName("peer")
+				util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+				util.CreateManagedCluster(ctx, k8sClient, peerName, testClusterSet)
+				util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet)
+				expectControlPlaneNamespaceManifestWork(clusterName, "istio-system")
+				setupMsaTokenSecret(testNs, meshName, clusterName)
+				setupMsaTokenSecret(testNs, meshName, peerName)
+				simulateOperatorInstalled(clusterName)
+				simulateOperatorInstalled(peerName)
+				simulateRemoteSecretDistribution(meshName, testNs, clusterName, peerName)
+
+				// The work agent of the peer keeps the ManifestWork until it deleted the remote secret of the cluster.
+				remoteSecretWork := remoteSecretDistributionName(meshName, testNs, clusterName)
+				setWorkFinalizers := func(finalizers []string) {
+					Eventually(func() error {
+						work := &workv1.ManifestWork{}
+						if err := k8sClient.Get(ctx, key.Of(remoteSecretWork, peerName), work); err != nil {
+							return err
+						}
+						work.Finalizers = finalizers
+						return k8sClient.Update(ctx, work)
+					}).Should(Succeed())
+				}
+				setWorkFinalizers([]string{"test.mesh.open-cluster-management.io/cleanup"})
+
+				updateClusterSetLabel(clusterName, "")
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionRemoving, meshv1alpha1.ReasonRevokingRemoteSecret)
+				Consistently(func(g Gomega) {
+					g.Expect(k8sClient.Get(ctx, key.Of(cpNsMWName, clusterName), &workv1.ManifestWork{})).To(Succeed())
+					g.Expect(k8sClient.Get(ctx, key.Of(meshcontroller.OperatorManifestWorkName, clusterName), &workv1.ManifestWork{})).To(Succeed())
+					g.Expect(getManagedServiceAccount(g, testNs, meshName, clusterName)).NotTo(BeNil())
+				}).Should(Succeed())
+
+				setWorkFinalizers(nil)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, remoteSecretWork, peerName)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, remoteSecretWork, clusterName)
+				util.ExpectResourceDeleted(ctx, k8sClient, &msav1beta1.ManagedServiceAccount{},
+					expectedManagedServiceAccountName(testNs, meshName), clusterName)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, cpNsMWName, clusterName)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, meshcontroller.OperatorManifestWorkName, clusterName)
+				expectNoClusterStatus(meshName, testNs, clusterName)
+			})
+		})
+
+		When("the removal grace period expires", func() {
+			It("should remove the resources of the cluster", func() {
+				createMeshWithGracePeriod(3 * time.Second)
+				updateClusterSetLabel(clusterName, "")
+
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, cpNsMWName, clusterName)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, meshcontroller.OperatorManifestWorkName, clusterName)
+				util.ExpectResourceDeleted(ctx, k8sClient, &msav1beta1.ManagedServiceAccount{},
+					expectedManagedServiceAccountName(testNs, meshName), clusterName)
+				expectNoClusterStatus(meshName, testNs, clusterName)
+			})
+		})
+	})
+
+	Context("Quarantine", func() {
+		var peerName string
+
+		BeforeEach(func() {
+			peerName = util.UniqueName("peer")
+			util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+			util.CreateManagedCluster(ctx, k8sClient, peerName, testClusterSet)
+			util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet, util.CertManagerSpec("mesh-issuer"))
+			setupMsaTokenSecret(testNs, meshName, clusterName)
+			setupMsaTokenSecret(testNs, meshName, peerName)
+			for _, cluster := range []string{clusterName, peerName} {
+				simulateOperatorInstalled(cluster)
+				expectCertificate(testNs, cluster, meshName, "mesh-issuer", "Issuer")
+				simulateCacertsApplied(testNs, meshName, cluster)
+			}
+			simulateRemoteSecretDistribution(meshName, testNs, clusterName, peerName)
+
+			updateClusterAnnotations(clusterName, map[string]string{meshcontroller.AnnotationQuarantine: "true"})
+		})
+
+		It("should revoke the credentials of the cluster and record it in status", func() {
+			util.ExpectResourceDeleted(ctx, k8sClient, &workv1alpha1.ManifestWorkReplicaSet{}, remoteSecretDistributionName(meshName, testNs, clusterName), testNs)
+			util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, remoteSecretDistributionName(meshName, testNs, clusterName), peerName)
+			util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, remoteSecretDistributionName(meshName, testNs, peerName), clusterName)
+			util.ExpectResourceDeleted(ctx, k8sClient, &msav1beta1.ManagedServiceAccount{},
+				expectedManagedServiceAccountName(testNs, meshName), clusterName)
+			util.ExpectResourceDeleted(ctx, k8sClient, &certmanagerv1.Certificate{}, "cacerts-"+clusterName, testNs)
+			util.ExpectResourceDeleted(ctx, k8sClient, &corev1.Secret{}, "cacerts-"+clusterName, testNs)
+
+			expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionQuarantined, meshv1alpha1.ReasonQuarantineComplete)
+			expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+				g.Expect(cs.Quarantine).NotTo(BeNil())
+				g.Expect(cs.Quarantine.RemoteSecretRevokedTime).NotTo(BeNil())
+				g.Expect(cs.Quarantine.PeerSecretsRemovedTime).NotTo(BeNil())
+				g.Expect(cs.Quarantine.ServiceAccountRevokedTime).NotTo(BeNil())
+				g.Expect(cs.Quarantine.CertificateRevokedTime).NotTo(BeNil())
+				g.Expect(cs.Quarantine.TrustRevokedTime).NotTo(BeNil())
+				g.Expect(meta.FindStatusCondition(cs.Conditions, meshv1alpha1.ConditionDiscoveryReady)).To(BeNil())
+			})
+		})
+
+		It("should keep the cluster in the mesh with the resources applied to it", func() {
+			expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionQuarantined, meshv1alpha1.ReasonQuarantineComplete)
+			Consistently(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, key.Of(meshcontroller.ManifestWorkNameCacerts, clusterName), &workv1.ManifestWork{})).To(Succeed())
+				g.Expect(k8sClient.Get(ctx, key.Of(meshcontroller.ManifestWorkNameCPNSPrefix+"istio-system", clusterName), &workv1.ManifestWork{})).To(Succeed())
+				g.Expect(k8sClient.Get(ctx, key.Of(meshcontroller.OperatorManifestWorkName, clusterName), &workv1.ManifestWork{})).To(Succeed())
+				g.Expect(errors.IsNotFound(k8sClient.Get(ctx, key.Of(expectedManagedServiceAccountName(testNs, meshName), clusterName),
+					&msav1beta1.ManagedServiceAccount{}))).To(BeTrue())
+			}).Should(Succeed())
+		})
 
-				BeforeEach(func() {
-					otherClusterSet = util.UniqueName("other-set")
-					util.CreateManagedClusterSet(ctx, k8sClient, otherClusterSet)
-				})
+		It("should stop distributing the remote secrets of the peers to the cluster", func() {
+			Eventually(func(g Gomega) {
+				placement := &clusterv1beta1.Placement{}
+				g.Expect(k8sClient.Get(ctx, key.Of(remoteSecretDistributionName(meshName, testNs, peerName), testNs), placement)).To(Succeed())
+				g.Expect(placement.Spec.Predicates[0].RequiredClusterSelector.LabelSelector.MatchExpressions[0].Values).To(ConsistOf(peerName, clusterName))
+			}).Should(Succeed())
+		})
 
-				It("should cleanup ManifestWork when no mesh targets the new set", func() {
-					updateClusterSetLabel(clusterName, otherClusterSet)
-					expectAllManifestWorksDeleted()
-					expectNoClusterStatus(meshName, testNs, clusterName)
-				})
+		It("should restore the credentials once the quarantine is lifted", func() {
+			expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionQuarantined, meshv1alpha1.ReasonQuarantineComplete)
+			updateClusterAnnotations(clusterName, map[string]string{meshcontroller.AnnotationQuarantine: "false"})
 
-				It("should recreate ManifestWork for the new mesh when cluster moves to a new set", func() {
-					originalUID := work.UID
+			expectManagedServiceAccount(testNs, meshName, clusterName)
+			expectCertificate(testNs, clusterName, meshName, "mesh-issuer", "Issuer")
+			expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+				g.Expect(cs.Quarantine).To(BeNil())
+				g.Expect(meta.FindStatusCondition(cs.Conditions, meshv1alpha1.ConditionQuarantined)).To(BeNil())
+			})
+		})
+	})
 
-					otherMesh := util.UniqueName("other-mesh")
-					util.CreateMultiClusterMesh(ctx, k8sClient, otherMesh, testNs, otherClusterSet)
-					expectMeshNotReady(otherMesh, testNs)
-					updateClusterSetLabel(clusterName, otherClusterSet)
+	Context("Readiness gate", func() {
+		var peerName string
 
-					Eventually(func(g Gomega) {
-						w := expectOperatorManifestWork(clusterName)
-						g.Expect(w.Labels[meshcontroller.ClusterSetLabel]).To(Equal(otherClusterSet))
-						g.Expect(w.UID).NotTo(Equal(originalUID))
-					}).Should(Succeed())
-				})
+		createMesh := func(gate meshv1alpha1.ReadinessGate) {
+			peerName = util.UniqueName("peer")
+			util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+			util.CreateManagedCluster(ctx, k8sClient, peerName, testClusterSet)
+			util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+				Security: meshv1alpha1.SecurityConfig{Discovery: meshv1alpha1.DiscoveryConfig{ReadinessGate: gate}},
 			})
+			setupMsaTokenSecret(testNs, meshName, clusterName)
+			setupMsaTokenSecret(testNs, meshName, peerName)
+		}
+		expectPlacementExcludes := func(source string, clusters ...string) {
+			Eventually(func(g Gomega) {
+				placement := &clusterv1beta1.Placement{}
+				g.Expect(k8sClient.Get(ctx, key.Of(remoteSecretDistributionName(meshName, testNs, source), testNs), placement)).To(Succeed())
+				g.Expect(placement.Spec.Predicates[0].RequiredClusterSelector.LabelSelector.MatchExpressions[0].Values).To(ConsistOf(clusters))
+			}).Should(Succeed())
+		}
+		expectNotDistributed := func(source string) {
+			Consistently(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, key.Of(remoteSecretDistributionName(meshName, testNs, source), testNs), &workv1alpha1.ManifestWorkReplicaSet{}))
+			}).Should(BeTrue())
+		}
 
-			When("two meshes target the same cluster", func() {
-				var otherNs, otherMesh string
+		It("should keep a cluster out of its peers until its operator is installed", func() {
+			createMesh(meshv1alpha1.ReadinessGateOperator)
+			simulateOperatorInstalled(clusterName)
+
+			expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, clusterName)
+			expectPlacementExcludes(clusterName, clusterName, peerName)
+			expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionPeerReady, meshv1alpha1.ReasonReadinessGatePassed)
+			expectClusterConditionReason(meshName, testNs, peerName, meshv1alpha1.ConditionPeerReady, meshv1alpha1.ReasonOperatorNotReady)
+			expectNotDistributed(peerName)
+
+			By("installing the operator of the peer")
+			simulateOperatorInstalled(peerName)
+			expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, peerName)
+			expectPlacementExcludes(clusterName, clusterName)
+			expectPlacementExcludes(peerName, peerName)
+			expectClusterConditionReason(meshName, testNs, peerName, meshv1alpha1.ConditionPeerReady, meshv1alpha1.ReasonReadinessGatePassed)
+		})
 
-				BeforeEach(func() {
-					otherNs = util.UniqueName("other-ns")
-					otherMesh = util.UniqueName("other-mesh")
-					util.CreateNamespace(ctx, k8sClient, otherNs)
-					util.CreateMultiClusterMesh(ctx, k8sClient, otherMesh, otherNs, testClusterSet)
-				})
+		It("should keep a peer whose operator is upgraded", func() {
+			createMesh(meshv1alpha1.ReadinessGateOperator)
+			simulateOperatorInstalled(clusterName)
+			simulateOperatorInstalled(peerName)
+			expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, peerName)
+
+			util.SetManifestWorkFeedback(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, peerName,
+				meshcontroller.FeedbackInstallPlanPending, string(metav1.ConditionTrue))
+			expectClusterOperatorConditionReason(meshName, testNs, peerName, meshv1alpha1.ReasonInstallationPending)
+			Consistently(func() error {
+				return k8sClient.Get(ctx, key.Of(remoteSecretDistributionName(meshName, testNs, peerName), testNs), &workv1alpha1.ManifestWorkReplicaSet{})
+			}).Should(Succeed())
+			expectClusterConditionReason(meshName, testNs, peerName, meshv1alpha1.ConditionPeerReady, meshv1alpha1.ReasonReadinessGatePassed)
+		})
 
-				It("should keep the ManifestWork when one mesh is deleted", func() {
-					expectMeshNotReady(otherMesh, otherNs)
-					util.DeleteResource(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, otherMesh, otherNs)
-					expectOperatorManifestWork(clusterName)
-				})
+		It("should wait for istiod to be ready with the Istiod readiness gate", func() {
+			createMesh(meshv1alpha1.ReadinessGateIstiod)
+			simulateOperatorInstalled(clusterName)
+			simulateOperatorInstalled(peerName)
+			probe := expectManifestWork(meshcontroller.ManifestWorkNameIstiodProbePrefix+"istio-system", clusterName)
+			expectMeshOwnedLabels(probe.Labels, meshName, testNs, clusterName)
+
+			Expect(util.ReportIstiodProbe(ctx, k8sClient, clusterName, "istio-system", map[string]string{
+				meshcontroller.FeedbackReplicas: "2", meshcontroller.FeedbackReadyReplicas: "1",
+			})).To(Succeed())
+			expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionPeerReady, meshv1alpha1.ReasonIstiodNotReady)
+			expectNotDistributed(clusterName)
+
+			Expect(util.ReportIstiodProbe(ctx, k8sClient, clusterName, "istio-system", map[string]string{
+				meshcontroller.FeedbackReplicas: "2", meshcontroller.FeedbackReadyReplicas: "2",
+			})).To(Succeed())
+			expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, clusterName)
+			expectPlacementExcludes(clusterName, clusterName, peerName)
+			expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionPeerReady, meshv1alpha1.ReasonReadinessGatePassed)
+
+			By("switching to the Operator readiness gate")
+			updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+				mesh.Spec.Security.Discovery.ReadinessGate = meshv1alpha1.ReadinessGateOperator
+			})
+			util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, probe.Name, clusterName)
+			expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, peerName)
+		})
 
-				It("should delete the ManifestWork when both meshes are deleted", func() {
-					expectMeshNotReady(otherMesh, otherNs)
-					util.DeleteResource(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, meshName, testNs)
-					util.DeleteResource(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, otherMesh, otherNs)
-					expectAllManifestWorksDeleted()
-				})
-			})
+		It("should distribute the remote secrets right away without a readiness gate", func() {
+			createMesh("")
+			Eventually(func(g Gomega) {
+				mesh := &meshv1alpha1.MultiClusterMesh{}
+				g.Expect(k8sClient.Get(ctx, key.Of(meshName, testNs), mesh)).To(Succeed())
+				g.Expect(mesh.Spec.Security.Discovery.ReadinessGate).To(Equal(meshv1alpha1.ReadinessGateNone))
+			}).Should(Succeed())
+
+			expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, clusterName)
+			expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, peerName)
+			expectPlacementExcludes(clusterName, clusterName)
+			expectClusterConditionReason(meshName, testNs, peerName, meshv1alpha1.ConditionPeerReady, meshv1alpha1.ReasonReadinessGatePassed)
 		})
 	})
 
@@ -456,6 +1450,68 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 			})
 		})
 
+		When("the ClusterProxy discovery transport has no clusterProxy config", func() {
+			It("should reject creation", func() {
+				expectInvalidCreateMeshFailure(meshName+"-proxy", testNs,
+					meshv1alpha1.MultiClusterMeshSpec{
+						ClusterSet: testClusterSet,
+						Security: meshv1alpha1.SecurityConfig{Discovery: meshv1alpha1.DiscoveryConfig{
+							Transport: meshv1alpha1.DiscoveryTransportClusterProxy,
+						}},
+					},
+					"clusterProxy is required when transport is ClusterProxy")
+			})
+		})
+
+		When("approvedCSV is set without Manual InstallPlan approval", func() {
+			It("should reject creation", func() {
+				expectInvalidCreateMeshFailure(meshName+"-approval", testNs,
+					meshv1alpha1.MultiClusterMeshSpec{
+						ClusterSet: testClusterSet,
+						Operator:   meshv1alpha1.OperatorConfig{ApprovedCSV: "servicemeshoperator3.v3.0.0"},
+					},
+					"approvedCSV requires installPlanApproval Manual")
+			})
+		})
+
+		When("the Manifests install mode has no ConfigMap", func() {
+			It("should reject creation", func() {
+				expectInvalidCreateMeshFailure(meshName+"-manifests", testNs,
+					meshv1alpha1.MultiClusterMeshSpec{
+						ClusterSet: testClusterSet,
+						Operator:   meshv1alpha1.OperatorConfig{InstallMode: meshv1alpha1.OperatorInstallModeManifests},
+					},
+					"manifests.configMapName is required when installMode is Manifests")
+			})
+		})
+
+		When("the Manifests install mode has a Subscription config", func() {
+			It("should reject creation", func() {
+				expectInvalidCreateMeshFailure(meshName+"-config", testNs,
+					meshv1alpha1.MultiClusterMeshSpec{
+						ClusterSet: testClusterSet,
+						Operator: meshv1alpha1.OperatorConfig{
+							InstallMode: meshv1alpha1.OperatorInstallModeManifests,
+							Manifests:   meshv1alpha1.OperatorManifests{ConfigMapName: "sail-operator-manifests"},
+							Config:      &meshv1alpha1.SubscriptionConfig{NodeSelector: map[string]string{"a": "b"}},
+						},
+					},
+					"config requires installMode OLM")
+			})
+		})
+
+		When("the rollout maxConcurrency is not a valid percentage", func() {
+			It("should reject creation", func() {
+				maxConcurrency := intstr.FromString("0%")
+				expectInvalidCreateMeshFailure(meshName+"-rollout", testNs,
+					meshv1alpha1.MultiClusterMeshSpec{
+						ClusterSet: testClusterSet,
+						Rollout:    &meshv1alpha1.RolloutStrategy{MaxConcurrency: &maxConcurrency},
+					},
+					"maxConcurrency must be a positive number or a percentage between 1% and 100%")
+			})
+		})
+
 		When("spec.clusterSet is changed on update", func() {
 			It("should reject the update", func() {
 				mesh := &meshv1alpha1.MultiClusterMesh{}
@@ -538,6 +1594,34 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 			})
 		})
 
+		When("a newer mesh has a different operator deletion policy", func() {
+			BeforeEach(func() {
+				util.CreateMultiClusterMesh(ctx, k8sClient, otherMesh, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+					ControlPlane:   meshv1alpha1.ControlPlaneConfig{Namespace: "istio-system-2"},
+					DeletionPolicy: meshv1alpha1.DeletionPolicy{Operator: meshv1alpha1.DeletionPolicyOrphan},
+				})
+			})
+
+			It("should block the newer mesh", func() {
+				expectMeshConditionReason(otherMesh, testNs, meshv1alpha1.ConditionReady, meshv1alpha1.ReasonOperatorConfigConflict)
+			})
+		})
+
+		When("a newer mesh only differs in the operator Subscription config", func() {
+			BeforeEach(func() {
+				util.CreateMultiClusterMesh(ctx, k8sClient, otherMesh, testNs, testClusterSet, meshv1alpha1.MultiClusterMeshSpec{
+					ControlPlane: meshv1alpha1.ControlPlaneConfig{Namespace: "istio-system-2"},
+					Operator: meshv1alpha1.OperatorConfig{Config: &meshv1alpha1.SubscriptionConfig{
+						NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+					}},
+				})
+			})
+
+			It("should block the newer mesh", func() {
+				expectMeshConditionReason(otherMesh, testNs, meshv1alpha1.ConditionReady, meshv1alpha1.ReasonOperatorConfigConflict)
+			})
+		})
+
 		When("a newer mesh has the same control plane namespace", func() {
 			BeforeEach(func() {
 				util.CreateMultiClusterMesh(ctx, k8sClient, otherMesh, testNs, testClusterSet)
@@ -591,6 +1675,139 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 
 			util.DeleteResource(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, meshName, testNs)
 		})
+
+		When("the cluster may still run an Istio control plane", func() {
+			guardName := meshcontroller.ManifestWorkNameDeletionGuardPrefix + "istio-system"
+			cpNsMWName := meshcontroller.ManifestWorkNameCPNSPrefix + "istio-system"
+
+			BeforeEach(func() {
+				util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+				updateClusterAnnotations(clusterName, map[string]string{util.AnnotationManualDeletionGuard: "true"})
+				util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet)
+				expectFinalizer(meshName, testNs)
+				expectControlPlaneNamespaceManifestWork(clusterName, "istio-system")
+
+				mesh := &meshv1alpha1.MultiClusterMesh{}
+				Expect(k8sClient.Get(ctx, key.Of(meshName, testNs), mesh)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, mesh)).To(Succeed())
+			})
+
+			expectDeletionBlocked := func() {
+				Consistently(func() error {
+					return k8sClient.Get(ctx, key.Of(cpNsMWName, clusterName), &workv1.ManifestWork{})
+				}).Should(Succeed())
+				Expect(k8sClient.Get(ctx, key.Of(meshName, testNs), &meshv1alpha1.MultiClusterMesh{})).To(Succeed())
+			}
+
+			It("should check the control plane read-only while the cluster has not reported it", func() {
+				guard := expectManifestWork(guardName, clusterName)
+				Expect(guard.Spec.ManifestConfigs).To(HaveLen(2))
+				for _, config := range guard.Spec.ManifestConfigs {
+					Expect(config.UpdateStrategy.Type).To(Equal(workv1.UpdateStrategyTypeReadOnly))
+				}
+				expectMeshOwnedLabels(guard.Labels, meshName, testNs, clusterName)
+
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionDeletionBlocked, meshv1alpha1.ReasonSafetyCheckPending)
+				expectDeletionBlocked()
+			})
+
+			It("should delete the mesh once the cluster reports no control plane", func() {
+				expectManifestWork(guardName, clusterName)
+				Expect(util.ReportDeletionGuard(ctx, k8sClient, clusterName, "istio-system", nil)).To(Succeed())
+
+				util.ExpectResourceDeleted(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, meshName, testNs)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, cpNsMWName, clusterName)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, guardName, clusterName)
+			})
+
+			It("should block the deletion while a control plane runs until the user confirms it", func() {
+				expectManifestWork(guardName, clusterName)
+				Expect(util.ReportDeletionGuard(ctx, k8sClient, clusterName, "istio-system", map[string]map[string]string{
+					"Istio": {
+						meshcontroller.FeedbackIstioNamespace: "istio-system",
+						meshcontroller.FeedbackIstioState:     "Healthy",
+						meshcontroller.FeedbackRevisionsInUse: "1",
+					},
+					"Deployment": {meshcontroller.FeedbackReadyReplicas: "1"},
+				})).To(Succeed())
+
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionDeletionBlocked, meshv1alpha1.ReasonControlPlanesRunning)
+				expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+					c := findCondition(g, cs.Conditions, meshv1alpha1.ConditionDeletionBlocked)
+					g.Expect(c.Reason).To(Equal(meshv1alpha1.ReasonControlPlanesRunning))
+					g.Expect(c.Message).To(ContainSubstring("Istio default in state Healthy with 1 revisions in use by injected workloads"))
+				})
+				expectDeletionBlocked()
+
+				updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+					metav1.SetMetaDataAnnotation(&mesh.ObjectMeta, meshcontroller.AnnotationConfirmDeletion, "true")
+				})
+				util.ExpectResourceDeleted(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, meshName, testNs)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, cpNsMWName, clusterName)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1.ManifestWork{}, meshcontroller.OperatorManifestWorkName, clusterName)
+			})
+		})
+
+		When("the work agent is still cleaning up the cluster", func() {
+			// The work agent holds a finalizer on the ManifestWorks until it deleted their resources from the cluster.
+			const cleanupFinalizer = "test.mesh.open-cluster-management.io/cleanup"
+			cpNsMWName := meshcontroller.ManifestWorkNameCPNSPrefix + "istio-system"
+
+			updateFinalizers := func(finalizers []string) {
+				Eventually(func() error {
+					work := &workv1.ManifestWork{}
+					if err := k8sClient.Get(ctx, key.Of(cpNsMWName, clusterName), work); err != nil {
+						return err
+					}
+					work.Finalizers = finalizers
+					return k8sClient.Update(ctx, work)
+				}).Should(Succeed())
+			}
+
+			deleteMesh := func(spec meshv1alpha1.MultiClusterMeshSpec) {
+				util.CreateManagedCluster(ctx, k8sClient, clusterName, testClusterSet)
+				util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet, spec)
+				expectFinalizer(meshName, testNs)
+				expectControlPlaneNamespaceManifestWork(clusterName, "istio-system")
+				updateFinalizers([]string{cleanupFinalizer})
+
+				mesh := &meshv1alpha1.MultiClusterMesh{}
+				Expect(k8sClient.Get(ctx, key.Of(meshName, testNs), mesh)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, mesh)).To(Succeed())
+			}
+
+			AfterEach(func() {
+				updateFinalizers(nil)
+			})
+
+			It("should keep the mesh until the cluster deleted its resources", func() {
+				deleteMesh(meshv1alpha1.MultiClusterMeshSpec{})
+
+				expectMeshConditionReason(meshName, testNs, meshv1alpha1.ConditionTerminating, meshv1alpha1.ReasonCleanupInProgress)
+				expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+					c := findCondition(g, cs.Conditions, meshv1alpha1.ConditionTerminating)
+					g.Expect(c.Reason).To(Equal(meshv1alpha1.ReasonCleanupInProgress))
+					g.Expect(c.Message).To(ContainSubstring("ManifestWork " + cpNsMWName))
+				})
+				Consistently(func() error {
+					return k8sClient.Get(ctx, key.Of(meshName, testNs), &meshv1alpha1.MultiClusterMesh{})
+				}).Should(Succeed())
+
+				updateFinalizers(nil)
+				util.ExpectResourceDeleted(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, meshName, testNs)
+			})
+
+			It("should skip the cluster after the cleanup timeout", func() {
+				deleteMesh(meshv1alpha1.MultiClusterMeshSpec{
+					DeletionPolicy: meshv1alpha1.DeletionPolicy{CleanupTimeout: &metav1.Duration{Duration: 2 * time.Second}},
+				})
+
+				util.ExpectResourceDeleted(ctx, k8sClient, &meshv1alpha1.MultiClusterMesh{}, meshName, testNs)
+				work := &workv1.ManifestWork{}
+				Expect(k8sClient.Get(ctx, key.Of(cpNsMWName, clusterName), work)).To(Succeed())
+				Expect(work.DeletionTimestamp).NotTo(BeNil())
+			})
+		})
 	})
 
 	Context("Certificate distribution", func() {
@@ -799,15 +2016,11 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 				Expect(clusterSetBinding.Spec.ClusterSet).To(Equal(testClusterSet))
 			})
 
-			It("should create Placement for the ManagedClusterSet", func() {
+			It("should not create a Placement before the ManagedServiceAccount token exists", func() {
 				util.CreateMultiClusterMesh(ctx, k8sClient, meshName, testNs, testClusterSet)
-				placement := expectPlacement(meshName, testNs)
-				Expect(placement.OwnerReferences).To(HaveLen(1))
-				Expect(placement.OwnerReferences[0].Name).To(Equal(meshName))
-				Expect(placement.Labels[meshcontroller.ManagedByLabel]).To(Equal(meshcontroller.ManagedByValue))
-				Expect(placement.Labels[meshcontroller.MeshNameLabel]).To(Equal(meshName))
-				Expect(placement.Labels[meshcontroller.MeshNamespaceLabel]).To(Equal(testNs))
-				Expect(placement.Spec.ClusterSets).To(ContainElement(testClusterSet))
+				expectManagedServiceAccount(testNs, meshName, clusterName)
+				expectNoPlacement(testNs)
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionDiscoveryReady, meshv1alpha1.ReasonTokenPending)
 			})
 
 			When("the ManagedServiceAccount exists", func() {
@@ -853,62 +2066,162 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 					ControlPlane: meshv1alpha1.ControlPlaneConfig{Namespace: "istio-system"},
 				})
 				setupMsaTokenSecret(testNs, meshName, clusterName)
+				simulateOperatorInstalled(clusterName)
 			})
 
-			It("should create ManifestWorkReplicaSet for the ManagedClusterSet", func() {
-				placement := expectPlacement(meshName, testNs)
-				mwrset := expectManifestWorkReplicaSet(meshName, testNs)
+			It("should create a Placement that excludes the source cluster", func() {
+				placement := expectRemoteSecretPlacement(meshName, testNs, clusterName)
+				Expect(placement.OwnerReferences).To(HaveLen(1))
+				Expect(placement.OwnerReferences[0].Name).To(Equal(meshName))
+				expectMeshOwnedLabels(placement.Labels, meshName, testNs, clusterName)
+				Expect(placement.Spec.ClusterSets).To(ConsistOf(testClusterSet))
+				Expect(placement.Spec.Predicates).To(HaveLen(1))
+				Expect(placement.Spec.Predicates[0].RequiredClusterSelector.LabelSelector.MatchExpressions).To(ConsistOf(
+					metav1.LabelSelectorRequirement{
+						Key:      clusterv1.ClusterNameLabelKey,
+						Operator: metav1.LabelSelectorOpNotIn,
+						Values:   []string{clusterName},
+					}))
+			})
+
+			It("should create a ManifestWorkReplicaSet with only the source cluster's remote secret", func() {
+				mwrset := expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, clusterName)
 				Expect(mwrset.OwnerReferences).To(HaveLen(1))
 				Expect(mwrset.OwnerReferences[0].Name).To(Equal(meshName))
-				Expect(mwrset.Labels[meshcontroller.ManagedByLabel]).To(Equal(meshcontroller.ManagedByValue))
-				Expect(mwrset.Labels[meshcontroller.MeshNameLabel]).To(Equal(meshName))
-				Expect(mwrset.Labels[meshcontroller.MeshNamespaceLabel]).To(Equal(testNs))
-				Expect(mwrset.Spec.PlacementRefs[0].Name).To(Equal(placement.Name))
+				expectMeshOwnedLabels(mwrset.Labels, meshName, testNs, clusterName)
+				Expect(mwrset.Spec.PlacementRefs).To(ConsistOf(workv1alpha1.LocalPlacementReference{Name: mwrset.Name}))
 				Expect(mwrset.Spec.ManifestWorkTemplate.Workload.Manifests).To(HaveLen(1))
 				expectRemoteSecret(mwrset.Spec.ManifestWorkTemplate.Workload.Manifests[0], clusterName, "istio-system")
 			})
 
-			It("should update ManifestWorkReplicaSet for newly added cluster", func() {
+			It("should distribute the remote secret of a newly added cluster separately", func() {
 				cluster2Name := util.UniqueName("cluster")
 				util.CreateManagedCluster(ctx, k8sClient, cluster2Name, testClusterSet)
 				setupMsaTokenSecret(testNs, meshName, cluster2Name)
+				simulateOperatorInstalled(cluster2Name)
 
-				expectManifestWorkReplicaSetContent(meshName, testNs, func(g Gomega, mwrset *workv1alpha1.ManifestWorkReplicaSet) {
-					g.Expect(mwrset.Spec.ManifestWorkTemplate.Workload.Manifests).To(HaveLen(2))
-					for _, cluster := range []string{clusterName, cluster2Name} {
-						found := false
-						for _, m := range mwrset.Spec.ManifestWorkTemplate.Workload.Manifests {
-							secret := &corev1.Secret{}
-							g.Expect(unmarshalManifest(m, secret)).To(Succeed())
-							if secret.Name == "istio-remote-secret-"+cluster {
-								found = true
-								break
-							}
-						}
-						g.Expect(found).To(BeTrue(), "no remote secret for %s", cluster)
-					}
-				})
+				mwrset := expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, cluster2Name)
+				Expect(mwrset.Spec.ManifestWorkTemplate.Workload.Manifests).To(HaveLen(1))
+				expectRemoteSecret(mwrset.Spec.ManifestWorkTemplate.Workload.Manifests[0], cluster2Name, "istio-system")
+
+				placement := expectRemoteSecretPlacement(meshName, testNs, cluster2Name)
+				Expect(placement.Spec.Predicates[0].RequiredClusterSelector.LabelSelector.MatchExpressions[0].Values).To(ConsistOf(cluster2Name))
 			})
 
-			It("should drop one ManifestWorkReplicaSet manifest when removing one cluster from the ManagedClusterSet", func() {
+			It("should remove the distribution of a cluster removed from the ManagedClusterSet", func() {
 				cluster2Name := util.UniqueName("cluster")
 				util.CreateManagedCluster(ctx, k8sClient, cluster2Name, testClusterSet)
 				setupMsaTokenSecret(testNs, meshName, cluster2Name)
+				simulateOperatorInstalled(cluster2Name)
+				expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, cluster2Name)
+
 				updateClusterSetLabel(clusterName, "")
 
-				expectManifestWorkReplicaSetContent(meshName, testNs, func(g Gomega, mwrset *workv1alpha1.ManifestWorkReplicaSet) {
-					g.Expect(mwrset.Spec.ManifestWorkTemplate.Workload.Manifests).To(HaveLen(1))
-					expectRemoteSecret(mwrset.Spec.ManifestWorkTemplate.Workload.Manifests[0], cluster2Name, "istio-system")
+				name := remoteSecretDistributionName(meshName, testNs, clusterName)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1alpha1.ManifestWorkReplicaSet{}, name, testNs)
+				util.ExpectResourceDeleted(ctx, k8sClient, &clusterv1beta1.Placement{}, name, testNs)
+				expectRemoteSecretManifestWorkReplicaSet(meshName, testNs, cluster2Name)
+			})
+
+			It("should delete the legacy mesh-wide ManifestWorkReplicaSet and Placement", func() {
+				mesh := &meshv1alpha1.MultiClusterMesh{}
+				Expect(k8sClient.Get(ctx, key.Of(meshName, testNs), mesh)).To(Succeed())
+				labels := map[string]string{
+					meshcontroller.ManagedByLabel:     meshcontroller.ManagedByValue,
+					meshcontroller.MeshNameLabel:      meshName,
+					meshcontroller.MeshNamespaceLabel: testNs,
+				}
+				Expect(k8sClient.Create(ctx, &clusterv1beta1.Placement{
+					ObjectMeta: metav1.ObjectMeta{Name: meshName, Namespace: testNs, Labels: labels},
+				})).To(Succeed())
+				Expect(k8sClient.Create(ctx, &workv1alpha1.ManifestWorkReplicaSet{
+					ObjectMeta: metav1.ObjectMeta{Name: meshName, Namespace: testNs, Labels: labels},
+					Spec:       workv1alpha1.ManifestWorkReplicaSetSpec{PlacementRefs: []workv1alpha1.LocalPlacementReference{{Name: meshName}}},
+				})).To(Succeed())
+
+				triggerReconcile(meshName, testNs)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1alpha1.ManifestWorkReplicaSet{}, meshName, testNs)
+				util.ExpectResourceDeleted(ctx, k8sClient, &clusterv1beta1.Placement{}, meshName, testNs)
+			})
+
+			It("should use the API server settings from the ManagedCluster annotations", func() {
+				updateClusterAnnotations(clusterName, map[string]string{
+					meshv1alpha1.AnnotationAPIServerURL:           "https://api.private.example.com:6443",
+					meshv1alpha1.AnnotationAPIServerTLSServerName: "kubernetes.default.svc",
+					meshv1alpha1.AnnotationAPIServerProxyURL:      "http://proxy.example.com:3128",
+				})
+
+				expectRemoteSecretManifestWorkReplicaSetContent(meshName, testNs, clusterName, func(g Gomega, mwrset *workv1alpha1.ManifestWorkReplicaSet) {
+					cluster := expectRemoteSecretKubeconfigCluster(g, mwrset, clusterName)
+					g.Expect(cluster.Server).To(Equal("https://api.private.example.com:6443"))
+					g.Expect(cluster.TLSServerName).To(Equal("kubernetes.default.svc"))
+					g.Expect(cluster.ProxyURL).To(Equal("http://proxy.example.com:3128"))
+					g.Expect(cluster.CertificateAuthorityData).To(Equal([]byte("test-ca-data")))
 				})
 			})
 
-			It("should cleanup ManifestWorkReplicaSet Manifests when removing all clusters from the ManagedClusterSet", func() {
-				updateClusterSetLabel(clusterName, "")
-				Eventually(func(g Gomega) {
-					mwrset := &workv1alpha1.ManifestWorkReplicaSet{}
-					g.Expect(k8sClient.Get(ctx, key.Of(meshName, testNs), mwrset)).To(Succeed())
-					g.Expect(mwrset.Spec.ManifestWorkTemplate.Workload.Manifests).To(BeEmpty())
-				}).Should(Succeed())
+			It("should use the client config CABundle when the CA source is ClientConfig", func() {
+				cluster := &clusterv1.ManagedCluster{}
+				Expect(k8sClient.Get(ctx, key.Of(clusterName), cluster)).To(Succeed())
+				cluster.Spec.ManagedClusterClientConfigs[0].CABundle = []byte("client-config-ca")
+				Expect(k8sClient.Update(ctx, cluster)).To(Succeed())
+				updateClusterAnnotations(clusterName, map[string]string{
+					meshv1alpha1.AnnotationAPIServerCASource: string(meshv1alpha1.APIServerCASourceClientConfig),
+				})
+
+				expectRemoteSecretManifestWorkReplicaSetContent(meshName, testNs, clusterName, func(g Gomega, mwrset *workv1alpha1.ManifestWorkReplicaSet) {
+					cluster := expectRemoteSecretKubeconfigCluster(g, mwrset, clusterName)
+					g.Expect(cluster.CertificateAuthorityData).To(Equal([]byte("client-config-ca")))
+				})
+			})
+
+			It("should route the remote secret through the cluster-proxy when the ClusterProxy transport is selected", func() {
+				updateMesh(meshName, testNs, func(mesh *meshv1alpha1.MultiClusterMesh) {
+					mesh.Spec.Security.Discovery.Transport = meshv1alpha1.DiscoveryTransportClusterProxy
+					mesh.Spec.Security.Discovery.ClusterProxy = &meshv1alpha1.ClusterProxyConfig{
+						URL:         "https://cluster-proxy.hub.example.com",
+						CABundle:    []byte("proxy-ca"),
+						Impersonate: "system:serviceaccount:istio-system:istio-reader",
+					}
+				})
+
+				expectRemoteSecretManifestWorkReplicaSetContent(meshName, testNs, clusterName, func(g Gomega, mwrset *workv1alpha1.ManifestWorkReplicaSet) {
+					cluster := expectRemoteSecretKubeconfigCluster(g, mwrset, clusterName)
+					g.Expect(cluster.Server).To(Equal("https://cluster-proxy.hub.example.com/" + clusterName))
+					g.Expect(cluster.CertificateAuthorityData).To(Equal([]byte("proxy-ca")))
+				})
+			})
+
+			It("should report the cluster's remote secret as distributed once the ManifestWorkReplicaSet is rolled out", func() {
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionDiscoveryReady, meshv1alpha1.ReasonDistributionPending)
+
+				simulateRemoteSecretDistribution(meshName, testNs, clusterName)
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionDiscoveryReady, meshv1alpha1.ReasonDistributed)
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionRemoteSecretsApplied, meshv1alpha1.ReasonApplied)
+			})
+
+			It("should report the token expiration and last rotation time", func() {
+				msa := expectManagedServiceAccount(testNs, meshName, clusterName)
+				expiration := metav1.NewTime(time.Now().Add(time.Hour).Truncate(time.Second))
+				msa.Status.ExpirationTimestamp = &expiration
+				Expect(k8sClient.Status().Update(ctx, msa)).To(Succeed())
+
+				expectClusterStatus(meshName, testNs, clusterName, func(g Gomega, _ *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+					g.Expect(cs.Discovery).NotTo(BeNil())
+					g.Expect(cs.Discovery.TokenExpirationTime).NotTo(BeNil())
+					g.Expect(cs.Discovery.TokenExpirationTime.Equal(&expiration)).To(BeTrue())
+					g.Expect(cs.Discovery.LastTokenRotationTime).NotTo(BeNil())
+					g.Expect(cs.Discovery.LastTokenRotationTime.Equal(&msa.Status.TokenSecretRef.LastRefreshTimestamp)).To(BeTrue())
+				})
+			})
+
+			It("should report a cluster without a usable API endpoint", func() {
+				updateClusterAnnotations(clusterName, map[string]string{
+					meshv1alpha1.AnnotationAPIServerURLPattern: "no-such-endpoint",
+				})
+
+				expectClusterConditionReason(meshName, testNs, clusterName, meshv1alpha1.ConditionDiscoveryReady, meshv1alpha1.ReasonNoAPIEndpoint)
+				util.ExpectResourceDeleted(ctx, k8sClient, &workv1alpha1.ManifestWorkReplicaSet{}, remoteSecretDistributionName(meshName, testNs, clusterName), testNs)
 			})
 
 			It("should update ManifestWorkReplicaSet when ManagedServiceAccount secret is updated", func() {
@@ -917,7 +2230,7 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 				oldTime := msa.Status.TokenSecretRef.LastRefreshTimestamp
 				oldSec := &corev1.Secret{}
 
-				expectManifestWorkReplicaSetContent(meshName, testNs, func(g Gomega, mwrset *workv1alpha1.ManifestWorkReplicaSet) {
+				expectRemoteSecretManifestWorkReplicaSetContent(meshName, testNs, clusterName, func(g Gomega, mwrset *workv1alpha1.ManifestWorkReplicaSet) {
 					g.Expect(mwrset.Spec.ManifestWorkTemplate.Workload.Manifests).NotTo(BeEmpty())
 					manifests := mwrset.Spec.ManifestWorkTemplate.Workload.Manifests
 					g.Expect(unmarshalManifest(manifests[0], oldSec)).To(Succeed())
@@ -932,7 +2245,7 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 					g.Expect(msa.Status.TokenSecretRef.LastRefreshTimestamp).NotTo(Equal(oldTime))
 				}).Should(Succeed())
 
-				expectManifestWorkReplicaSetContent(meshName, testNs, func(g Gomega, mwrset *workv1alpha1.ManifestWorkReplicaSet) {
+				expectRemoteSecretManifestWorkReplicaSetContent(meshName, testNs, clusterName, func(g Gomega, mwrset *workv1alpha1.ManifestWorkReplicaSet) {
 					manifests := mwrset.Spec.ManifestWorkTemplate.Workload.Manifests
 					newSec := &corev1.Secret{}
 					g.Expect(unmarshalManifest(manifests[0], newSec)).To(Succeed())
@@ -965,7 +2278,7 @@ var _ = Describe("MultiClusterMesh Controller", func() {
 				util.CreateManagedCluster(ctx, k8sClient, clusterName, otherClusterSet)
 				util.CreateManagedClusterSet(ctx, k8sClient, otherClusterSet)
 				expectManagedClusterSetBinding(testNs, otherClusterSet)
-				expectPlacement(meshName, testNs)
+				expectManagedServiceAccount(testNs, meshName, clusterName)
 			})
 		})
 
@@ -1055,6 +2368,15 @@ func updateClusterLabel(clusterName, labelKey, labelValue string) {
 	Expect(k8sClient.Update(ctx, cluster)).To(Succeed())
 }
 
+func updateClusterAnnotations(clusterName string, annotations map[string]string) {
+	cluster := &clusterv1.ManagedCluster{}
+	Expect(k8sClient.Get(ctx, key.Of(clusterName), cluster)).To(Succeed())
+	for k, v := range annotations {
+		metav1.SetMetaDataAnnotation(&cluster.ObjectMeta, k, v)
+	}
+	Expect(k8sClient.Update(ctx, cluster)).To(Succeed())
+}
+
 func updateClusterSetLabel(clusterName, newClusterSet string) {
 	updateClusterLabel(clusterName, meshcontroller.ClusterSetLabel, newClusterSet)
 }
@@ -1258,26 +2580,33 @@ func expectManagedClusterSetBinding(meshNamespace, clusterSet string) *clusterv1
 	return binding
 }
 
-func expectPlacement(meshName, meshNamespace string) *clusterv1beta1.Placement {
+// remoteSecretDistributionName returns the name of the Placement, the ManifestWorkReplicaSet and its ManifestWorks
+// distributing the remote secret of a cluster.
+func remoteSecretDistributionName(meshName, meshNamespace, clusterName string) string {
+	mesh := &meshv1alpha1.MultiClusterMesh{ObjectMeta: metav1.ObjectMeta{Name: meshName, Namespace: meshNamespace}}
+	return meshcontroller.RemoteSecretDistributionName(mesh, clusterName)
+}
+
+func expectRemoteSecretPlacement(meshName, meshNamespace, clusterName string) *clusterv1beta1.Placement {
 	placement := &clusterv1beta1.Placement{}
 	Eventually(func() error {
-		return k8sClient.Get(ctx, key.Of(meshName, meshNamespace), placement)
+		return k8sClient.Get(ctx, key.Of(remoteSecretDistributionName(meshName, meshNamespace, clusterName), meshNamespace), placement)
 	}).Should(Succeed())
 	return placement
 }
 
-func expectManifestWorkReplicaSet(meshName, meshNamespace string) *workv1alpha1.ManifestWorkReplicaSet {
+func expectRemoteSecretManifestWorkReplicaSet(meshName, meshNamespace, clusterName string) *workv1alpha1.ManifestWorkReplicaSet {
 	mwrset := &workv1alpha1.ManifestWorkReplicaSet{}
 	Eventually(func() error {
-		return k8sClient.Get(ctx, key.Of(meshName, meshNamespace), mwrset)
+		return k8sClient.Get(ctx, key.Of(remoteSecretDistributionName(meshName, meshNamespace, clusterName), meshNamespace), mwrset)
 	}).Should(Succeed())
 	return mwrset
 }
 
-func expectManifestWorkReplicaSetContent(meshName, meshNamespace string, assert func(Gomega, *workv1alpha1.ManifestWorkReplicaSet)) {
+func expectRemoteSecretManifestWorkReplicaSetContent(meshName, meshNamespace, clusterName string, assert func(Gomega, *workv1alpha1.ManifestWorkReplicaSet)) {
 	Eventually(func(g Gomega) {
 		mwrset := &workv1alpha1.ManifestWorkReplicaSet{}
-		g.Expect(k8sClient.Get(ctx, key.Of(meshName, meshNamespace), mwrset)).To(Succeed())
+		g.Expect(k8sClient.Get(ctx, key.Of(remoteSecretDistributionName(meshName, meshNamespace, clusterName), meshNamespace), mwrset)).To(Succeed())
 		assert(g, mwrset)
 	}).Should(Succeed())
 }
@@ -1292,6 +2621,16 @@ func expectRemoteSecret(manifest workv1.Manifest, clusterName, expectedNamespace
 	Expect(secret.Data).To(HaveKey(clusterName))
 }
 
+func expectRemoteSecretKubeconfigCluster(g Gomega, mwrset *workv1alpha1.ManifestWorkReplicaSet, clusterName string) *clientcmdapi.Cluster {
+	g.Expect(mwrset.Spec.ManifestWorkTemplate.Workload.Manifests).To(HaveLen(1))
+	secret := &corev1.Secret{}
+	g.Expect(unmarshalManifest(mwrset.Spec.ManifestWorkTemplate.Workload.Manifests[0], secret)).To(Succeed())
+	kubeconfig, err := clientcmd.Load(secret.Data[clusterName])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(kubeconfig.Clusters).To(HaveKey(clusterName))
+	return kubeconfig.Clusters[clusterName]
+}
+
 // createMsaSecret creates a ServiceAccount and a secret that simulates what ManagedServiceAccount controller would create.
 func createMsaSecret(ctx context.Context, k8sClient client.Client, msaName, clusterName string) {
 	secret := &corev1.Secret{
@@ -1347,10 +2686,57 @@ func simulateMsaTokenSecretRotation(meshNamespace, meshName, clusterName string)
 	expectMeshNotReady(meshName, meshNamespace)
 }
 
+// simulateOperatorInstalled simulates the work agent reporting the operator installed by the operator ManifestWork of a
+// cluster, so that the cluster passes the default readiness gate.
+func simulateOperatorInstalled(clusterName string) {
+	expectOperatorManifestWork(clusterName)
+	util.SetManifestWorkFeedback(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName,
+		meshcontroller.FeedbackInstalledCSV, "sailoperator.v1.0.0")
+}
+
+// simulateCacertsApplied simulates cert-manager issuing the intermediate CA of a cluster and the work agent applying the
+// cacerts ManifestWork delivering it.
+func simulateCacertsApplied(meshNamespace, meshName, clusterName string) {
+	util.CreateCacertsSecret(ctx, k8sClient, meshNamespace, clusterName, meshName, meshNamespace)
+	expectCacertsManifestWork(clusterName)
+	util.SetManifestWorkConditions(ctx, k8sClient, meshcontroller.ManifestWorkNameCacerts, clusterName, util.WorkAppliedConditions()...)
+}
+
+// simulateMeshManifestWorksApplied simulates the work agent applying the control plane namespace and operator ManifestWorks of a cluster.
+func simulateMeshManifestWorksApplied(clusterName, cpNamespace string) {
+	expectControlPlaneNamespaceManifestWork(clusterName, cpNamespace)
+	util.SetManifestWorkConditions(ctx, k8sClient, meshcontroller.ManifestWorkNameCPNSPrefix+cpNamespace, clusterName, util.WorkAppliedConditions()...)
+	util.SetManifestWorkConditions(ctx, k8sClient, meshcontroller.OperatorManifestWorkName, clusterName, util.WorkAppliedConditions()...)
+}
+
+// simulateRemoteSecretDistribution simulates the OCM ManifestWorkReplicaSet controller and work agents
+// successfully delivering the remote secret of every cluster to all its peers.
+func simulateRemoteSecretDistribution(meshName, meshNamespace string, clusterNames ...string) {
+	for _, source := range clusterNames {
+		mwrset := expectRemoteSecretManifestWorkReplicaSet(meshName, meshNamespace, source)
+		for _, peer := range clusterNames {
+			if peer != source {
+				util.CreateManifestWorkReplicaSetWork(ctx, k8sClient, mwrset.Name, meshNamespace, peer, util.WorkAppliedConditions()...)
+			}
+		}
+		util.SetManifestWorkReplicaSetSummary(ctx, k8sClient, mwrset.Name, meshNamespace, len(clusterNames)-1, len(clusterNames)-1)
+	}
+}
+
 func unmarshalManifest(manifest workv1.Manifest, into interface{}) error {
 	return json.Unmarshal(manifest.Raw, into)
 }
 
+func expectNoInstallPlanApprovalManifestWork(clusterNamespace string) {
+	isNotFound := func() bool {
+		work := &workv1.ManifestWork{}
+		err := k8sClient.Get(ctx, key.Of(meshcontroller.ManifestWorkNameInstallPlanApproval, clusterNamespace), work)
+		return errors.IsNotFound(err)
+	}
+	Eventually(isNotFound).Should(BeTrue())
+	Consistently(isNotFound).Should(BeTrue())
+}
+
 func expectOLMClusterRole(work *workv1.ManifestWork, index int) {
 	cr := &rbacv1.ClusterRole{}
 	Expect(unmarshalManifest(work.Spec.Workload.Manifests[index], cr)).To(Succeed())
@@ -1358,7 +2744,7 @@ func expectOLMClusterRole(work *workv1.ManifestWork, index int) {
 	Expect(cr.Labels).To(HaveKeyWithValue("open-cluster-management.io/aggregate-to-work", "true"))
 	Expect(cr.Rules).To(HaveLen(1))
 	Expect(cr.Rules[0].APIGroups).To(ConsistOf("operators.coreos.com"))
-	Expect(cr.Rules[0].Resources).To(ConsistOf("operatorgroups", "subscriptions", "catalogsources", "clusterserviceversions"))
+	Expect(cr.Rules[0].Resources).To(ConsistOf("operatorgroups", "subscriptions", "catalogsources", "clusterserviceversions", "installplans"))
 	Expect(cr.Rules[0].Verbs).To(ConsistOf("create", "get", "list", "update", "patch", "delete"))
 }
 
@@ -1387,6 +2773,17 @@ func expectSubscription(work *workv1.ManifestWork, index int, expected meshv1alp
 	Expect(sub.Spec.CatalogSourceNamespace).To(Equal(expected.SourceNamespace))
 	Expect(sub.Spec.Channel).To(Equal(expected.Channel))
 	Expect(sub.Spec.InstallPlanApproval).To(Equal(expected.InstallPlanApproval))
+	if expected.Config == nil {
+		Expect(sub.Spec.Config).To(BeNil())
+		return
+	}
+	Expect(sub.Spec.Config).NotTo(BeNil())
+	Expect(sub.Spec.Config.NodeSelector).To(Equal(expected.Config.NodeSelector))
+	Expect(sub.Spec.Config.Tolerations).To(Equal(expected.Config.Tolerations))
+	Expect(sub.Spec.Config.Resources.Limits.Memory().Equal(*expected.Config.Resources.Limits.Memory())).To(BeTrue())
+	Expect(sub.Spec.Config.Env).To(Equal(expected.Config.Env))
+	Expect(sub.Spec.Config.Volumes).To(Equal(expected.Config.Volumes))
+	Expect(sub.Spec.Config.VolumeMounts).To(Equal(expected.Config.VolumeMounts))
 }
 
 func findCondition(g Gomega, conditions []metav1.Condition, conditionType string) *metav1.Condition {
@@ -1416,14 +2813,24 @@ func expectMeshReady(meshName, namespace string) {
 }
 
 func expectClusterOperatorConditionReason(meshName, namespace, clusterName, reason string) {
+	expectClusterConditionReason(meshName, namespace, clusterName, meshv1alpha1.ConditionOperatorInstalled, reason)
+}
+
+func expectClusterConditionReason(meshName, namespace, clusterName, conditionType, reason string) {
+	expectClusterStatus(meshName, namespace, clusterName, func(g Gomega, mesh *meshv1alpha1.MultiClusterMesh, cs *meshv1alpha1.ClusterMeshStatus) {
+		c := findCondition(g, cs.Conditions, conditionType)
+		g.Expect(c.Reason).To(Equal(reason))
+		g.Expect(c.ObservedGeneration).To(Equal(mesh.Generation))
+	})
+}
+
+func expectClusterStatus(meshName, namespace, clusterName string, assert func(Gomega, *meshv1alpha1.MultiClusterMesh, *meshv1alpha1.ClusterMeshStatus)) {
 	Eventually(func(g Gomega) {
 		mesh := &meshv1alpha1.MultiClusterMesh{}
 		g.Expect(k8sClient.Get(ctx, key.Of(meshName, namespace), mesh)).To(Succeed())
 		for _, cs := range mesh.Status.ClusterStatus {
 			if cs.ClusterName == clusterName {
-				c := findCondition(g, cs.Conditions, meshv1alpha1.ConditionOperatorInstalled)
-				g.Expect(c.Reason).To(Equal(reason))
-				g.Expect(c.ObservedGeneration).To(Equal(mesh.Generation))
+				assert(g, mesh, &cs)
 				return
 			}
 		}
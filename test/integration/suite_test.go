@@ -5,6 +5,7 @@ package integration
 import (
 	"context"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,9 +25,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	meshv1alpha1 "github.com/stolostron/multicluster-mesh-addon/pkg/apis/mesh/v1alpha1"
 	meshcontroller "github.com/stolostron/multicluster-mesh-addon/pkg/hub/mesh"
+	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
 	"github.com/stolostron/multicluster-mesh-addon/test/util"
 	msav1beta1 "open-cluster-management.io/managed-serviceaccount/apis/authentication/v1beta1"
 )
@@ -96,12 +99,45 @@ var _ = BeforeSuite(func() {
 
 	Expect(meshcontroller.RegisterController(mgr)).NotTo(HaveOccurred())
 
+	// No work agent runs in the test environment, so operator probes are answered as if no operator Subscription
+	// existed on the clusters, and deletion guards as if no Istio control plane ran on them, except on clusters
+	// where the test reports them itself.
+	Expect(ctrl.NewControllerManagedBy(mgr).
+		Named("probe-responder").
+		For(&workv1.ManifestWork{}).
+		Complete(reconcile.Func(respondToProbes))).To(Succeed())
+
 	go func() {
 		defer GinkgoRecover()
 		Expect(mgr.Start(ctx)).NotTo(HaveOccurred())
 	}()
 })
 
+func respondToProbes(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	cpNamespace, guard := strings.CutPrefix(req.Name, meshcontroller.ManifestWorkNameDeletionGuardPrefix)
+	if req.Name != meshcontroller.ManifestWorkNameOperatorProbe && !guard {
+		return reconcile.Result{}, nil
+	}
+	cluster := &clusterv1.ManagedCluster{}
+	if err := k8sClient.Get(ctx, key.Of(req.Namespace), cluster); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	work := &workv1.ManifestWork{}
+	if err := k8sClient.Get(ctx, req.NamespacedName, work); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if guard {
+		if cluster.Annotations[util.AnnotationManualDeletionGuard] == "true" || util.DeletionGuardReported(work) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, client.IgnoreNotFound(util.ReportDeletionGuard(ctx, k8sClient, req.Namespace, cpNamespace, nil))
+	}
+	if cluster.Annotations[util.AnnotationManualOperatorProbe] == "true" || util.OperatorProbeReported(work) {
+		return reconcile.Result{}, nil
+	}
+	return reconcile.Result{}, client.IgnoreNotFound(util.ReportOperatorProbe(ctx, k8sClient, req.Namespace, nil))
+}
+
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
 	cancel()
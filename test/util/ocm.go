@@ -2,13 +2,21 @@ package util
 
 import (
 	"context"
+	"encoding/json"
+	"maps"
+	"slices"
 
 	. "github.com/onsi/gomega"
+	meshcontroller "github.com/stolostron/multicluster-mesh-addon/pkg/hub/mesh"
 	"github.com/stolostron/multicluster-mesh-addon/pkg/key"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta2"
 	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -40,9 +48,59 @@ func CreateManagedCluster(ctx context.Context, k8sClient client.Client, name, cl
 	})).To(Succeed())
 }
 
+// SetManagedClusterClaims updates a ManagedCluster's status to report the given ClusterClaims,
+// simulating what the registration agent does on a real spoke cluster.
+func SetManagedClusterClaims(ctx context.Context, k8sClient client.Client, name string, claims map[string]string) {
+	cluster := &clusterv1.ManagedCluster{}
+	Expect(k8sClient.Get(ctx, key.Of(name), cluster)).To(Succeed())
+	cluster.Status.ClusterClaims = nil
+	for _, claimName := range slices.Sorted(maps.Keys(claims)) {
+		cluster.Status.ClusterClaims = append(cluster.Status.ClusterClaims, clusterv1.ManagedClusterClaim{Name: claimName, Value: claims[claimName]})
+	}
+	Expect(k8sClient.Status().Update(ctx, cluster)).To(Succeed())
+}
+
 // SetManifestWorkFeedback updates a ManifestWork's status to include a string feedback value,
 // simulating what the OCM work agent does on a real spoke cluster.
 func SetManifestWorkFeedback(ctx context.Context, k8sClient client.Client, workName, namespace, feedbackName, feedbackValue string) {
+	SetManifestWorkFeedbackValues(ctx, k8sClient, workName, namespace, map[string]string{feedbackName: feedbackValue})
+}
+
+// SetManifestWorkFeedbackValues updates a ManifestWork's status to include the given string feedback values,
+// replacing any previously reported values.
+func SetManifestWorkFeedbackValues(ctx context.Context, k8sClient client.Client, workName, namespace string, feedback map[string]string) {
+	values := make([]workv1.FeedbackValue, 0, len(feedback))
+	for _, name := range slices.Sorted(maps.Keys(feedback)) {
+		value := feedback[name]
+		values = append(values, workv1.FeedbackValue{
+			Name: name,
+			Value: workv1.FieldValue{
+				Type:   workv1.String,
+				String: &value,
+			},
+		})
+	}
+	setManifestWorkFeedbackValues(ctx, k8sClient, workName, namespace, values)
+}
+
+// SetManifestWorkIntegerFeedbackValues updates a ManifestWork's status to include the given integer feedback values,
+// such as the replica counts the work agent reports for Deployments, replacing any previously reported values.
+func SetManifestWorkIntegerFeedbackValues(ctx context.Context, k8sClient client.Client, workName, namespace string, feedback map[string]int64) {
+	values := make([]workv1.FeedbackValue, 0, len(feedback))
+	for _, name := range slices.Sorted(maps.Keys(feedback)) {
+		value := feedback[name]
+		values = append(values, workv1.FeedbackValue{
+			Name: name,
+			Value: workv1.FieldValue{
+				Type:    workv1.Integer,
+				Integer: &value,
+			},
+		})
+	}
+	setManifestWorkFeedbackValues(ctx, k8sClient, workName, namespace, values)
+}
+
+func setManifestWorkFeedbackValues(ctx context.Context, k8sClient client.Client, workName, namespace string, values []workv1.FeedbackValue) {
 	work := &workv1.ManifestWork{}
 	Expect(k8sClient.Get(ctx, key.Of(workName, namespace), work)).To(Succeed())
 	work.Status.ResourceStatus = workv1.ManifestResourceStatus{
@@ -54,15 +112,198 @@ func SetManifestWorkFeedback(ctx context.Context, k8sClient client.Client, workN
 				LastTransitionTime: metav1.Now(),
 			}},
 			StatusFeedbacks: workv1.StatusFeedbackResult{
-				Values: []workv1.FeedbackValue{{
-					Name: feedbackName,
-					Value: workv1.FieldValue{
-						Type:   workv1.String,
-						String: &feedbackValue,
-					},
-				}},
+				Values: values,
 			},
 		}},
 	}
 	Expect(k8sClient.Status().Update(ctx, work)).To(Succeed())
 }
+
+// SetManifestWorkConditions updates a ManifestWork's status conditions for its current generation,
+// simulating what the OCM work agent does on a real spoke cluster.
+func SetManifestWorkConditions(ctx context.Context, k8sClient client.Client, workName, namespace string, conditions ...metav1.Condition) {
+	work := &workv1.ManifestWork{}
+	Expect(k8sClient.Get(ctx, key.Of(workName, namespace), work)).To(Succeed())
+	for _, condition := range conditions {
+		condition.ObservedGeneration = work.Generation
+		meta.SetStatusCondition(&work.Status.Conditions, condition)
+	}
+	Expect(k8sClient.Status().Update(ctx, work)).To(Succeed())
+}
+
+// WorkAppliedConditions returns the conditions the work agent reports for a successfully applied ManifestWork.
+func WorkAppliedConditions() []metav1.Condition {
+	return []metav1.Condition{
+		{Type: workv1.WorkApplied, Status: metav1.ConditionTrue, Reason: "AppliedManifestWorkComplete", Message: "Apply manifest work complete"},
+		{Type: workv1.WorkAvailable, Status: metav1.ConditionTrue, Reason: "ResourcesAvailable", Message: "All resources are available"},
+	}
+}
+
+// CreateManifestWorkReplicaSetWork creates the ManifestWork of a ManifestWorkReplicaSet on a cluster with the given conditions,
+// simulating what the OCM ManifestWorkReplicaSet controller and work agent do.
+func CreateManifestWorkReplicaSetWork(ctx context.Context, k8sClient client.Client, mwrsetName, mwrsetNamespace, clusterName string, conditions ...metav1.Condition) {
+	Expect(k8sClient.Create(ctx, &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mwrsetName,
+			Namespace: clusterName,
+			Labels: map[string]string{
+				workv1alpha1.ManifestWorkReplicaSetControllerNameLabelKey: mwrsetNamespace + "." + mwrsetName,
+			},
+		},
+	})).To(Succeed())
+	SetManifestWorkConditions(ctx, k8sClient, mwrsetName, clusterName, conditions...)
+}
+
+// SetManifestWorkReplicaSetSummary updates a ManifestWorkReplicaSet's placement summary,
+// simulating what the OCM ManifestWorkReplicaSet controller does. A desired total of 0 reports an empty placement decision.
+func SetManifestWorkReplicaSetSummary(ctx context.Context, k8sClient client.Client, name, namespace string, desired, available int) {
+	Eventually(func(g Gomega) {
+		mwrset := &workv1alpha1.ManifestWorkReplicaSet{}
+		g.Expect(k8sClient.Get(ctx, key.Of(name, namespace), mwrset)).To(Succeed())
+		summary := workv1alpha1.ManifestWorkReplicaSetSummary{
+			DesiredTotal: desired,
+			Total:        desired,
+			Applied:      available,
+			Available:    available,
+		}
+		placementVerified := metav1.Condition{
+			Type:               workv1alpha1.ManifestWorkReplicaSetConditionPlacementVerified,
+			Status:             metav1.ConditionTrue,
+			Reason:             workv1alpha1.ReasonAsExpected,
+			LastTransitionTime: metav1.Now(),
+		}
+		if desired == 0 {
+			placementVerified.Status = metav1.ConditionFalse
+			placementVerified.Reason = workv1alpha1.ReasonPlacementDecisionEmpty
+		}
+		mwrset.Status = workv1alpha1.ManifestWorkReplicaSetStatus{
+			Conditions:        []metav1.Condition{placementVerified},
+			Summary:           summary,
+			PlacementsSummary: []workv1alpha1.PlacementSummary{{Name: name, AvailableDecisionGroups: "1 (1 / 1 clusters applied)", Summary: summary}},
+		}
+		g.Expect(k8sClient.Status().Update(ctx, mwrset)).To(Succeed())
+	}).Should(Succeed())
+}
+
+// AnnotationManualOperatorProbe marks a ManagedCluster whose operator probe is reported by the test itself
+// with ReportOperatorProbe, instead of reporting that no operator Subscription exists.
+const AnnotationManualOperatorProbe = "test.mesh.open-cluster-management.io/manual-operator-probe"
+
+// OperatorProbeReported returns true if the status of an operator probe ManifestWork reports every Subscription it probes.
+func OperatorProbeReported(work *workv1.ManifestWork) bool {
+	return readOnlyResourcesReported(work)
+}
+
+// ReportOperatorProbe updates the status of the operator probe ManifestWork of a cluster to report the Subscriptions
+// found on it, keyed by namespace with their feedback values, simulating what the OCM work agent does for read-only
+// manifests. Every other probed Subscription is reported missing. It returns an error rather than failing the test,
+// so that it can also be called outside of a spec.
+func ReportOperatorProbe(ctx context.Context, k8sClient client.Client, clusterName string, subscriptions map[string]map[string]string) error {
+	return reportReadOnlyResources(ctx, k8sClient, key.Of(meshcontroller.ManifestWorkNameOperatorProbe, clusterName),
+		func(resource workv1.ManifestResourceMeta) (map[string]string, bool) {
+			feedback, found := subscriptions[resource.Namespace]
+			return feedback, found
+		})
+}
+
+// AnnotationManualDeletionGuard marks a ManagedCluster whose deletion guards are reported by the test itself
+// with ReportDeletionGuard, instead of reporting that no Istio control plane runs on it.
+const AnnotationManualDeletionGuard = "test.mesh.open-cluster-management.io/manual-deletion-guard"
+
+// DeletionGuardReported returns true if the status of a deletion guard ManifestWork reports every resource it checks.
+func DeletionGuardReported(work *workv1.ManifestWork) bool {
+	return readOnlyResourcesReported(work)
+}
+
+// ReportDeletionGuard updates the status of the deletion guard ManifestWork of a control plane namespace on a cluster
+// to report the Istio control plane resources found on it, keyed by kind with their feedback values. Every other
+// checked resource is reported missing. It returns an error rather than failing the test, so that it can also be
+// called outside of a spec.
+func ReportDeletionGuard(ctx context.Context, k8sClient client.Client, clusterName, cpNamespace string, resources map[string]map[string]string) error {
+	return reportReadOnlyResources(ctx, k8sClient, key.Of(meshcontroller.ManifestWorkNameDeletionGuardPrefix+cpNamespace, clusterName),
+		func(resource workv1.ManifestResourceMeta) (map[string]string, bool) {
+			feedback, found := resources[resource.Kind]
+			return feedback, found
+		})
+}
+
+// ReportIstiodProbe updates the status of the istiod probe ManifestWork of a control plane namespace on a cluster to
+// report the istiod Deployment with the given feedback values, or as missing if feedback is nil.
+func ReportIstiodProbe(ctx context.Context, k8sClient client.Client, clusterName, cpNamespace string, feedback map[string]string) error {
+	return reportReadOnlyResources(ctx, k8sClient, key.Of(meshcontroller.ManifestWorkNameIstiodProbePrefix+cpNamespace, clusterName),
+		func(workv1.ManifestResourceMeta) (map[string]string, bool) {
+			return feedback, feedback != nil
+		})
+}
+
+// readOnlyResourcesReported returns true if the status of a ManifestWork reports every read-only resource of it.
+func readOnlyResourcesReported(work *workv1.ManifestWork) bool {
+	for _, resource := range readOnlyResources(work) {
+		if !slices.ContainsFunc(work.Status.ResourceStatus.Manifests, func(m workv1.ManifestCondition) bool {
+			return m.ResourceMeta == resource
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// reportReadOnlyResources updates the status of a ManifestWork to report whether each of its read-only resources
+// exists, with its feedback values, simulating what the OCM work agent does for read-only manifests.
+func reportReadOnlyResources(ctx context.Context, k8sClient client.Client, name types.NamespacedName,
+	found func(workv1.ManifestResourceMeta) (map[string]string, bool)) error {
+	work := &workv1.ManifestWork{}
+	if err := k8sClient.Get(ctx, name, work); err != nil {
+		return err
+	}
+	work.Status.ResourceStatus = workv1.ManifestResourceStatus{}
+	for _, resource := range readOnlyResources(work) {
+		condition := workv1.ManifestCondition{ResourceMeta: resource}
+		feedback, exists := found(resource)
+		available := metav1.Condition{
+			Type: workv1.ManifestAvailable, Status: metav1.ConditionFalse, Reason: "ResourceNotAvailable", LastTransitionTime: metav1.Now(),
+		}
+		if exists {
+			available.Status, available.Reason = metav1.ConditionTrue, "ResourceAvailable"
+			for _, name := range slices.Sorted(maps.Keys(feedback)) {
+				value := feedback[name]
+				condition.StatusFeedbacks.Values = append(condition.StatusFeedbacks.Values, workv1.FeedbackValue{
+					Name:  name,
+					Value: workv1.FieldValue{Type: workv1.String, String: &value},
+				})
+			}
+		}
+		condition.Conditions = []metav1.Condition{available}
+		work.Status.ResourceStatus.Manifests = append(work.Status.ResourceStatus.Manifests, condition)
+	}
+	return k8sClient.Status().Update(ctx, work)
+}
+
+// readOnlyResources returns the resource metadata of the manifests of a ManifestWork with the ReadOnly update strategy.
+func readOnlyResources(work *workv1.ManifestWork) []workv1.ManifestResourceMeta {
+	var resources []workv1.ManifestResourceMeta
+	for i, manifest := range work.Spec.Workload.Manifests {
+		obj := &metav1.PartialObjectMetadata{}
+		if err := json.Unmarshal(manifest.Raw, obj); err != nil {
+			continue
+		}
+		index := slices.IndexFunc(work.Spec.ManifestConfigs, func(config workv1.ManifestConfigOption) bool {
+			return config.ResourceIdentifier.Name == obj.Name && config.ResourceIdentifier.Namespace == obj.Namespace &&
+				config.UpdateStrategy != nil && config.UpdateStrategy.Type == workv1.UpdateStrategyTypeReadOnly
+		})
+		if index < 0 {
+			continue
+		}
+		gv, _ := schema.ParseGroupVersion(obj.APIVersion)
+		resources = append(resources, workv1.ManifestResourceMeta{
+			Ordinal:   int32(i),
+			Group:     gv.Group,
+			Version:   gv.Version,
+			Kind:      obj.Kind,
+			Resource:  work.Spec.ManifestConfigs[index].ResourceIdentifier.Resource,
+			Name:      obj.Name,
+			Namespace: obj.Namespace,
+		})
+	}
+	return resources
+}